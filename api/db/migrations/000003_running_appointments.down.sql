@@ -0,0 +1 @@
+DROP INDEX IF EXISTS ux_appointments_running_per_user;
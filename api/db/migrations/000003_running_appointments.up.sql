@@ -0,0 +1,19 @@
+-- Fecha cronômetros duplicados que já existam: cada apontamento em andamento
+-- termina no início do próximo apontamento do mesmo usuário.
+UPDATE appointments a
+SET end_time = (
+    SELECT MIN(n.start_time)
+    FROM appointments n
+    WHERE n.user_id = a.user_id AND n.start_time > a.start_time
+)
+WHERE a.end_time IS NULL
+  AND EXISTS (
+    SELECT 1
+    FROM appointments n
+    WHERE n.user_id = a.user_id AND n.end_time IS NULL AND n.start_time > a.start_time
+  );
+
+-- No máximo um apontamento em andamento (end_time NULL) por usuário
+CREATE UNIQUE INDEX IF NOT EXISTS ux_appointments_running_per_user
+    ON appointments (user_id)
+    WHERE end_time IS NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE appointments DROP CONSTRAINT IF EXISTS excl_appointments_user_overlap;
+ALTER TABLE appointments DROP COLUMN IF EXISTS allow_overlap;
@@ -0,0 +1,27 @@
+-- Necessário para usar "user_id WITH =" dentro de um índice GiST
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+-- Apontamentos liberados por um admin para trabalho paralelo legítimo
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS allow_overlap BOOLEAN NOT NULL DEFAULT false;
+
+-- Histórico já sobreposto é preservado, mas fica marcado para revisão
+UPDATE appointments a
+SET allow_overlap = true
+WHERE EXISTS (
+    SELECT 1
+    FROM appointments b
+    WHERE b.user_id = a.user_id
+      AND b.id <> a.id
+      AND tsrange(a.start_time, COALESCE(a.end_time, 'infinity'), '[)')
+       && tsrange(b.start_time, COALESCE(b.end_time, 'infinity'), '[)')
+);
+
+-- Um consultor não pode ter dois apontamentos no mesmo intervalo.
+-- Apontamento em andamento (end_time NULL) ocupa do início até "infinito".
+-- Intervalos são [início, fim): terminar às 12:00 e começar às 12:00 não é sobreposição.
+ALTER TABLE appointments
+    ADD CONSTRAINT excl_appointments_user_overlap
+    EXCLUDE USING gist (
+        user_id WITH =,
+        tsrange(start_time, COALESCE(end_time, 'infinity'), '[)') WITH &&
+    ) WHERE (NOT allow_overlap);
@@ -0,0 +1,2 @@
+ALTER TABLE appointments DROP COLUMN IF EXISTS is_overrun;
+ALTER TABLE contracts DROP COLUMN IF EXISTS overrun_policy;
@@ -0,0 +1,8 @@
+-- O que fazer ao lançar horas em contrato com saldo esgotado:
+-- reject = recusa, warn = aceita com aviso na resposta, flag = aceita e marca o apontamento
+ALTER TABLE contracts
+    ADD COLUMN IF NOT EXISTS overrun_policy VARCHAR(10) NOT NULL DEFAULT 'warn'
+    CHECK (overrun_policy IN ('reject', 'warn', 'flag'));
+
+-- Apontamento lançado além do saldo do contrato (política "flag")
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS is_overrun BOOLEAN NOT NULL DEFAULT false;
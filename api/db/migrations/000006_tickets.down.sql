@@ -0,0 +1,2 @@
+ALTER TABLE appointments DROP COLUMN IF EXISTS ticket_id;
+DROP TABLE IF EXISTS tickets;
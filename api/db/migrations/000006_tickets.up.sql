@@ -0,0 +1,26 @@
+-- Chamados de service desk
+CREATE TABLE IF NOT EXISTS tickets (
+    id BIGSERIAL PRIMARY KEY,
+    company_id BIGINT NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    contract_id BIGINT NULL REFERENCES contracts(id) ON DELETE SET NULL,
+    requester_name VARCHAR(255) NOT NULL DEFAULT '',
+    requester_email VARCHAR(255) NOT NULL DEFAULT '',
+    assignee_id BIGINT NULL REFERENCES users(id) ON DELETE SET NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'open'
+        CHECK (status IN ('open', 'in_progress', 'waiting_client', 'resolved', 'closed')),
+    priority VARCHAR(10) NOT NULL DEFAULT 'medium'
+        CHECK (priority IN ('low', 'medium', 'high', 'urgent')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP NULL,
+    closed_at TIMESTAMP NULL
+);
+
+CREATE INDEX IF NOT EXISTS ix_tickets_company ON tickets (company_id);
+CREATE INDEX IF NOT EXISTS ix_tickets_assignee_status ON tickets (assignee_id, status);
+
+-- Horas gastas no chamado
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS ticket_id BIGINT NULL REFERENCES tickets(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS ix_appointments_ticket ON appointments (ticket_id) WHERE ticket_id IS NOT NULL;
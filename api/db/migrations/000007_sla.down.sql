@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS ix_tickets_contract_created;
+DROP INDEX IF EXISTS ix_tickets_sla_open;
+ALTER TABLE tickets
+    DROP COLUMN IF EXISTS sla_status,
+    DROP COLUMN IF EXISTS resolution_risk_at,
+    DROP COLUMN IF EXISTS response_risk_at,
+    DROP COLUMN IF EXISTS resolution_due_at,
+    DROP COLUMN IF EXISTS response_due_at,
+    DROP COLUMN IF EXISTS first_response_at;
+ALTER TABLE contracts DROP COLUMN IF EXISTS sla_policy_id;
+DROP TABLE IF EXISTS sla_policies;
+DROP TABLE IF EXISTS business_calendars;
@@ -0,0 +1,35 @@
+-- Calendários de horário comercial: grade semanal e feriados em JSONB
+CREATE TABLE IF NOT EXISTS business_calendars (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'America/Sao_Paulo',
+    hours JSONB NOT NULL DEFAULT '[]',     -- [{"weekday":1,"start":"09:00","end":"18:00"}, ...]
+    holidays JSONB NOT NULL DEFAULT '[]'   -- ["2026-12-25", ...]
+);
+
+-- Políticas de SLA: prazos por prioridade em minutos de horário comercial
+CREATE TABLE IF NOT EXISTS sla_policies (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    calendar_id BIGINT NULL REFERENCES business_calendars(id) ON DELETE SET NULL, -- NULL = 24x7
+    targets JSONB NOT NULL DEFAULT '{}',  -- {"high":{"responseMinutes":60,"resolutionMinutes":480}, ...}
+    at_risk_percent INT NOT NULL DEFAULT 80 CHECK (at_risk_percent BETWEEN 1 AND 100)
+);
+
+ALTER TABLE contracts ADD COLUMN IF NOT EXISTS sla_policy_id BIGINT NULL REFERENCES sla_policies(id) ON DELETE SET NULL;
+
+-- Prazos do chamado e situação calculada pelo worker
+ALTER TABLE tickets
+    ADD COLUMN IF NOT EXISTS first_response_at TIMESTAMP NULL,
+    ADD COLUMN IF NOT EXISTS response_due_at TIMESTAMP NULL,
+    ADD COLUMN IF NOT EXISTS resolution_due_at TIMESTAMP NULL,
+    ADD COLUMN IF NOT EXISTS response_risk_at TIMESTAMP NULL,
+    ADD COLUMN IF NOT EXISTS resolution_risk_at TIMESTAMP NULL,
+    ADD COLUMN IF NOT EXISTS sla_status VARCHAR(10) NOT NULL DEFAULT ''
+        CHECK (sla_status IN ('', 'on_track', 'at_risk', 'breached'));
+
+-- Chamados já atendidos antes do SLA existir
+UPDATE tickets SET first_response_at = updated_at WHERE status <> 'open' AND first_response_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS ix_tickets_sla_open ON tickets (sla_status) WHERE sla_status IN ('on_track', 'at_risk');
+CREATE INDEX IF NOT EXISTS ix_tickets_contract_created ON tickets (contract_id, created_at);
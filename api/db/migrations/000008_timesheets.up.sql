@@ -0,0 +1,15 @@
+-- Folhas de horas semanais (semana começa na segunda-feira)
+CREATE TABLE IF NOT EXISTS timesheets (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    week_start DATE NOT NULL CHECK (EXTRACT(ISODOW FROM week_start) = 1),
+    status VARCHAR(20) NOT NULL DEFAULT 'submitted'
+        CHECK (status IN ('submitted', 'approved', 'rejected')),
+    comment TEXT NOT NULL DEFAULT '',
+    submitted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    reviewed_at TIMESTAMP NULL,
+    reviewed_by BIGINT NULL REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT ux_timesheets_user_week UNIQUE (user_id, week_start)
+);
+
+CREATE INDEX IF NOT EXISTS ix_timesheets_status ON timesheets (status);
@@ -0,0 +1,19 @@
+-- Valor-hora por contrato, com exceções por consultor e vigência.
+-- user_id NULL é o valor padrão do contrato; um valor do consultor tem precedência sobre ele.
+-- Reajuste é um novo registro a partir de uma data: o histórico continua com o valor antigo.
+CREATE TABLE IF NOT EXISTS billing_rates (
+    id BIGSERIAL PRIMARY KEY,
+    contract_id BIGINT NOT NULL REFERENCES contracts(id) ON DELETE CASCADE,
+    user_id BIGINT NULL REFERENCES users(id) ON DELETE CASCADE,
+    hourly_rate NUMERIC(12, 2) NOT NULL CHECK (hourly_rate >= 0),
+    valid_from DATE NOT NULL,
+    valid_to DATE NULL, -- Inclusivo; NULL = sem fim
+    CHECK (valid_to IS NULL OR valid_to >= valid_from),
+
+    -- Para o mesmo contrato e consultor (ou padrão), as vigências não podem se sobrepor
+    CONSTRAINT excl_billing_rates_overlap EXCLUDE USING gist (
+        contract_id WITH =,
+        COALESCE(user_id, 0) WITH =,
+        daterange(valid_from, valid_to, '[]') WITH &&
+    )
+);
@@ -0,0 +1,4 @@
+ALTER TABLE appointments DROP COLUMN IF EXISTS invoice_line_id;
+DROP TABLE IF EXISTS invoice_lines;
+DROP TABLE IF EXISTS invoice_sequences;
+DROP TABLE IF EXISTS invoices;
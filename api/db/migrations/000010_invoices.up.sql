@@ -0,0 +1,47 @@
+-- Faturas: rascunho montado a partir das horas aprovadas, com numeração anual na emissão
+CREATE TABLE IF NOT EXISTS invoices (
+    id BIGSERIAL PRIMARY KEY,
+    company_id BIGINT NOT NULL REFERENCES companies(id) ON DELETE RESTRICT,
+    status VARCHAR(10) NOT NULL DEFAULT 'draft'
+        CHECK (status IN ('draft', 'issued', 'paid', 'void')),
+    year INT NULL,   -- Ano da emissão
+    number INT NULL, -- Sequencial dentro do ano, atribuído na emissão
+    period_start DATE NOT NULL,
+    period_end DATE NOT NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    total NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    issued_at TIMESTAMP NULL,
+    paid_at TIMESTAMP NULL,
+    voided_at TIMESTAMP NULL,
+    CHECK (period_end >= period_start),
+    CHECK ((year IS NULL) = (number IS NULL)),
+    CONSTRAINT ux_invoices_year_number UNIQUE (year, number)
+);
+
+CREATE INDEX IF NOT EXISTS ix_invoices_company ON invoices (company_id);
+
+-- Último número emitido por ano (a linha é travada na emissão, então não há buracos nem repetição)
+CREATE TABLE IF NOT EXISTS invoice_sequences (
+    year INT PRIMARY KEY,
+    last_number INT NOT NULL
+);
+
+-- Itens da fatura: horas (contrato × consultor × valor-hora) ou lançamentos manuais
+CREATE TABLE IF NOT EXISTS invoice_lines (
+    id BIGSERIAL PRIMARY KEY,
+    invoice_id BIGINT NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('hours', 'manual')),
+    description TEXT NOT NULL,
+    contract_id BIGINT NULL REFERENCES contracts(id) ON DELETE SET NULL,
+    user_id BIGINT NULL REFERENCES users(id) ON DELETE SET NULL,
+    quantity NUMERIC(12, 2) NOT NULL,
+    unit_price NUMERIC(12, 2) NOT NULL,
+    amount NUMERIC(14, 2) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS ix_invoice_lines_invoice ON invoice_lines (invoice_id);
+
+-- Apontamento faturado: aponta para o item que o cobrou (NULL = ainda não faturado)
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS invoice_line_id BIGINT NULL REFERENCES invoice_lines(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS ix_appointments_invoice_line ON appointments (invoice_line_id) WHERE invoice_line_id IS NOT NULL;
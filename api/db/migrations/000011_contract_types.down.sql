@@ -0,0 +1,4 @@
+-- Os textos livres antigos não são recuperados: ficam os códigos dos tipos
+ALTER TABLE contracts DROP COLUMN IF EXISTS rollover_cap_hours;
+ALTER TABLE contracts DROP CONSTRAINT IF EXISTS chk_contracts_type;
+ALTER TABLE contracts ALTER COLUMN contract_type TYPE VARCHAR(100);
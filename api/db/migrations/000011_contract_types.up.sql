@@ -0,0 +1,18 @@
+-- Tipo de contrato deixa de ser texto livre:
+-- hour_bank   = banco de horas mensal: total_hours por período, períodos contados a partir de start_date
+-- fixed_price = projeto fechado: total_hours é o orçamento de horas da vigência inteira
+-- on_demand   = sob demanda: sem limite de horas, faturado pelo uso
+UPDATE contracts SET contract_type = CASE
+    WHEN total_hours <= 0 THEN 'on_demand'
+    WHEN lower(contract_type) LIKE '%mensal%' OR lower(contract_type) LIKE '%banco%' THEN 'hour_bank'
+    ELSE 'fixed_price'
+END;
+
+ALTER TABLE contracts
+    ALTER COLUMN contract_type TYPE VARCHAR(20),
+    ADD CONSTRAINT chk_contracts_type CHECK (contract_type IN ('hour_bank', 'fixed_price', 'on_demand'));
+
+-- Banco de horas: quanto da sobra de um período passa para o seguinte (0 = não acumula)
+ALTER TABLE contracts
+    ADD COLUMN IF NOT EXISTS rollover_cap_hours INT NOT NULL DEFAULT 0
+    CHECK (rollover_cap_hours >= 0);
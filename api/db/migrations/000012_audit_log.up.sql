@@ -0,0 +1,15 @@
+-- Trilha de auditoria: quem criou, alterou ou removeu cada registro, quando e o que mudou.
+-- changes guarda só os campos alterados: {"campo": {"old": ..., "new": ...}}
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    actor_id BIGINT NULL REFERENCES users(id) ON DELETE SET NULL,
+    occurred_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    entity VARCHAR(50) NOT NULL, -- Nome da tabela (ex.: appointments)
+    entity_id BIGINT NOT NULL,
+    operation VARCHAR(10) NOT NULL CHECK (operation IN ('create', 'update', 'delete')),
+    changes JSONB NOT NULL DEFAULT '{}'
+);
+
+-- Histórico de um registro e ações de um usuário, em ordem
+CREATE INDEX IF NOT EXISTS ix_audit_log_entity ON audit_log (entity, entity_id, occurred_at);
+CREATE INDEX IF NOT EXISTS ix_audit_log_actor ON audit_log (actor_id, occurred_at);
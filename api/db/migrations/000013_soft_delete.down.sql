@@ -0,0 +1,58 @@
+ALTER TABLE audit_log DROP CONSTRAINT IF EXISTS audit_log_operation_check;
+ALTER TABLE audit_log ADD CONSTRAINT audit_log_operation_check
+    CHECK (operation IN ('create', 'update', 'delete'));
+
+ALTER TABLE timesheets DROP CONSTRAINT IF EXISTS timesheets_user_id_fkey,
+    ADD CONSTRAINT timesheets_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE billing_rates DROP CONSTRAINT IF EXISTS billing_rates_user_id_fkey,
+    ADD CONSTRAINT billing_rates_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE billing_rates DROP CONSTRAINT IF EXISTS billing_rates_contract_id_fkey,
+    ADD CONSTRAINT billing_rates_contract_id_fkey FOREIGN KEY (contract_id) REFERENCES contracts(id) ON DELETE CASCADE;
+ALTER TABLE tickets DROP CONSTRAINT IF EXISTS tickets_company_id_fkey,
+    ADD CONSTRAINT tickets_company_id_fkey FOREIGN KEY (company_id) REFERENCES companies(id) ON DELETE CASCADE;
+ALTER TABLE appointments DROP CONSTRAINT IF EXISTS appointments_user_id_fkey,
+    ADD CONSTRAINT appointments_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE appointments DROP CONSTRAINT IF EXISTS appointments_contract_id_fkey,
+    ADD CONSTRAINT appointments_contract_id_fkey FOREIGN KEY (contract_id) REFERENCES contracts(id) ON DELETE CASCADE;
+ALTER TABLE contracts DROP CONSTRAINT IF EXISTS contracts_company_id_fkey,
+    ADD CONSTRAINT contracts_company_id_fkey FOREIGN KEY (company_id) REFERENCES companies(id) ON DELETE CASCADE;
+
+-- Registros removidos logicamente saem de vez antes de as restrições voltarem
+DELETE FROM billing_rates WHERE deleted_at IS NOT NULL;
+ALTER TABLE billing_rates DROP CONSTRAINT IF EXISTS excl_billing_rates_overlap;
+ALTER TABLE billing_rates
+    ADD CONSTRAINT excl_billing_rates_overlap EXCLUDE USING gist (
+        contract_id WITH =,
+        COALESCE(user_id, 0) WITH =,
+        daterange(valid_from, valid_to, '[]') WITH &&
+    );
+
+DELETE FROM appointments WHERE deleted_at IS NOT NULL;
+ALTER TABLE appointments DROP CONSTRAINT IF EXISTS excl_appointments_user_overlap;
+ALTER TABLE appointments
+    ADD CONSTRAINT excl_appointments_user_overlap
+    EXCLUDE USING gist (
+        user_id WITH =,
+        tsrange(start_time, COALESCE(end_time, 'infinity'), '[)') WITH &&
+    ) WHERE (NOT allow_overlap);
+
+DROP INDEX IF EXISTS ux_appointments_running_per_user;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_appointments_running_per_user
+    ON appointments (user_id)
+    WHERE end_time IS NULL;
+
+-- Volta a unicidade total: se um cadastro removido repetir o CNPJ/e-mail de um ativo,
+-- a volta para aqui; apague (purge) o removido antes
+DROP INDEX IF EXISTS ux_users_email;
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+DROP INDEX IF EXISTS ux_companies_cnpj;
+ALTER TABLE companies ADD CONSTRAINT companies_cnpj_key UNIQUE (cnpj);
+
+ALTER TABLE billing_rates DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE business_calendars DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE sla_policies DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE tickets DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE appointments DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE contracts DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE companies DROP COLUMN IF EXISTS deleted_at;
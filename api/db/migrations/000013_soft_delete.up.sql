@@ -0,0 +1,65 @@
+-- Remoção lógica: DELETE pela API só marca deleted_at; o registro some das consultas,
+-- pode ser restaurado e só sai do banco pelo purge (admin).
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL;
+ALTER TABLE contracts ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL;
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL;
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL;
+ALTER TABLE sla_policies ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL;
+ALTER TABLE business_calendars ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL;
+ALTER TABLE billing_rates ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL;
+
+-- CNPJ e e-mail só precisam ser únicos entre os cadastros ativos: removida a empresa ou o
+-- usuário, o mesmo CNPJ/e-mail pode ser cadastrado de novo sem purge
+ALTER TABLE companies DROP CONSTRAINT IF EXISTS companies_cnpj_key;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_companies_cnpj
+    ON companies (cnpj)
+    WHERE deleted_at IS NULL;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_users_email
+    ON users (email)
+    WHERE deleted_at IS NULL;
+
+-- Registros removidos não seguram mais o cronômetro, a agenda do consultor nem a vigência do valor-hora
+DROP INDEX IF EXISTS ux_appointments_running_per_user;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_appointments_running_per_user
+    ON appointments (user_id)
+    WHERE end_time IS NULL AND deleted_at IS NULL;
+
+ALTER TABLE appointments DROP CONSTRAINT IF EXISTS excl_appointments_user_overlap;
+ALTER TABLE appointments
+    ADD CONSTRAINT excl_appointments_user_overlap
+    EXCLUDE USING gist (
+        user_id WITH =,
+        tsrange(start_time, COALESCE(end_time, 'infinity'), '[)') WITH &&
+    ) WHERE (NOT allow_overlap AND deleted_at IS NULL);
+
+ALTER TABLE billing_rates DROP CONSTRAINT IF EXISTS excl_billing_rates_overlap;
+ALTER TABLE billing_rates
+    ADD CONSTRAINT excl_billing_rates_overlap EXCLUDE USING gist (
+        contract_id WITH =,
+        COALESCE(user_id, 0) WITH =,
+        daterange(valid_from, valid_to, '[]') WITH &&
+    ) WHERE (deleted_at IS NULL);
+
+-- O purge não apaga histórico em cascata: com registros dependentes ele é recusado
+ALTER TABLE contracts DROP CONSTRAINT IF EXISTS contracts_company_id_fkey,
+    ADD CONSTRAINT contracts_company_id_fkey FOREIGN KEY (company_id) REFERENCES companies(id) ON DELETE RESTRICT;
+ALTER TABLE appointments DROP CONSTRAINT IF EXISTS appointments_contract_id_fkey,
+    ADD CONSTRAINT appointments_contract_id_fkey FOREIGN KEY (contract_id) REFERENCES contracts(id) ON DELETE RESTRICT;
+ALTER TABLE appointments DROP CONSTRAINT IF EXISTS appointments_user_id_fkey,
+    ADD CONSTRAINT appointments_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE RESTRICT;
+ALTER TABLE tickets DROP CONSTRAINT IF EXISTS tickets_company_id_fkey,
+    ADD CONSTRAINT tickets_company_id_fkey FOREIGN KEY (company_id) REFERENCES companies(id) ON DELETE RESTRICT;
+ALTER TABLE billing_rates DROP CONSTRAINT IF EXISTS billing_rates_contract_id_fkey,
+    ADD CONSTRAINT billing_rates_contract_id_fkey FOREIGN KEY (contract_id) REFERENCES contracts(id) ON DELETE RESTRICT;
+ALTER TABLE billing_rates DROP CONSTRAINT IF EXISTS billing_rates_user_id_fkey,
+    ADD CONSTRAINT billing_rates_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE RESTRICT;
+ALTER TABLE timesheets DROP CONSTRAINT IF EXISTS timesheets_user_id_fkey,
+    ADD CONSTRAINT timesheets_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE RESTRICT;
+
+-- Auditoria também registra restauração e purge
+ALTER TABLE audit_log DROP CONSTRAINT IF EXISTS audit_log_operation_check;
+ALTER TABLE audit_log ADD CONSTRAINT audit_log_operation_check
+    CHECK (operation IN ('create', 'update', 'delete', 'restore', 'purge'));
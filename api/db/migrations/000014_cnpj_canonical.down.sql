@@ -0,0 +1,2 @@
+-- A máscara dos CNPJs não volta: a forma canônica cabe na coluna e continua válida.
+ALTER TABLE companies DROP CONSTRAINT IF EXISTS chk_companies_cnpj_format;
@@ -0,0 +1,12 @@
+-- CNPJ na forma canônica (sem máscara, letras em maiúsculas; ver internal/document):
+-- "12.345.678/0001-90" e "12345678000190" deixam de contornar a ux_companies_cnpj.
+-- Se duas empresas ativas colidirem ao normalizar, a migração para no índice: junte os cadastros antes.
+UPDATE companies
+   SET cnpj = upper(regexp_replace(cnpj, '[./ -]', '', 'g'))
+ WHERE cnpj <> upper(regexp_replace(cnpj, '[./ -]', '', 'g'));
+
+-- Só o formato (inclui o CNPJ alfanumérico); os dígitos verificadores são conferidos na API.
+-- NOT VALID: cadastros antigos fora do formato continuam lá até a próxima edição.
+ALTER TABLE companies DROP CONSTRAINT IF EXISTS chk_companies_cnpj_format;
+ALTER TABLE companies
+    ADD CONSTRAINT chk_companies_cnpj_format CHECK (cnpj ~ '^[0-9A-Z]{12}[0-9]{2}$') NOT VALID;
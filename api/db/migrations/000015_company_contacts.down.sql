@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS company_addresses;
+DROP TABLE IF EXISTS company_contacts;
@@ -0,0 +1,56 @@
+-- Contatos da empresa: cada envio vai para o tipo certo de contato.
+-- is_billing (financeiro) recebe as faturas, is_technical (técnico) abre chamados e recebe
+-- as notificações deles, is_approver (gestor) aprova os extratos mensais. Um contato pode ter
+-- vários papéis; o principal (is_primary) é o primeiro da lista e o substituto quando falta um tipo.
+-- Como o resto do histórico da empresa, contatos e endereços seguram o purge (RESTRICT).
+CREATE TABLE IF NOT EXISTS company_contacts (
+    id BIGSERIAL PRIMARY KEY,
+    company_id BIGINT NOT NULL REFERENCES companies(id) ON DELETE RESTRICT,
+    name VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    phone VARCHAR(32) NOT NULL DEFAULT '',
+    is_billing BOOLEAN NOT NULL DEFAULT FALSE,
+    is_technical BOOLEAN NOT NULL DEFAULT FALSE,
+    is_approver BOOLEAN NOT NULL DEFAULT FALSE,
+    is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_company_contacts_company ON company_contacts (company_id);
+-- O mesmo e-mail não se repete na empresa, e só um contato é o principal
+CREATE UNIQUE INDEX IF NOT EXISTS ux_company_contacts_email
+    ON company_contacts (company_id, lower(email))
+    WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_company_contacts_primary
+    ON company_contacts (company_id)
+    WHERE is_primary AND deleted_at IS NULL;
+
+-- Endereços de cobrança (o principal sai na fatura)
+CREATE TABLE IF NOT EXISTS company_addresses (
+    id BIGSERIAL PRIMARY KEY,
+    company_id BIGINT NOT NULL REFERENCES companies(id) ON DELETE RESTRICT,
+    street VARCHAR(255) NOT NULL,
+    number VARCHAR(20) NOT NULL DEFAULT '',
+    complement VARCHAR(255) NOT NULL DEFAULT '',
+    district VARCHAR(255) NOT NULL DEFAULT '',
+    city VARCHAR(255) NOT NULL,
+    state CHAR(2) NOT NULL,
+    zip_code VARCHAR(9) NOT NULL,
+    is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_company_addresses_company ON company_addresses (company_id);
+CREATE UNIQUE INDEX IF NOT EXISTS ux_company_addresses_primary
+    ON company_addresses (company_id)
+    WHERE is_primary AND deleted_at IS NULL;
+
+-- O e-mail único de hoje (contact_email) vira o contato principal, com todos os papéis.
+-- A coluna continua como e-mail geral da empresa, usado quando não há contato.
+INSERT INTO company_contacts (company_id, name, email, is_billing, is_technical, is_approver, is_primary)
+SELECT c.id, c.name, c.contact_email, TRUE, TRUE, TRUE, TRUE
+  FROM companies c
+ WHERE c.contact_email <> ''
+   AND NOT EXISTS (SELECT 1 FROM company_contacts cc WHERE cc.company_id = c.id);
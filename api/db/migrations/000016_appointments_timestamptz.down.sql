@@ -0,0 +1,12 @@
+ALTER TABLE appointments DROP CONSTRAINT IF EXISTS excl_appointments_user_overlap;
+
+ALTER TABLE appointments
+    ALTER COLUMN start_time TYPE TIMESTAMP USING start_time AT TIME ZONE 'UTC',
+    ALTER COLUMN end_time TYPE TIMESTAMP USING end_time AT TIME ZONE 'UTC';
+
+ALTER TABLE appointments
+    ADD CONSTRAINT excl_appointments_user_overlap
+    EXCLUDE USING gist (
+        user_id WITH =,
+        tsrange(start_time, COALESCE(end_time, 'infinity'), '[)') WITH &&
+    ) WHERE (NOT allow_overlap AND deleted_at IS NULL);
@@ -0,0 +1,16 @@
+-- Início e fim dos apontamentos passam a ser instantes (TIMESTAMPTZ): a sobreposição deixa de
+-- depender do fuso de quem gravou. Os valores antigos já eram UTC (o frontend envia em UTC e a
+-- API lia a coluna como UTC). Dia e semana do apontamento (folha de horas, faturas, relatórios)
+-- seguem em UTC: a API abre as conexões com TimeZone=UTC (ver database.ConnectDB).
+ALTER TABLE appointments DROP CONSTRAINT IF EXISTS excl_appointments_user_overlap;
+
+ALTER TABLE appointments
+    ALTER COLUMN start_time TYPE TIMESTAMPTZ USING start_time AT TIME ZONE 'UTC',
+    ALTER COLUMN end_time TYPE TIMESTAMPTZ USING end_time AT TIME ZONE 'UTC';
+
+ALTER TABLE appointments
+    ADD CONSTRAINT excl_appointments_user_overlap
+    EXCLUDE USING gist (
+        user_id WITH =,
+        tstzrange(start_time, COALESCE(end_time, 'infinity'), '[)') WITH &&
+    ) WHERE (NOT allow_overlap AND deleted_at IS NULL);
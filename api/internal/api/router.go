@@ -5,7 +5,10 @@ import (
 
 	_ "nexus/docs"
 
+	"nexus/internal/auth"
 	"nexus/internal/handlers"
+	"nexus/internal/models"
+	"nexus/internal/repository"
 
 	httpSwagger "github.com/swaggo/http-swagger"
 
@@ -14,12 +17,36 @@ import (
 	"github.com/go-chi/cors"
 )
 
-func NewRouter(
-	companyHandler *handlers.CompanyHandler,
-	userHandler *handlers.UserHandler,
-	contractHandler *handlers.ContractHandler,
-	appointmentHandler *handlers.AppointmentHandler, // Adicionado o novo handler
-) http.Handler {
+// Handlers são os handlers de cada grupo de rotas. Os campos são nomeados para uma troca
+// entre dois handlers não passar despercebida; entidade nova ganha um campo aqui.
+type Handlers struct {
+	Auth        *handlers.AuthHandler
+	Company     *handlers.CompanyHandler
+	Contact     *handlers.CompanyChildHandler[*models.CompanyContact]
+	Address     *handlers.CompanyChildHandler[*models.CompanyAddress]
+	User        *handlers.UserHandler
+	Contract    *handlers.ContractHandler
+	Appointment *handlers.AppointmentHandler
+	Report      *handlers.ReportHandler
+	Statement   *handlers.StatementHandler
+	Ticket      *handlers.TicketHandler
+	SLAPolicy   *handlers.SLAPolicyHandler
+	Calendar    *handlers.BusinessCalendarHandler
+	Timesheet   *handlers.TimesheetHandler
+	BillingRate *handlers.BillingRateHandler
+	Invoice     *handlers.InvoiceHandler
+	Audit       *handlers.AuditHandler
+}
+
+// Deps é o que o roteador usa além dos handlers: autenticação e prazo das consultas.
+type Deps struct {
+	Tokens   *auth.TokenManager
+	UserRepo repository.UserRepository
+	Timeouts Timeouts
+}
+
+// NewRouter monta as rotas da API.
+func NewRouter(deps Deps, h Handlers) http.Handler {
 
 	r := chi.NewRouter()
 
@@ -29,6 +56,8 @@ func NewRouter(
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins: []string{"*"},
 		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedHeaders: []string{"Accept", "Authorization", "Content-Type"},
+		ExposedHeaders: []string{"Link", "X-Total-Count", "X-Next-Cursor"}, // Paginação das listas
 	}))
 
 	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
@@ -39,47 +68,196 @@ func NewRouter(
 		httpSwagger.URL("http://localhost:8080/swagger/doc.json"), // Aponta para o JSON gerado
 	))
 
-	// --- 1. ROTAS DE EMPRESAS (COMPANIES) ---
-	r.Route("/api/companies", func(r chi.Router) {
-		r.Post("/", companyHandler.CreateHandler)       // Criar empresa
-		r.Get("/", companyHandler.GetAllHandler)        // Listar empresas
-		r.Get("/{id}", companyHandler.GetByIDHandler)   // Detalhe da empresa
-		r.Put("/{id}", companyHandler.UpdateHandler)    // Atualizar
-		r.Delete("/{id}", companyHandler.DeleteHandler) // Deletar
-
-		r.Get("/{companyID}/contracts", contractHandler.ListContractsByCompany)
+	// --- 0. AUTENTICAÇÃO (ÚNICAS ROTAS /api PÚBLICAS) ---
+	r.Route("/api/auth", func(r chi.Router) {
+		r.Post("/login", h.Auth.Login)
+		r.Post("/refresh", h.Auth.Refresh)
+		r.Post("/logout", h.Auth.Logout)
 	})
 
-	// --- 2. ROTAS DE USUÁRIOS (USERS) ---
-	r.Route("/api/users", func(r chi.Router) {
-		r.Post("/", userHandler.CreateHandler)
-		r.Get("/", userHandler.GetAllHandler)
-		r.Get("/{id}", userHandler.GetByIDHandler)
-		r.Put("/{id}", userHandler.UpdateHandler)
-		r.Delete("/{id}", userHandler.DeleteHandler)
+	// Daqui para baixo tudo exige "Authorization: Bearer <accessToken>"
+	r.Group(func(r chi.Router) {
+		r.Use(auth.Middleware(deps.Tokens, deps.UserRepo))
+		r.Use(h.Audit.Middleware)          // Registra quem criou, alterou ou removeu cada registro
+		r.Use(queryTimeout(deps.Timeouts)) // Prazo das consultas; cliente desconectado também cancela
 
-		// Rota Especial: Ver apontamentos deste usuário
-		r.Get("/{userID}/appointments", appointmentHandler.ListAppointmentsByUser)
-	})
+		adminOnly := auth.RequireRole(models.RoleAdmin)
 
-	// --- 3. ROTAS DE CONTRATOS (CONTRACTS) ---
-	r.Route("/api/contracts", func(r chi.Router) {
-		r.Post("/", contractHandler.CreateHandler)
-		r.Get("/", contractHandler.GetAllHandler) // Lista Turbinada (com JOIN)
-		r.Get("/{id}", contractHandler.GetByIDHandler)
-		r.Put("/{id}", contractHandler.UpdateHandler)
-		r.Delete("/{id}", contractHandler.DeleteHandler)
+		// --- 1. ROTAS DE EMPRESAS (COMPANIES) --- Somente admin
+		r.Route("/api/companies", func(r chi.Router) {
+			r.Use(adminOnly)
 
-		// Rota Especial: Ver apontamentos deste contrato
-		r.Get("/{contractID}/appointments", appointmentHandler.ListAppointmentsByContract)
-	})
+			r.Post("/", h.Company.CreateHandler)       // Criar empresa
+			r.Get("/", h.Company.GetAllHandler)        // Listar empresas
+			r.Get("/lookup", h.Company.LookupCNPJ)     // Consulta o CNPJ no provedor (?cnpj=)
+			r.Get("/{id}", h.Company.GetByIDHandler)   // Detalhe da empresa
+			r.Put("/{id}", h.Company.UpdateHandler)    // Atualizar
+			r.Delete("/{id}", h.Company.DeleteHandler) // Deletar (remoção lógica)
+			r.Post("/{id}/restore", h.Company.RestoreHandler)
+			r.Delete("/{id}/purge", h.Company.PurgeHandler) // Apaga de vez (só já removida)
+
+			r.Get("/{companyID}/contracts", h.Contract.ListContractsByCompany)
+
+			// Contatos (financeiro, técnico, gestor) e endereços de cobrança da empresa
+			r.Route("/{companyID}/contacts", companyChildRoutes(h.Contact.BaseHandler))
+			r.Route("/{companyID}/addresses", companyChildRoutes(h.Address.BaseHandler))
+		})
+
+		// --- 2. ROTAS DE USUÁRIOS (USERS) --- Admin gerencia; consultor só vê a si mesmo
+		r.Route("/api/users", func(r chi.Router) {
+			r.With(adminOnly).Post("/", h.User.CreateHandler)
+			r.With(adminOnly).Get("/", h.User.GetAllHandler)
+			r.With(auth.RequireSelfOrRole("id", models.RoleAdmin)).Get("/{id}", h.User.GetByIDHandler)
+			r.With(adminOnly).Put("/{id}", h.User.UpdateHandler)
+			r.With(adminOnly).Delete("/{id}", h.User.DeleteHandler)
+			r.With(adminOnly).Post("/{id}/restore", h.User.RestoreHandler)
+			r.With(adminOnly).Delete("/{id}/purge", h.User.PurgeHandler)
+
+			// Rota Especial: Ver apontamentos deste usuário
+			r.With(auth.RequireSelfOrRole("userID", models.RoleAdmin)).Get("/{userID}/appointments", h.Appointment.ListAppointmentsByUser)
+			r.With(auth.RequireSelfOrRole("userID", models.RoleAdmin)).Get("/{userID}/appointments/running", h.Appointment.GetRunningByUser)
+		})
+
+		// --- 3. ROTAS DE CONTRATOS (CONTRACTS) --- Admin gerencia; consultor lê os ativos
+		r.Route("/api/contracts", func(r chi.Router) {
+			r.With(adminOnly).Post("/", h.Contract.CreateHandler)
+			r.Get("/", h.Contract.GetAllHandler) // Lista Turbinada (com JOIN)
+			r.Get("/{id}", h.Contract.GetByIDHandler)
+			r.With(adminOnly).Put("/{id}", h.Contract.UpdateHandler)
+			r.With(adminOnly).Delete("/{id}", h.Contract.DeleteHandler)
+			r.With(adminOnly).Post("/{id}/restore", h.Contract.RestoreHandler)
+			r.With(adminOnly).Delete("/{id}/purge", h.Contract.PurgeHandler)
+
+			r.Get("/{id}/balance", h.Contract.GetContractBalance)                          // Saldo de horas
+			r.With(adminOnly).Get("/{id}/statement.pdf", h.Statement.ContractStatementPDF) // Extrato mensal
+			r.With(adminOnly).Get("/{id}/sla-report", h.Report.SLAReport)                  // Conformidade de SLA por mês
+
+			// Rota Especial: Ver apontamentos deste contrato
+			r.With(adminOnly).Get("/{contractID}/appointments", h.Appointment.ListAppointmentsByContract)
+			r.With(adminOnly).Get("/{contractID}/billing-rates", h.BillingRate.ListRatesByContract)
+		})
+
+		// --- 4. ROTAS DE APONTAMENTOS (APPOINTMENTS) --- Consultor só mexe nos próprios
+		r.Route("/api/appointments", func(r chi.Router) {
+			r.Post("/", h.Appointment.CreateHandler)                // Lançar horas
+			r.With(adminOnly).Get("/", h.Appointment.GetAllHandler) // Visão Admin (Tudo)
+			r.Get("/{id}", h.Appointment.GetByIDHandler)
+			r.Put("/{id}", h.Appointment.UpdateHandler)
+			r.Delete("/{id}", h.Appointment.DeleteHandler)
+			r.With(adminOnly).Post("/{id}/restore", h.Appointment.RestoreHandler)
+			r.With(adminOnly).Delete("/{id}/purge", h.Appointment.PurgeHandler)
+
+			// Cronômetro: no máximo um apontamento em andamento por usuário
+			r.Post("/start", h.Appointment.StartTimer)
+			r.Post("/{id}/stop", h.Appointment.StopTimer)
+		})
 
-	// --- 4. ROTAS DE APONTAMENTOS (APPOINTMENTS) ---
-	r.Route("/api/appointments", func(r chi.Router) {
-		r.Post("/", appointmentHandler.CreateHandler) // Lançar horas
-		r.Get("/", appointmentHandler.GetAllHandler)  // Visão Admin (Tudo)
-		r.Delete("/{id}", appointmentHandler.DeleteHandler)
+		// --- 5. CHAMADOS (TICKETS) --- Consultor altera os próprios ou sem responsável
+		r.Route("/api/tickets", func(r chi.Router) {
+			r.Post("/", h.Ticket.CreateHandler)
+			r.Get("/", h.Ticket.GetAllHandler) // Com empresa, responsável e horas gastas
+			r.Get("/{id}", h.Ticket.GetByIDHandler)
+			r.Put("/{id}", h.Ticket.UpdateHandler)
+			r.With(adminOnly).Delete("/{id}", h.Ticket.DeleteHandler)
+			r.With(adminOnly).Post("/{id}/restore", h.Ticket.RestoreHandler)
+			r.With(adminOnly).Delete("/{id}/purge", h.Ticket.PurgeHandler)
+
+			// Transições de status
+			r.Post("/{id}/start", h.Ticket.Transition(models.TicketInProgress))
+			r.Post("/{id}/wait", h.Ticket.Transition(models.TicketWaitingClient))
+			r.Post("/{id}/resolve", h.Ticket.Transition(models.TicketResolved))
+			r.Post("/{id}/close", h.Ticket.Transition(models.TicketClosed))
+			r.Post("/{id}/reopen", h.Ticket.Transition(models.TicketOpen))
+
+			r.With(adminOnly).Get("/{ticketID}/appointments", h.Appointment.ListAppointmentsByTicket)
+		})
+
+		// --- 6. SLA --- Políticas e calendários (admin); consultor pode consultar
+		r.Route("/api/sla-policies", func(r chi.Router) {
+			r.With(adminOnly).Post("/", h.SLAPolicy.CreateHandler)
+			r.Get("/", h.SLAPolicy.GetAllHandler)
+			r.Get("/{id}", h.SLAPolicy.GetByIDHandler)
+			r.With(adminOnly).Put("/{id}", h.SLAPolicy.UpdateHandler)
+			r.With(adminOnly).Delete("/{id}", h.SLAPolicy.DeleteHandler)
+			r.With(adminOnly).Post("/{id}/restore", h.SLAPolicy.RestoreHandler)
+			r.With(adminOnly).Delete("/{id}/purge", h.SLAPolicy.PurgeHandler)
+		})
+		r.Route("/api/business-calendars", func(r chi.Router) {
+			r.With(adminOnly).Post("/", h.Calendar.CreateHandler)
+			r.Get("/", h.Calendar.GetAllHandler)
+			r.Get("/{id}", h.Calendar.GetByIDHandler)
+			r.With(adminOnly).Put("/{id}", h.Calendar.UpdateHandler)
+			r.With(adminOnly).Delete("/{id}", h.Calendar.DeleteHandler)
+			r.With(adminOnly).Post("/{id}/restore", h.Calendar.RestoreHandler)
+			r.With(adminOnly).Delete("/{id}/purge", h.Calendar.PurgeHandler)
+		})
+
+		// --- 7. FOLHAS DE HORAS (TIMESHEETS) --- Consultor envia a semana; admin aprova ou recusa
+		r.Route("/api/timesheets", func(r chi.Router) {
+			r.Post("/", h.Timesheet.CreateHandler) // Enviar semana
+			r.Get("/", h.Timesheet.GetAllHandler)
+			r.Get("/{id}", h.Timesheet.GetByIDHandler)
+
+			r.With(adminOnly).Post("/{id}/approve", h.Timesheet.Review(models.TimesheetApproved))
+			r.With(adminOnly).Post("/{id}/reject", h.Timesheet.Review(models.TimesheetRejected))
+
+			r.Get("/{timesheetID}/appointments", h.Appointment.ListAppointmentsByTimesheet)
+		})
+
+		// --- 8. VALORES-HORA (BILLING RATES) --- Somente admin
+		r.Route("/api/billing-rates", func(r chi.Router) {
+			r.Use(adminOnly)
+
+			r.Post("/", h.BillingRate.CreateHandler)
+			r.Get("/", h.BillingRate.GetAllHandler)
+			r.Get("/{id}", h.BillingRate.GetByIDHandler)
+			r.Put("/{id}", h.BillingRate.UpdateHandler)
+			r.Delete("/{id}", h.BillingRate.DeleteHandler)
+			r.Post("/{id}/restore", h.BillingRate.RestoreHandler)
+			r.Delete("/{id}/purge", h.BillingRate.PurgeHandler)
+		})
+
+		// --- 9. FATURAS (INVOICES) --- Somente admin
+		r.Route("/api/invoices", func(r chi.Router) {
+			r.Use(adminOnly)
+
+			r.Post("/", h.Invoice.CreateHandler)
+			r.Get("/", h.Invoice.GetAllHandler)
+			r.Get("/{id}", h.Invoice.GetByIDHandler)
+			r.Delete("/{id}", h.Invoice.DeleteHandler)
+
+			r.Get("/{id}/lines", h.Invoice.ListLines)
+			r.Post("/{id}/lines", h.Invoice.AddLine)
+			r.Delete("/{id}/lines/{lineID}", h.Invoice.DeleteLine)
+			r.Get("/{id}/invoice.pdf", h.Invoice.InvoicePDF)
+
+			r.Post("/{id}/issue", h.Invoice.Transition(models.InvoiceIssued))
+			r.Post("/{id}/pay", h.Invoice.Transition(models.InvoicePaid))
+			r.Post("/{id}/void", h.Invoice.Transition(models.InvoiceVoid))
+		})
+
+		// --- 10. RELATÓRIOS (REPORTS) --- Consultor vê só as próprias horas; faturamento só admin
+		r.Route("/api/reports", func(r chi.Router) {
+			r.Get("/hours", h.Report.HoursReport) // Fechamento mensal
+			r.With(adminOnly).Get("/revenue", h.Report.RevenueReport)
+		})
+
+		// --- 11. AUDITORIA (AUDIT) --- Somente admin
+		r.With(adminOnly).Get("/api/audit", h.Audit.ListAudit)
 	})
 
 	return r
 }
+
+// companyChildRoutes monta o CRUD de um cadastro aninhado em /api/companies/{companyID}.
+func companyChildRoutes[T models.Model](h *handlers.BaseHandler[T]) func(chi.Router) {
+	return func(r chi.Router) {
+		r.Get("/", h.GetAllHandler)
+		r.Post("/", h.CreateHandler)
+		r.Get("/{id}", h.GetByIDHandler)
+		r.Put("/{id}", h.UpdateHandler)
+		r.Delete("/{id}", h.DeleteHandler)
+		r.Post("/{id}/restore", h.RestoreHandler)
+		r.Delete("/{id}/purge", h.PurgeHandler)
+	}
+}
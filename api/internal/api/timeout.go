@@ -0,0 +1,44 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"nexus/internal/utils"
+)
+
+// Timeouts limita quanto tempo as consultas de uma requisição podem levar. O prazo vai no
+// contexto da requisição, que os repositórios repassam ao banco: estourado, a consulta é
+// cancelada no PostgreSQL. Zero desliga o limite.
+type Timeouts struct {
+	Default time.Duration // Rotas comuns (CRUD, listas em JSON)
+	Reports time.Duration // Relatórios, PDFs e exportações CSV/XLSX, que varrem muitas linhas
+}
+
+// queryTimeout aplica o prazo de Timeouts conforme o tipo da requisição.
+func queryTimeout(t Timeouts) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			d := t.Default
+			if isReportRequest(r) {
+				d = t.Reports
+			}
+			if d <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// isReportRequest identifica as requisições pesadas: /api/reports, arquivos .pdf e exportações.
+func isReportRequest(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.Path, "/api/reports") ||
+		strings.HasSuffix(r.URL.Path, ".pdf") ||
+		utils.ExportFormatFromRequest(r) != ""
+}
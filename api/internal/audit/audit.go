@@ -0,0 +1,103 @@
+// Package audit grava a trilha de auditoria: quem criou, alterou ou removeu cada registro.
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"reflect"
+	"strings"
+	"time"
+
+	"nexus/internal/auth"
+	"nexus/internal/models"
+	"nexus/internal/repository"
+)
+
+type contextKey struct{}
+
+// WithRepository devolve um contexto em que Record grava no repositório informado.
+// Sem ele (ex.: worker, testes), Record não faz nada.
+func WithRepository(ctx context.Context, repo repository.AuditRepository) context.Context {
+	return context.WithValue(ctx, contextKey{}, repo)
+}
+
+// Record registra uma operação do usuário logado (do ctx) sobre um registro. before é nil na
+// criação e after é nil na remoção. Falhas só vão para o log e não desfazem a alteração:
+// dentro de uma transação (repository.TxManager) o registro entra nela por um savepoint.
+func Record(ctx context.Context, entity, operation string, id int64, before, after any) {
+	repo, ok := ctx.Value(contextKey{}).(repository.AuditRepository)
+	if !ok {
+		return
+	}
+	changes := Diff(before, after)
+	if operation == models.AuditUpdate && len(changes) == 0 {
+		return
+	}
+	payload, err := json.Marshal(changes)
+	if err != nil {
+		log.Printf("Erro ao montar auditoria de %s #%d: %v", entity, id, err)
+		return
+	}
+
+	entry := &models.AuditEntry{
+		OccurredAt: time.Now(),
+		Entity:     entity,
+		EntityID:   id,
+		Operation:  operation,
+		Changes:    payload,
+	}
+	if user := auth.UserFromContext(ctx); user != nil {
+		entry.ActorID = &user.ID
+	}
+	if err := repo.Record(ctx, entry); err != nil {
+		log.Printf("Erro ao registrar auditoria de %s #%d: %v", entity, id, err)
+	}
+}
+
+// Diff compara os campos gravados (tag db) de dois modelos do mesmo tipo pelo seu JSON
+// e devolve os que mudaram, pelo nome JSON. Campos fora do JSON (ex.: hash de senha) e o id ficam de fora.
+func Diff(before, after any) map[string]models.AuditChange {
+	oldValues, newValues := fields(before), fields(after)
+	changes := make(map[string]models.AuditChange)
+	for name, newValue := range newValues {
+		if oldValue, ok := oldValues[name]; !ok || !bytes.Equal(oldValue, newValue) {
+			changes[name] = models.AuditChange{Old: oldValues[name], New: newValue}
+		}
+	}
+	for name, oldValue := range oldValues {
+		if _, ok := newValues[name]; !ok {
+			changes[name] = models.AuditChange{Old: oldValue}
+		}
+	}
+	return changes
+}
+
+// fields devolve o JSON de cada campo gravado do modelo (nil = nenhum campo).
+func fields(model any) map[string]json.RawMessage {
+	values := make(map[string]json.RawMessage)
+	val := reflect.ValueOf(model)
+	if !val.IsValid() || (val.Kind() == reflect.Pointer && val.IsNil()) {
+		return values
+	}
+	val = reflect.Indirect(val)
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		dbTag := strings.Split(field.Tag.Get("db"), ",")[0]
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if dbTag == "" || dbTag == "id" || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		value, err := json.Marshal(val.Field(i).Interface())
+		if err != nil {
+			continue
+		}
+		values[name] = value
+	}
+	return values
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+
+	"nexus/internal/models"
+	"nexus/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// IsAdmin indica se o usuário tem papel de administrador.
+func IsAdmin(user *models.User) bool {
+	return user != nil && user.Role == models.RoleAdmin
+}
+
+// HasRole indica se o usuário tem um dos papéis informados.
+func HasRole(user *models.User, roles ...string) bool {
+	return user != nil && slices.Contains(roles, user.Role)
+}
+
+// RequireRole só deixa passar usuários com um dos papéis informados.
+// Deve ser usado depois de Middleware, que coloca o usuário no contexto.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !HasRole(UserFromContext(r.Context()), roles...) {
+				utils.RespondWithError(w, http.StatusForbidden, "Você não tem permissão para esta operação")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireSelfOrRole deixa passar o próprio usuário (o ID do parâmetro de URL é o dele)
+// ou usuários com um dos papéis informados. Ex.: RequireSelfOrRole("userID", models.RoleAdmin).
+func RequireSelfOrRole(param string, roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user := UserFromContext(r.Context())
+			if HasRole(user, roles...) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			id, err := strconv.ParseInt(chi.URLParam(r, param), 10, 64)
+			if err != nil || user == nil || id != user.ID {
+				utils.RespondWithError(w, http.StatusForbidden, "Você não tem permissão para esta operação")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package auth
+
+import (
+	"context"
+
+	"nexus/internal/models"
+)
+
+type contextKey struct{}
+
+// WithUser devolve um contexto carregando o usuário autenticado.
+func WithUser(ctx context.Context, user *models.User) context.Context {
+	return context.WithValue(ctx, contextKey{}, user)
+}
+
+// UserFromContext devolve o usuário autenticado da requisição (ou nil).
+func UserFromContext(ctx context.Context) *models.User {
+	user, _ := ctx.Value(contextKey{}).(*models.User)
+	return user
+}
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	"nexus/internal/repository"
+	"nexus/internal/utils"
+)
+
+// Middleware exige um token de acesso válido no header Authorization ("Bearer <token>")
+// e coloca o usuário autenticado no contexto da requisição.
+func Middleware(tokens *TokenManager, users repository.UserRepository) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("Authorization")
+			tokenString, ok := strings.CutPrefix(header, "Bearer ")
+			if !ok || tokenString == "" {
+				utils.RespondWithError(w, http.StatusUnauthorized, "Token de acesso não informado")
+				return
+			}
+
+			claims, err := tokens.Parse(tokenString, TokenTypeAccess)
+			if err != nil {
+				utils.RespondWithError(w, http.StatusUnauthorized, "Token de acesso inválido ou expirado")
+				return
+			}
+
+			userID, err := claims.UserID()
+			if err != nil {
+				utils.RespondWithError(w, http.StatusUnauthorized, "Token de acesso inválido ou expirado")
+				return
+			}
+
+			// Busca o usuário a cada requisição: usuários removidos perdem o acesso na hora
+			found, err := users.Get(r.Context(), &userID)
+			if err != nil {
+				utils.RespondWithError(w, http.StatusInternalServerError, "Erro ao validar usuário")
+				return
+			}
+			if len(found) == 0 {
+				utils.RespondWithError(w, http.StatusUnauthorized, "Usuário não encontrado")
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithUser(r.Context(), found[0])))
+		})
+	}
+}
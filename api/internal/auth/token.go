@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"nexus/internal/models"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
+var ErrInvalidToken = errors.New("token inválido")
+
+// Claims são as informações assinadas dentro de cada token.
+type Claims struct {
+	Role string `json:"role"`
+	Type string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+// UserID devolve o ID do usuário guardado no "sub" do token.
+func (c *Claims) UserID() (int64, error) {
+	return strconv.ParseInt(c.Subject, 10, 64)
+}
+
+// TokenManager emite e valida os tokens de acesso e de refresh (HS256).
+type TokenManager struct {
+	secret     []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+// NewTokenManager cria um TokenManager com o segredo e as validades informadas.
+func NewTokenManager(secret string, accessTTL, refreshTTL time.Duration) *TokenManager {
+	return &TokenManager{
+		secret:     []byte(secret),
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+	}
+}
+
+// AccessTTL é a validade dos tokens de acesso.
+func (m *TokenManager) AccessTTL() time.Duration {
+	return m.accessTTL
+}
+
+// GenerateAccessToken emite um token de acesso de curta duração para o usuário.
+func (m *TokenManager) GenerateAccessToken(user *models.User) (string, error) {
+	token, _, err := m.generate(user, TokenTypeAccess, m.accessTTL)
+	return token, err
+}
+
+// GenerateRefreshToken emite um refresh token e devolve também o registro que deve ser persistido.
+func (m *TokenManager) GenerateRefreshToken(user *models.User) (string, *models.RefreshToken, error) {
+	token, claims, err := m.generate(user, TokenTypeRefresh, m.refreshTTL)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, &models.RefreshToken{
+		ID:        claims.ID,
+		UserID:    user.ID,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}, nil
+}
+
+// Parse valida a assinatura, a validade e o tipo do token.
+func (m *TokenManager) Parse(tokenString, expectedType string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		return m.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if claims.Type != expectedType {
+		return nil, fmt.Errorf("%w: tipo %q inesperado", ErrInvalidToken, claims.Type)
+	}
+	return claims, nil
+}
+
+func (m *TokenManager) generate(user *models.User, tokenType string, ttl time.Duration) (string, *Claims, error) {
+	jti, err := newTokenID()
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now()
+	claims := &Claims{
+		Role: user.Role,
+		Type: tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Subject:   strconv.FormatInt(user.ID, 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.secret)
+	if err != nil {
+		return "", nil, fmt.Errorf("erro ao assinar token: %w", err)
+	}
+	return signed, claims, nil
+}
+
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("erro ao gerar id do token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
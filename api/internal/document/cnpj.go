@@ -0,0 +1,77 @@
+// Package document valida, normaliza e formata os documentos brasileiros (CNPJ e CPF).
+// A forma canônica, que vai para o banco, é só com letras maiúsculas e dígitos, sem máscara.
+package document
+
+import "strings"
+
+// cnpjLength é o tamanho do CNPJ sem máscara: 12 posições de raiz/ordem e 2 dígitos verificadores.
+const cnpjLength = 14
+
+// NormalizeCNPJ tira a máscara (pontos, barra, hífen e espaços) e passa as letras para maiúsculas.
+// Não valida: "12.345.678/0001-90" vira "12345678000190", mesmo com dígito errado.
+func NormalizeCNPJ(cnpj string) string {
+	return strings.ToUpper(stripMask(cnpj))
+}
+
+// ValidCNPJ confere formato e dígitos verificadores, com ou sem máscara. Aceita o CNPJ
+// alfanumérico (a partir de julho/2026): as 12 primeiras posições podem ter letras e
+// os dois verificadores continuam numéricos.
+func ValidCNPJ(cnpj string) bool {
+	cnpj = NormalizeCNPJ(cnpj)
+	if len(cnpj) != cnpjLength || repeated(cnpj) {
+		return false
+	}
+	for i := 0; i < cnpjLength; i++ {
+		c := cnpj[i]
+		if !isDigit(c) && (i >= 12 || c < 'A' || c > 'Z') {
+			return false
+		}
+	}
+	return cnpj[12] == cnpjCheckDigit(cnpj[:12]) && cnpj[13] == cnpjCheckDigit(cnpj[:13])
+}
+
+// FormatCNPJ aplica a máscara 00.000.000/0000-00. Valor que não tem 14 posições volta como veio.
+func FormatCNPJ(cnpj string) string {
+	n := NormalizeCNPJ(cnpj)
+	if len(n) != cnpjLength {
+		return cnpj
+	}
+	return n[:2] + "." + n[2:5] + "." + n[5:8] + "/" + n[8:12] + "-" + n[12:]
+}
+
+// cnpjCheckDigit calcula o verificador do prefixo (12 posições para o 1º, 13 para o 2º).
+// Cada posição vale o código ASCII menos 48 ('0'..'9' = 0..9, 'A' = 17...), com pesos
+// de 2 a 9 da direita para a esquerda; resto menor que 2 dá dígito 0.
+func cnpjCheckDigit(prefix string) byte {
+	sum, weight := 0, 2
+	for i := len(prefix) - 1; i >= 0; i-- {
+		sum += int(prefix[i]-'0') * weight
+		if weight++; weight > 9 {
+			weight = 2
+		}
+	}
+	if rest := sum % 11; rest >= 2 {
+		return byte('0' + 11 - rest)
+	}
+	return '0'
+}
+
+// stripMask tira os separadores usuais das máscaras de CNPJ e CPF.
+func stripMask(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '/', '-', ' ':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(s))
+}
+
+// repeated indica sequências como 00000000000000, que passam no cálculo mas não existem.
+func repeated(s string) bool {
+	return strings.Count(s, s[:1]) == len(s)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
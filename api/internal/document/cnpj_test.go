@@ -0,0 +1,71 @@
+package document
+
+import "testing"
+
+func TestValidCNPJ(t *testing.T) {
+	tests := []struct {
+		name string
+		cnpj string
+		want bool
+	}{
+		{"numérico sem máscara", "11222333000181", true},
+		{"numérico com máscara", "11.222.333/0001-81", true},
+		{"com espaços nas pontas", " 11.222.333/0001-81 ", true},
+		{"alfanumérico sem máscara", "12ABC34501DE35", true},
+		{"alfanumérico com máscara", "12.ABC.345/01DE-35", true},
+		{"alfanumérico em minúsculas", "12.abc.345/01de-35", true},
+		{"1º verificador errado", "11222333000191", false},
+		{"2º verificador errado", "11222333000182", false},
+		{"alfanumérico com verificador errado", "12ABC34501DE36", false},
+		{"letra no verificador", "12ABC34501DE3A", false},
+		{"caractere inválido", "12ABC345#1DE35", false},
+		{"curto", "1122233300018", false},
+		{"longo", "112223330001810", false},
+		{"vazio", "", false},
+		{"zeros repetidos", "00000000000000", false},
+		{"dígitos repetidos com máscara", "11.111.111/1111-11", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValidCNPJ(tt.cnpj); got != tt.want {
+				t.Errorf("ValidCNPJ(%q) = %v, esperado %v", tt.cnpj, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeCNPJ(t *testing.T) {
+	tests := []struct {
+		cnpj string
+		want string
+	}{
+		{"11.222.333/0001-81", "11222333000181"},
+		{"11222333000181", "11222333000181"},
+		{"12.abc.345/01de-35", "12ABC34501DE35"},
+		{" 12 ABC 345 01DE 35 ", "12ABC34501DE35"},
+		{"11.222.333/0001-99", "11222333000199"}, // não valida
+	}
+	for _, tt := range tests {
+		if got := NormalizeCNPJ(tt.cnpj); got != tt.want {
+			t.Errorf("NormalizeCNPJ(%q) = %q, esperado %q", tt.cnpj, got, tt.want)
+		}
+	}
+}
+
+func TestFormatCNPJ(t *testing.T) {
+	tests := []struct {
+		cnpj string
+		want string
+	}{
+		{"11222333000181", "11.222.333/0001-81"},
+		{"11.222.333/0001-81", "11.222.333/0001-81"},
+		{"12abc34501de35", "12.ABC.345/01DE-35"},
+		{"123", "123"}, // tamanho errado volta como veio
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := FormatCNPJ(tt.cnpj); got != tt.want {
+			t.Errorf("FormatCNPJ(%q) = %q, esperado %q", tt.cnpj, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package document
+
+// cpfLength é o tamanho do CPF sem máscara: 9 dígitos e 2 verificadores.
+const cpfLength = 11
+
+// NormalizeCPF tira a máscara (pontos, hífen e espaços). Não valida.
+func NormalizeCPF(cpf string) string {
+	return stripMask(cpf)
+}
+
+// ValidCPF confere formato e dígitos verificadores, com ou sem máscara.
+func ValidCPF(cpf string) bool {
+	cpf = NormalizeCPF(cpf)
+	if len(cpf) != cpfLength || repeated(cpf) {
+		return false
+	}
+	for i := 0; i < cpfLength; i++ {
+		if !isDigit(cpf[i]) {
+			return false
+		}
+	}
+	return cpf[9] == cpfCheckDigit(cpf[:9]) && cpf[10] == cpfCheckDigit(cpf[:10])
+}
+
+// FormatCPF aplica a máscara 000.000.000-00. Valor que não tem 11 dígitos volta como veio.
+func FormatCPF(cpf string) string {
+	n := NormalizeCPF(cpf)
+	if len(n) != cpfLength {
+		return cpf
+	}
+	return n[:3] + "." + n[3:6] + "." + n[6:9] + "-" + n[9:]
+}
+
+// cpfCheckDigit calcula o verificador do prefixo (9 dígitos para o 1º, 10 para o 2º),
+// com pesos decrescentes a partir de len(prefix)+1.
+func cpfCheckDigit(prefix string) byte {
+	sum := 0
+	for i := 0; i < len(prefix); i++ {
+		sum += int(prefix[i]-'0') * (len(prefix) + 1 - i)
+	}
+	if rest := sum % 11; rest >= 2 {
+		return byte('0' + 11 - rest)
+	}
+	return '0'
+}
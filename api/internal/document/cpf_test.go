@@ -0,0 +1,52 @@
+package document
+
+import "testing"
+
+func TestValidCPF(t *testing.T) {
+	tests := []struct {
+		name string
+		cpf  string
+		want bool
+	}{
+		{"sem máscara", "52998224725", true},
+		{"com máscara", "529.982.247-25", true},
+		{"outro válido", "111.444.777-35", true},
+		{"verificador 0", "123.456.789-09", true},
+		{"1º verificador errado", "52998224735", false},
+		{"2º verificador errado", "52998224726", false},
+		{"letra", "5299822472A", false},
+		{"curto", "5299822472", false},
+		{"longo", "529982247250", false},
+		{"vazio", "", false},
+		{"zeros repetidos", "00000000000", false},
+		{"dígitos repetidos com máscara", "111.111.111-11", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValidCPF(tt.cpf); got != tt.want {
+				t.Errorf("ValidCPF(%q) = %v, esperado %v", tt.cpf, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeAndFormatCPF(t *testing.T) {
+	tests := []struct {
+		cpf        string
+		normalized string
+		formatted  string
+	}{
+		{"529.982.247-25", "52998224725", "529.982.247-25"},
+		{"52998224725", "52998224725", "529.982.247-25"},
+		{" 529 982 247 25 ", "52998224725", "529.982.247-25"},
+		{"123", "123", "123"}, // tamanho errado volta como veio
+	}
+	for _, tt := range tests {
+		if got := NormalizeCPF(tt.cpf); got != tt.normalized {
+			t.Errorf("NormalizeCPF(%q) = %q, esperado %q", tt.cpf, got, tt.normalized)
+		}
+		if got := FormatCPF(tt.cpf); got != tt.formatted {
+			t.Errorf("FormatCPF(%q) = %q, esperado %q", tt.cpf, got, tt.formatted)
+		}
+	}
+}
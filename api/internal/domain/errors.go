@@ -0,0 +1,113 @@
+// Package domain define os erros de negócio da API. Casos de uso e repositórios devolvem
+// *Error; os handlers escolhem o status HTTP pelo Kind e mandam Code, Message e Fields
+// ao cliente como problem+json (RFC 7807). A causa (Err) fica só no log.
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+// Kind classifica o erro; cada um corresponde a um status HTTP.
+type Kind int
+
+const (
+	KindInternal    Kind = iota // 500
+	KindValidation              // 400
+	KindNotFound                // 404
+	KindConflict                // 409
+	KindForbidden               // 403
+	KindUnavailable             // 503
+)
+
+// Códigos genéricos, usados quando não há um mais específico (ex.: constraint sem tradução).
+const (
+	CodeValidation        = "validation_failed"
+	CodeRequired          = "required"
+	CodeInvalidValue      = "invalid_value"
+	CodeDuplicate         = "duplicate"
+	CodeOverlap           = "overlap"
+	CodeInUse             = "in_use"
+	CodeReferenceNotFound = "reference_not_found"
+)
+
+// FieldError aponta o problema em um campo do corpo (pelo nome no JSON),
+// para o frontend mostrar a mensagem ao lado do input.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error é um erro de negócio. Code é estável (o cliente pode comparar); Message é para o usuário.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Fields  []FieldError
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// On liga o erro a um campo do corpo, com o mesmo código e mensagem.
+func (e *Error) On(field string) *Error {
+	e.Fields = append(e.Fields, FieldError{Field: field, Code: e.Code, Message: e.Message})
+	return e
+}
+
+// Field descreve o erro de um campo, para Invalid.
+func Field(field, code, message string) FieldError {
+	return FieldError{Field: field, Code: code, Message: message}
+}
+
+// Invalid junta os erros de campo em um só erro de validação; a mensagem é a soma das dos campos.
+func Invalid(fields ...FieldError) *Error {
+	messages := make([]string, len(fields))
+	for i, f := range fields {
+		messages[i] = f.Message
+	}
+	return &Error{Kind: KindValidation, Code: CodeValidation, Message: strings.Join(messages, "; "), Fields: fields}
+}
+
+// Validation é o erro de validação que não é de um campo só (ex.: lote vazio).
+func Validation(code, message string) *Error {
+	return &Error{Kind: KindValidation, Code: code, Message: message}
+}
+
+func NotFound(code, message string) *Error {
+	return &Error{Kind: KindNotFound, Code: code, Message: message}
+}
+
+func Conflict(code, message string) *Error {
+	return &Error{Kind: KindConflict, Code: code, Message: message}
+}
+
+func Forbidden(code, message string) *Error {
+	return &Error{Kind: KindForbidden, Code: code, Message: message}
+}
+
+// Unavailable é o erro de um serviço externo fora do ar ou não configurado; err é a causa, se houver.
+func Unavailable(code, message string, err error) *Error {
+	return &Error{Kind: KindUnavailable, Code: code, Message: message, Err: err}
+}
+
+// As devolve o *Error da cadeia de err, se houver.
+func As(err error) (*Error, bool) {
+	var e *Error
+	ok := errors.As(err, &e)
+	return e, ok
+}
+
+// HasCode indica se err é um *Error com o código informado.
+func HasCode(err error, code string) bool {
+	e, ok := As(err)
+	return ok && e.Code == code
+}
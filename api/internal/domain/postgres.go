@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Códigos SQLSTATE das violações de integridade (classe 23).
+const (
+	pgNotNullViolation    = "23502"
+	pgForeignKeyViolation = "23503"
+	pgUniqueViolation     = "23505"
+	pgCheckViolation      = "23514"
+	pgExclusionViolation  = "23P01"
+)
+
+// constraint é a tradução de uma constraint conhecida: campo do JSON, código e mensagem.
+type constraint struct {
+	field   string
+	code    string
+	message string
+}
+
+// constraints traduz as constraints que o usuário consegue violar pela API.
+// As que não estão aqui caem na tradução genérica pelo SQLSTATE.
+var constraints = map[string]constraint{
+	"ux_companies_cnpj":                 {"cnpj", "cnpj_taken", "Este CNPJ já está cadastrado no sistema."},
+	"chk_companies_cnpj_format":         {"cnpj", "invalid_cnpj", "cnpj não é um CNPJ válido"},
+	"ux_users_email":                    {"email", "email_taken", "E-mail já cadastrado"},
+	"users_role_check":                  {"role", "invalid_role", "Papel inválido (use admin ou consultant)"},
+	"chk_contracts_type":                {"contractType", "invalid_contract_type", "Tipo de contrato inválido"},
+	"chk_end_time_valid":                {"endTime", "end_before_start", "A data de fim não pode ser anterior ao início"},
+	"ux_appointments_running_per_user":  {"", "timer_running", "Já existe um apontamento em andamento para este usuário. Use /api/appointments/start para trocar de tarefa."},
+	"excl_appointments_user_overlap":    {"", "appointment_overlap", "O intervalo informado se sobrepõe a outro apontamento deste usuário"},
+	"excl_billing_rates_overlap":        {"", "rate_overlap", "Já existe um valor-hora deste contrato/consultor nesta vigência"},
+	"billing_rates_contract_id_fkey":    {"contractId", "contract_not_found", "Contrato não encontrado"},
+	"billing_rates_user_id_fkey":        {"userId", "user_not_found", "Usuário não encontrado"},
+	"ux_company_contacts_email":         {"email", "contact_email_taken", "Este e-mail já é de outro contato da empresa"},
+	"ux_company_contacts_primary":       {"primary", "primary_taken", "A empresa já tem um contato principal"},
+	"ux_company_addresses_primary":      {"primary", "primary_taken", "A empresa já tem um endereço principal"},
+	"company_contacts_company_id_fkey":  {"companyId", "company_not_found", "Empresa não encontrada"},
+	"company_addresses_company_id_fkey": {"companyId", "company_not_found", "Empresa não encontrada"},
+}
+
+// FromPostgres traduz violações de integridade do PostgreSQL (unique, foreign key, check,
+// exclusion, not null) em *Error; qualquer outro erro volta como veio. A mensagem do banco
+// não vai para o cliente, só fica em Err.
+func FromPostgres(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	var e *Error
+	switch pgErr.Code {
+	case pgUniqueViolation:
+		e = Conflict(CodeDuplicate, "Já existe um registro com estes dados")
+	case pgExclusionViolation:
+		e = Conflict(CodeOverlap, "O período informado conflita com outro registro")
+	case pgForeignKeyViolation:
+		// Em INSERT/UPDATE: o registro apontado não existe. A remoção vai por FromPostgresDelete
+		e = Validation(CodeReferenceNotFound, "Registro referenciado não encontrado")
+	case pgCheckViolation:
+		e = Validation(CodeInvalidValue, "Valor inválido")
+	case pgNotNullViolation:
+		e = Validation(CodeRequired, "Campo obrigatório não informado").On(jsonName(pgErr.ColumnName))
+	default:
+		return err
+	}
+
+	if known, ok := constraints[pgErr.ConstraintName]; ok {
+		e.Code, e.Message = known.code, known.message
+		if known.field != "" {
+			e.Fields = nil
+			e.On(known.field)
+		}
+	}
+	e.Err = err
+	return e
+}
+
+// FromPostgresDelete é o FromPostgres de um DELETE: a violação de chave estrangeira aí quer
+// dizer que outros registros ainda apontam para o apagado (CodeInUse, 409). O PostgreSQL usa a
+// mesma constraint e a mesma tabela (a que referencia) nos dois sentidos; só a mensagem, que
+// segue o lc_messages do servidor, diferencia. Por isso o sentido vem de quem executou o comando.
+func FromPostgresDelete(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+		return &Error{Kind: KindConflict, Code: CodeInUse, Message: "Existem registros ligados a este registro: apague-os antes", Err: err}
+	}
+	return FromPostgres(err)
+}
+
+// jsonName converte o nome da coluna (snake_case) no do campo JSON (camelCase).
+func jsonName(column string) string {
+	parts := strings.Split(column, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
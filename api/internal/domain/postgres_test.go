@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestFromPostgres(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   *pgconn.PgError
+		kind  Kind
+		code  string
+		field string // "" = sem campo
+	}{
+		{"unique conhecida", &pgconn.PgError{Code: pgUniqueViolation, ConstraintName: "ux_users_email"},
+			KindConflict, "email_taken", "email"},
+		{"unique desconhecida", &pgconn.PgError{Code: pgUniqueViolation, ConstraintName: "ux_outra"},
+			KindConflict, CodeDuplicate, ""},
+		{"exclusion conhecida sem campo", &pgconn.PgError{Code: pgExclusionViolation, ConstraintName: "excl_appointments_user_overlap"},
+			KindConflict, "appointment_overlap", ""},
+		{"exclusion desconhecida", &pgconn.PgError{Code: pgExclusionViolation, ConstraintName: "excl_outra"},
+			KindConflict, CodeOverlap, ""},
+		{"check conhecida", &pgconn.PgError{Code: pgCheckViolation, ConstraintName: "chk_companies_cnpj_format"},
+			KindValidation, "invalid_cnpj", "cnpj"},
+		{"check desconhecida", &pgconn.PgError{Code: pgCheckViolation, ConstraintName: "chk_outra"},
+			KindValidation, CodeInvalidValue, ""},
+		{"not null usa a coluna em camelCase", &pgconn.PgError{Code: pgNotNullViolation, ColumnName: "company_id"},
+			KindValidation, CodeRequired, "companyId"},
+		{"chave estrangeira conhecida", &pgconn.PgError{Code: pgForeignKeyViolation, ConstraintName: "billing_rates_contract_id_fkey", TableName: "billing_rates"},
+			KindValidation, "contract_not_found", "contractId"},
+		{"chave estrangeira desconhecida", &pgconn.PgError{Code: pgForeignKeyViolation, ConstraintName: "tickets_contract_id_fkey", TableName: "tickets"},
+			KindValidation, CodeReferenceNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkDomainError(t, FromPostgres(fmt.Errorf("erro ao salvar: %w", tt.err)), tt.err, tt.kind, tt.code, tt.field)
+		})
+	}
+}
+
+func TestFromPostgresDelete(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   *pgconn.PgError
+		kind  Kind
+		code  string
+		field string
+	}{
+		// Mesma constraint e mesma tabela do INSERT: no DELETE vira "em uso", sem campo
+		{"chave estrangeira conhecida", &pgconn.PgError{Code: pgForeignKeyViolation, ConstraintName: "billing_rates_contract_id_fkey", TableName: "billing_rates"},
+			KindConflict, CodeInUse, ""},
+		{"chave estrangeira desconhecida", &pgconn.PgError{Code: pgForeignKeyViolation, ConstraintName: "tickets_contract_id_fkey", TableName: "tickets"},
+			KindConflict, CodeInUse, ""},
+		{"outros códigos seguem o FromPostgres", &pgconn.PgError{Code: pgUniqueViolation, ConstraintName: "ux_companies_cnpj"},
+			KindConflict, "cnpj_taken", "cnpj"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkDomainError(t, FromPostgresDelete(tt.err), tt.err, tt.kind, tt.code, tt.field)
+		})
+	}
+}
+
+func TestFromPostgresPassThrough(t *testing.T) {
+	plain := errors.New("conexão recusada")
+	if got := FromPostgres(plain); got != plain {
+		t.Errorf("FromPostgres(erro comum) = %v, esperado o mesmo erro", got)
+	}
+	if got := FromPostgresDelete(plain); got != plain {
+		t.Errorf("FromPostgresDelete(erro comum) = %v, esperado o mesmo erro", got)
+	}
+	// SQLSTATE fora da classe 23 (ex.: deadlock) não é erro de negócio
+	deadlock := &pgconn.PgError{Code: "40P01"}
+	if got := FromPostgres(deadlock); got != error(deadlock) {
+		t.Errorf("FromPostgres(deadlock) = %v, esperado o mesmo erro", got)
+	}
+}
+
+// checkDomainError confere Kind, Code e campo do *Error e que a causa do banco ficou em Err.
+func checkDomainError(t *testing.T, got error, cause *pgconn.PgError, kind Kind, code, field string) {
+	t.Helper()
+	var e *Error
+	if !errors.As(got, &e) {
+		t.Fatalf("esperado *Error, veio %T: %v", got, got)
+	}
+	if e.Kind != kind || e.Code != code {
+		t.Errorf("Kind/Code = %d/%s, esperado %d/%s", e.Kind, e.Code, kind, code)
+	}
+	switch {
+	case field == "" && len(e.Fields) != 0:
+		t.Errorf("esperado sem campo, veio %+v", e.Fields)
+	case field != "" && (len(e.Fields) != 1 || e.Fields[0].Field != field || e.Fields[0].Code != code):
+		t.Errorf("campos = %+v, esperado %s/%s", e.Fields, field, code)
+	}
+	var pgErr *pgconn.PgError
+	if !errors.As(e, &pgErr) || pgErr != cause {
+		t.Errorf("a causa do banco não ficou em Err")
+	}
+}
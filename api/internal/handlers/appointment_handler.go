@@ -4,9 +4,12 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 
+	"nexus/internal/auth"
 	"nexus/internal/models"
 	"nexus/internal/repository"
+	"nexus/internal/usecase"
 	"nexus/internal/utils"
 
 	"github.com/go-chi/chi/v5"
@@ -14,19 +17,30 @@ import (
 
 type AppointmentHandler struct {
 	*BaseHandler[*models.Appointment]
-	repo repository.AppointmentRepository
+	repo          repository.AppointmentRepository
+	timesheetRepo repository.TimesheetRepository
+	usecase       usecase.AppointmentUsecase
 }
 
-func NewAppointmentHandler(repo repository.AppointmentRepository) *AppointmentHandler {
+func NewAppointmentHandler(repo repository.AppointmentRepository, timesheetRepo repository.TimesheetRepository, appointmentUsecase usecase.AppointmentUsecase) *AppointmentHandler {
 	baseHandler := NewBaseHandler(repo, "appointments")
 	handler := &AppointmentHandler{
-		BaseHandler: baseHandler,
-		repo:        repo,
+		BaseHandler:   baseHandler,
+		repo:          repo,
+		timesheetRepo: timesheetRepo,
+		usecase:       appointmentUsecase,
 	}
 
 	handler.CreateHandler = handler.CreateAppointmentHandler
+	handler.UpdateHandler = handler.UpdateAppointmentHandler
+	handler.DeleteHandler = handler.DeleteAppointmentHandler
+	handler.RestoreHandler = handler.RestoreAppointmentHandler
 	handler.GetAllHandler = handler.ListAllAppointmentsWithDetails
 
+	// Consultor só enxerga e altera os próprios apontamentos
+	handler.ReadPolicy = usecase.OwnAppointment
+	handler.WritePolicy = usecase.OwnAppointment
+
 	return handler
 }
 
@@ -39,8 +53,10 @@ func NewAppointmentHandler(repo repository.AppointmentRepository) *AppointmentHa
 // @Accept       json
 // @Produce      json
 // @Param        appointment body models.Appointment true "Dados do Apontamento (EndTime opcional)"
+// @Param        allowOverlap query bool false "Admin: permite sobrepor outro apontamento do mesmo usuário"
 // @Success      201  {object}  models.Appointment
-// @Failure      400  {string}  string "Erro de validação"
+// @Failure      400  {string}  string "Erro de validação ou contrato inativo/fora da vigência"
+// @Failure      409  {string}  string "Sobreposição, saldo esgotado (política reject) ou semana enviada/aprovada"
 // @Router       /api/appointments [post]
 // 4. Create customizado (caso precise validar horários no futuro)
 func (h *AppointmentHandler) CreateAppointmentHandler(w http.ResponseWriter, r *http.Request) {
@@ -50,43 +66,171 @@ func (h *AppointmentHandler) CreateAppointmentHandler(w http.ResponseWriter, r *
 		return
 	}
 
-	// Validação Lógica: Se mandou Data Fim, ela tem que ser maior que Início
-	// Se EndTime for nil (nulo), ignora essa validação.
-	if appt.EndTime != nil {
-		if appt.EndTime.Before(appt.StartTime) {
-			utils.RespondWithError(w, http.StatusBadRequest, "A data de fim não pode ser anterior ao início")
-			return
-		}
+	savedAppt, err := h.usecase.Create(r.Context(), appt, r.URL.Query().Get("allowOverlap") == "true")
+	if err != nil {
+		respondError(w, err, "Erro ao salvar apontamento: ")
+		return
 	}
+	utils.RespondWithJSON(w, http.StatusCreated, savedAppt)
+}
 
-	// Grava no banco (O repositório deve estar preparado para aceitar nil)
-	savedAppt, err := h.repo.Save(appt)
+// UpdateAppointment godoc
+// @Summary      Atualiza um apontamento
+// @Description  Altera horários, descrição ou contrato, revalidando contrato e sobreposição.
+// @Tags         appointments
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int                true "ID do Apontamento"
+// @Param        appointment body models.Appointment true "Dados do Apontamento"
+// @Success      200  {object}  models.Appointment
+// @Failure      400  {string}  string "Erro de validação ou contrato inativo/fora da vigência"
+// @Failure      404  {string}  string "Apontamento não encontrado"
+// @Failure      409  {string}  string "Sobreposição, saldo esgotado (política reject) ou semana enviada/aprovada"
+// @Router       /api/appointments/{id} [put]
+func (h *AppointmentHandler) UpdateAppointmentHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := h.parseID(r)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "Erro ao salvar apontamento: "+err.Error())
+		utils.RespondWithError(w, http.StatusBadRequest, "ID inválido")
 		return
 	}
-	utils.RespondWithJSON(w, http.StatusCreated, savedAppt)
+	appt := h.newModel()
+	if err := json.NewDecoder(r.Body).Decode(&appt); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "JSON inválido")
+		return
+	}
+	appt.SetID(id)
+
+	if err := h.usecase.Update(r.Context(), appt, r.URL.Query().Get("allowOverlap") == "true"); err != nil {
+		respondError(w, err, "Erro ao atualizar apontamento: ")
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, appt)
+}
+
+// DeleteAppointment godoc
+// @Summary      Remove um apontamento
+// @Description  Apontamentos de semana aprovada (ou enviada, para o consultor) não podem ser removidos.
+// @Tags         appointments
+// @Param        id   path      int  true  "ID do Apontamento"
+// @Success      204
+// @Failure      404  {string}  string "Apontamento não encontrado"
+// @Failure      409  {string}  string "Semana enviada/aprovada"
+// @Router       /api/appointments/{id} [delete]
+func (h *AppointmentHandler) DeleteAppointmentHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := h.parseID(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+	if err := h.usecase.Delete(r.Context(), id); err != nil {
+		respondError(w, err, "Erro ao deletar apontamento: ")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RestoreAppointmentHandler desfaz a remoção de um apontamento se a semana dele ainda aceitar
+// alterações e ele não bater com outro apontamento (cronômetro ou sobreposição).
+func (h *AppointmentHandler) RestoreAppointmentHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := h.parseID(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+	restored, err := h.usecase.Restore(r.Context(), id)
+	if err != nil {
+		respondError(w, err, "Erro ao restaurar apontamento: ")
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, restored)
 }
 
-// AppontmentsRouterHandler decide qual handler chamar com base na URL.
+// ListAllAppointments godoc
+// @Summary      Lista todos os apontamentos
+// @Description  Visão admin com contrato e consultor. Aceita paginação, ordenação e filtros (userId, contractId, approvalStatus, from/to).
+// @Tags         appointments
+// @Produce      json
+// @Param        page     query int    false "Página (começa em 1)"
+// @Param        pageSize query int    false "Itens por página (padrão 50, máx. 200)"
+// @Param        after    query string false "Cursor (X-Next-Cursor da página anterior)"
+// @Param        sort     query string false "Ex.: -startTime,id"
+// @Param        userId   query int    false "Filtra por consultor"
+// @Param        contractId query int  false "Filtra por contrato"
+// @Param        approvalStatus query string false "draft, submitted, approved ou rejected"
+// @Param        from     query string false "Início do período (startTime)"
+// @Param        to       query string false "Fim do período (startTime)"
+// @Param        format   query string false "Exporta como csv ou xlsx (também via header Accept)"
+// @Param        locale   query string false "Idioma da exportação: en (padrão) ou pt-BR"
+// @Success      200  {array}  models.Appointment
+// @Router       /api/appointments [get]
 func (h *AppointmentHandler) ListAllAppointmentsWithDetails(w http.ResponseWriter, r *http.Request) {
-	appointments, err := h.repo.GetAllWithContract()
+	h.listWithDetails(w, r, nil)
+}
+
+// listWithDetails lista apontamentos com a linguagem de listagem comum,
+// somando os filtros fixos da rota (ex.: contractId da URL).
+func (h *AppointmentHandler) listWithDetails(w http.ResponseWriter, r *http.Request, fixed map[string]string) {
+	query, err := parseListQuery(r)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		respondError(w, err, "")
 		return
 	}
-	utils.RespondWithJSON(w, http.StatusOK, appointments)
+	for field, value := range fixed {
+		query.Filters[field] = value
+	}
+	h.respondWithDetails(w, r, query)
+}
+
+// respondWithDetails devolve a listagem já montada como JSON paginado ou, com ?format=, como planilha.
+func (h *AppointmentHandler) respondWithDetails(w http.ResponseWriter, r *http.Request, query repository.ListQuery) {
+	if format := utils.ExportFormatFromRequest(r); format != "" {
+		h.exportWithDetails(w, r, format, query)
+		return
+	}
+
+	page, err := h.repo.GetAllWithContract(r.Context(), query)
+	if err != nil {
+		respondError(w, err, "Erro ao buscar apontamentos: ")
+		return
+	}
+	respondWithPage(w, r, page, page.Items)
+}
+
+// appointmentExportColumns são as colunas da planilha de apontamentos.
+var appointmentExportColumns = []utils.ExportColumn{
+	{Header: "ID", HeaderPT: "ID", Kind: utils.CellInteger},
+	{Header: "Date", HeaderPT: "Data", Kind: utils.CellDate},
+	{Header: "Start", HeaderPT: "Início", Kind: utils.CellDateTime},
+	{Header: "End", HeaderPT: "Fim", Kind: utils.CellDateTime},
+	{Header: "Consultant", HeaderPT: "Consultor"},
+	{Header: "Contract", HeaderPT: "Contrato"},
+	{Header: "Description", HeaderPT: "Descrição"},
+	{Header: "Hours", HeaderPT: "Horas", Kind: utils.CellNumber},
+}
+
+// exportWithDetails devolve os apontamentos filtrados como CSV/XLSX, sem paginação.
+// As linhas são escritas conforme saem do banco, sem carregar tudo em memória.
+func (h *AppointmentHandler) exportWithDetails(w http.ResponseWriter, r *http.Request, format string, query repository.ListQuery) {
+	export := newTableExport(w, r, format, "apontamentos", appointmentExportColumns)
+	err := h.repo.EachWithContract(r.Context(), query, func(a *models.Appointment) error {
+		return export.WriteRow(a.ID, a.StartTime, a.StartTime, a.EndTime, a.UserName, a.ContractTitle, a.Description, a.TotalHours)
+	})
+	export.Finish(err, func(err error) {
+		respondError(w, err, "Erro ao exportar apontamentos: ")
+	})
 }
 
 // MÉTODOS ESPECÍFICOS - Apontar para o router
 
 // ListByContract godoc
 // @Summary      Lista apontamentos de um contrato
-// @Description  Retorna todos os apontamentos vinculados a um ID de contrato específico
+// @Description  Retorna os apontamentos vinculados a um ID de contrato específico (aceita paginação, ordenação e filtros)
 // @Tags         contracts
 // @Accept       json
 // @Produce      json
 // @Param        contractID path int true "ID do Contrato"
+// @Param        format   query string false "Exporta como csv ou xlsx (também via header Accept)"
+// @Param        locale   query string false "Idioma da exportação: en (padrão) ou pt-BR"
 // @Success      200  {array}  models.Appointment
 // @Router       /api/contracts/{contractID}/appointments [get]
 // 2. Listar por CONTRATO (Visão Detalhada do Contrato)
@@ -99,21 +243,18 @@ func (h *AppointmentHandler) ListAppointmentsByContract(w http.ResponseWriter, r
 		return
 	}
 
-	appointments, err := h.repo.GetByContractID(contractID)
-	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
-		return
-	}
-	utils.RespondWithJSON(w, http.StatusOK, appointments)
+	h.listWithDetails(w, r, map[string]string{"contractId": strconv.FormatInt(contractID, 10)})
 }
 
 // ListAppointmentsByUser godoc
 // @Summary      Lista apontamentos de um usuário
-// @Description  Retorna o histórico de horas de um consultor específico
+// @Description  Retorna o histórico de horas de um consultor específico (aceita paginação, ordenação e filtros)
 // @Tags         users
 // @Accept       json
 // @Produce      json
 // @Param        userID path int true "ID do Usuário"
+// @Param        format   query string false "Exporta como csv ou xlsx (também via header Accept)"
+// @Param        locale   query string false "Idioma da exportação: en (padrão) ou pt-BR"
 // @Success      200  {array}  models.Appointment
 // @Failure      400  {string} string "ID inválido"
 // @Router       /api/users/{userID}/appointments [get]
@@ -126,16 +267,142 @@ func (h *AppointmentHandler) ListAppointmentsByUser(w http.ResponseWriter, r *ht
 		return
 	}
 
-	appointments, err := h.repo.GetByUserID(userID)
+	h.listWithDetails(w, r, map[string]string{"userId": strconv.FormatInt(userID, 10)})
+}
+
+// ListAppointmentsByTicket godoc
+// @Summary      Lista apontamentos de um chamado
+// @Description  Retorna as horas lançadas em um chamado (aceita paginação, ordenação e filtros)
+// @Tags         tickets
+// @Produce      json
+// @Param        ticketID path int true "ID do Chamado"
+// @Param        format   query string false "Exporta como csv ou xlsx (também via header Accept)"
+// @Param        locale   query string false "Idioma da exportação: en (padrão) ou pt-BR"
+// @Success      200  {array}  models.Appointment
+// @Router       /api/tickets/{ticketID}/appointments [get]
+func (h *AppointmentHandler) ListAppointmentsByTicket(w http.ResponseWriter, r *http.Request) {
+	ticketID, err := strconv.ParseInt(chi.URLParam(r, "ticketID"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID do chamado inválido")
+		return
+	}
+	h.listWithDetails(w, r, map[string]string{"ticketId": strconv.FormatInt(ticketID, 10)})
+}
+
+// ListAppointmentsByTimesheet godoc
+// @Summary      Lista apontamentos de uma folha de horas
+// @Description  Retorna os apontamentos do consultor na semana da folha (aceita paginação, ordenação e filtros)
+// @Tags         timesheets
+// @Produce      json
+// @Param        timesheetID path int true "ID da Folha de Horas"
+// @Param        format   query string false "Exporta como csv ou xlsx (também via header Accept)"
+// @Param        locale   query string false "Idioma da exportação: en (padrão) ou pt-BR"
+// @Success      200  {array}  models.Appointment
+// @Failure      404  {string} string "Folha de horas não encontrada"
+// @Router       /api/timesheets/{timesheetID}/appointments [get]
+func (h *AppointmentHandler) ListAppointmentsByTimesheet(w http.ResponseWriter, r *http.Request) {
+	timesheetID, err := strconv.ParseInt(chi.URLParam(r, "timesheetID"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID da folha de horas inválido")
+		return
+	}
+	timesheets, err := h.timesheetRepo.Get(r.Context(), &timesheetID)
+	if err != nil {
+		respondError(w, err, "Erro ao buscar folha de horas: ")
+		return
+	}
+	user := auth.UserFromContext(r.Context())
+	if len(timesheets) == 0 || !ownTimesheet(user, timesheets[0]) {
+		utils.RespondWithError(w, http.StatusNotFound, "Folha de horas não encontrada")
+		return
+	}
+	timesheet := timesheets[0]
+
+	query, err := parseListQuery(r)
+	if err != nil {
+		respondError(w, err, "")
+		return
+	}
+	// A semana inteira, de segunda a domingo (data pura em "to" inclui o dia)
+	query.Filters["userId"] = strconv.FormatInt(timesheet.UserID, 10)
+	query.From = timesheet.WeekStart.Format(time.DateOnly)
+	query.To = timesheet.WeekStart.AddDate(0, 0, 6).Format(time.DateOnly)
+	h.respondWithDetails(w, r, query)
+}
+
+// StartTimer godoc
+// @Summary      Inicia o cronômetro
+// @Description  Para o apontamento em andamento do usuário (se houver) e inicia um novo no mesmo instante.
+// @Tags         appointments
+// @Accept       json
+// @Produce      json
+// @Param        timer body models.StartTimerRequest true "Contrato e descrição (userId opcional, só admin)"
+// @Success      201  {object}  models.StartTimerResponse
+// @Failure      400  {string}  string "Erro de validação"
+// @Failure      409  {string}  string "Semana enviada/aprovada"
+// @Router       /api/appointments/start [post]
+func (h *AppointmentHandler) StartTimer(w http.ResponseWriter, r *http.Request) {
+	var req models.StartTimerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "JSON inválido")
+		return
+	}
+	started, err := h.usecase.StartTimer(r.Context(), req)
+	if err != nil {
+		respondError(w, err, "Erro ao iniciar cronômetro: ")
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusCreated, started)
+}
+
+// StopTimer godoc
+// @Summary      Para o cronômetro
+// @Description  Finaliza um apontamento em andamento, gravando o horário atual como fim.
+// @Tags         appointments
+// @Produce      json
+// @Param        id   path      int  true  "ID do Apontamento"
+// @Success      200  {object}  models.Appointment
+// @Failure      404  {string}  string "Apontamento não encontrado"
+// @Failure      409  {string}  string "Apontamento já finalizado"
+// @Router       /api/appointments/{id}/stop [post]
+func (h *AppointmentHandler) StopTimer(w http.ResponseWriter, r *http.Request) {
+	id, err := h.parseID(r)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "Erro ao buscar histórico: "+err.Error())
+		utils.RespondWithError(w, http.StatusBadRequest, "ID inválido")
 		return
 	}
+	stopped, err := h.usecase.StopTimer(r.Context(), id)
+	if err != nil {
+		respondError(w, err, "Erro ao parar cronômetro: ")
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, stopped)
+}
 
-	if appointments == nil {
-		utils.RespondWithJSON(w, http.StatusOK, []*models.Appointment{})
+// GetRunningByUser godoc
+// @Summary      Apontamento em andamento do usuário
+// @Description  Retorna o cronômetro rodando do consultor, ou 204 se não houver nenhum.
+// @Tags         users
+// @Produce      json
+// @Param        userID path int true "ID do Usuário"
+// @Success      200  {object}  models.Appointment
+// @Success      204
+// @Router       /api/users/{userID}/appointments/running [get]
+func (h *AppointmentHandler) GetRunningByUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID do usuário inválido")
 		return
 	}
 
-	utils.RespondWithJSON(w, http.StatusOK, appointments)
+	running, err := h.repo.GetRunningByUserID(r.Context(), userID)
+	if err != nil {
+		respondError(w, err, "Erro ao buscar apontamento em andamento: ")
+		return
+	}
+	if running == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, running)
 }
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+
+	"nexus/internal/audit"
+	"nexus/internal/repository"
+)
+
+// AuditHandler expõe a trilha de auditoria e a deixa disponível para os outros handlers.
+type AuditHandler struct {
+	repo repository.AuditRepository
+}
+
+// NewAuditHandler cria o handler de auditoria.
+func NewAuditHandler(repo repository.AuditRepository) *AuditHandler {
+	return &AuditHandler{repo: repo}
+}
+
+// Middleware coloca o repositório de auditoria no contexto da requisição, para que as gravações
+// feitas pelos handlers e casos de uso (audit.Record) sejam registradas.
+func (h *AuditHandler) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(audit.WithRepository(r.Context(), h.repo)))
+	})
+}
+
+// ListAudit godoc
+// @Summary      Trilha de auditoria
+// @Description  Quem criou, alterou ou removeu cada registro, quando e o que mudou (changes: {"campo": {"old": ..., "new": ...}}). Mais recentes primeiro; aceita paginação e filtros.
+// @Tags         audit
+// @Produce      json
+// @Param        entity    query string false "Nome da tabela (ex.: appointments)"
+// @Param        entityId  query int    false "ID do registro"
+// @Param        actorId   query int    false "Filtra por autor"
+// @Param        operation query string false "create, update ou delete"
+// @Param        from      query string false "Data inicial (AAAA-MM-DD)"
+// @Param        to        query string false "Data final, inclusiva"
+// @Success      200  {array}  models.AuditEntry
+// @Router       /api/audit [get]
+func (h *AuditHandler) ListAudit(w http.ResponseWriter, r *http.Request) {
+	query, err := parseListQuery(r)
+	if err != nil {
+		respondError(w, err, "")
+		return
+	}
+	page, err := h.repo.List(r.Context(), query)
+	if err != nil {
+		respondError(w, err, "Erro ao buscar auditoria: ")
+		return
+	}
+	respondWithPage(w, r, page, page.Items)
+}
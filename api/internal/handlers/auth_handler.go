@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"nexus/internal/auth"
+	"nexus/internal/models"
+	"nexus/internal/repository"
+	"nexus/internal/utils"
+)
+
+// AuthHandler lida com login, refresh e logout.
+type AuthHandler struct {
+	userRepo    repository.UserRepository
+	refreshRepo repository.RefreshTokenRepository
+	tokens      *auth.TokenManager
+}
+
+// NewAuthHandler cria um novo handler de autenticação.
+func NewAuthHandler(userRepo repository.UserRepository, refreshRepo repository.RefreshTokenRepository, tokens *auth.TokenManager) *AuthHandler {
+	return &AuthHandler{
+		userRepo:    userRepo,
+		refreshRepo: refreshRepo,
+		tokens:      tokens,
+	}
+}
+
+// Login godoc
+// @Summary      Autentica um usuário
+// @Description  Valida e-mail e senha e devolve um access token e um refresh token
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        credentials body models.LoginRequest true "E-mail e senha"
+// @Success      200  {object}  models.TokenResponse
+// @Failure      401  {string}  string "Credenciais inválidas"
+// @Router       /api/auth/login [post]
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var req models.LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Corpo da requisição inválido")
+		return
+	}
+	if req.Email == "" || req.Password == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, "E-mail e senha são obrigatórios")
+		return
+	}
+
+	user, err := h.userRepo.GetByEmail(r.Context(), req.Email)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Erro ao buscar usuário")
+		return
+	}
+	// Mesma mensagem para e-mail inexistente e senha errada (não revela quais e-mails existem)
+	if user == nil || !auth.CheckPassword(user.PasswordHash, req.Password) {
+		utils.RespondWithError(w, http.StatusUnauthorized, "E-mail ou senha inválidos")
+		return
+	}
+
+	h.respondWithTokens(w, r, user)
+}
+
+// Refresh godoc
+// @Summary      Renova o access token
+// @Description  Troca um refresh token válido por um novo par de tokens. O refresh token usado é revogado.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        token body models.RefreshRequest true "Refresh token"
+// @Success      200  {object}  models.TokenResponse
+// @Failure      401  {string}  string "Refresh token inválido"
+// @Router       /api/auth/refresh [post]
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req models.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, "Refresh token não informado")
+		return
+	}
+
+	claims, err := h.tokens.Parse(req.RefreshToken, auth.TokenTypeRefresh)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Refresh token inválido ou expirado")
+		return
+	}
+
+	stored, err := h.refreshRepo.GetByID(r.Context(), claims.ID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Erro ao validar refresh token")
+		return
+	}
+	if stored == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Refresh token inválido ou expirado")
+		return
+	}
+	if stored.RevokedAt != nil {
+		// Reuso de token já trocado: provável vazamento, derruba todas as sessões do usuário
+		if _, err := h.refreshRepo.RevokeAllForUser(r.Context(), stored.UserID); err != nil {
+			log.Printf("Erro ao revogar sessões do usuário %d: %v", stored.UserID, err)
+		}
+		utils.RespondWithError(w, http.StatusUnauthorized, "Refresh token revogado")
+		return
+	}
+
+	users, err := h.userRepo.Get(r.Context(), &stored.UserID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Erro ao buscar usuário")
+		return
+	}
+	if len(users) == 0 {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Usuário não encontrado")
+		return
+	}
+
+	// Rotação: o refresh token usado não vale mais
+	rows, err := h.refreshRepo.Revoke(r.Context(), stored.ID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Erro ao revogar refresh token")
+		return
+	}
+	if rows == 0 {
+		// Outra requisição trocou o mesmo token ao mesmo tempo
+		utils.RespondWithError(w, http.StatusUnauthorized, "Refresh token revogado")
+		return
+	}
+
+	h.respondWithTokens(w, r, users[0])
+}
+
+// Logout godoc
+// @Summary      Encerra a sessão
+// @Description  Revoga o refresh token informado. O access token expira sozinho.
+// @Tags         auth
+// @Accept       json
+// @Param        token body models.RefreshRequest true "Refresh token"
+// @Success      204
+// @Failure      400  {string}  string "Refresh token não informado"
+// @Router       /api/auth/logout [post]
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	var req models.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, "Refresh token não informado")
+		return
+	}
+
+	// Logout é idempotente: token inválido ou já revogado também responde 204
+	if claims, err := h.tokens.Parse(req.RefreshToken, auth.TokenTypeRefresh); err == nil {
+		if _, err := h.refreshRepo.Revoke(r.Context(), claims.ID); err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Erro ao revogar refresh token")
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AuthHandler) respondWithTokens(w http.ResponseWriter, r *http.Request, user *models.User) {
+	accessToken, err := h.tokens.GenerateAccessToken(user)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Erro ao gerar token de acesso")
+		return
+	}
+	refreshToken, stored, err := h.tokens.GenerateRefreshToken(user)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Erro ao gerar refresh token")
+		return
+	}
+	if err := h.refreshRepo.Save(r.Context(), stored); err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Erro ao registrar sessão")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, models.TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(h.tokens.AccessTTL() / time.Second),
+		User:         user,
+	})
+}
@@ -1,21 +1,50 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"net/url"
 	"reflect"
 	"strconv"
 	"strings"
 
+	"nexus/internal/audit"
+	"nexus/internal/auth"
+	"nexus/internal/domain"
 	"nexus/internal/models"
 	"nexus/internal/repository"
 	"nexus/internal/utils"
+	"nexus/internal/validation"
 
 	"github.com/go-chi/chi/v5"
 )
 
+// AccessPolicy decide, registro a registro, se o usuário logado pode acessá-lo.
+// Política nil significa sem restrição além das permissões da rota.
+type AccessPolicy[T models.Model] func(user *models.User, model T) bool
+
+// ReadFilter traduz a ReadPolicy em filtros de listagem (nome JSON do campo → valor), aplicados
+// no banco para que total, páginas e cursor só contem o que o usuário pode ver.
+// Devolve nil quando o usuário enxerga tudo.
+type ReadFilter func(user *models.User) map[string]string
+
+// allows aplica a política (nil libera tudo).
+func (p AccessPolicy[T]) allows(user *models.User, model T) bool {
+	return p == nil || p(user, model)
+}
+
 // BaseHandler é um handler genérico para operações CRUD.
 // Usa campos de função para permitir a sobrescrita de comportamento.
+// ReadPolicy vale para leituras (lista e por ID) e WritePolicy para PUT/DELETE; nas listagens
+// quem restringe é o ReadFilter, no SQL, e a ReadPolicy fica como segunda conferência.
+// As restrições por papel ficam nas rotas (auth.RequireRole). RestoreHandler e PurgeHandler
+// só servem a modelos com remoção lógica (deleted_at) e ficam em rotas de admin.
+// Create e update conferem o corpo pelas tags `validate` do modelo (pacote validation);
+// Validate, quando definido, soma as regras que não cabem em tag (ex.: datas cruzadas).
 type BaseHandler[T models.Model] struct {
 	repo           repository.Repository[T]
 	routeName      string
@@ -24,6 +53,12 @@ type BaseHandler[T models.Model] struct {
 	GetByIDHandler http.HandlerFunc
 	UpdateHandler  http.HandlerFunc
 	DeleteHandler  http.HandlerFunc
+	RestoreHandler http.HandlerFunc
+	PurgeHandler   http.HandlerFunc
+	ReadPolicy     AccessPolicy[T]
+	ReadFilter     ReadFilter
+	WritePolicy    AccessPolicy[T]
+	Validate       func(T) error
 }
 
 // NewBaseHandler cria uma nova instância de BaseHandler com handlers padrão.
@@ -38,6 +73,8 @@ func NewBaseHandler[T models.Model](repo repository.Repository[T], routeName str
 	h.GetByIDHandler = h.getByIDHandlerDefault
 	h.UpdateHandler = h.updateHandlerDefault
 	h.DeleteHandler = h.deleteHandlerDefault
+	h.RestoreHandler = h.restoreHandlerDefault
+	h.PurgeHandler = h.purgeHandlerDefault
 	return h
 }
 
@@ -73,28 +110,70 @@ func (h *BaseHandler[T]) newModel() T {
 	return reflect.New(reflect.TypeOf(t).Elem()).Interface().(T)
 }
 
-// Implementações padrão dos handlers
-func (h *BaseHandler[T]) createHandlerDefault(w http.ResponseWriter, r *http.Request) {
+// decode lê o corpo da requisição e valida o modelo; em caso de erro já responde
+// (400 com todos os erros de campo) e devolve false.
+func (h *BaseHandler[T]) decode(w http.ResponseWriter, r *http.Request) (T, bool) {
 	model := h.newModel()
 	if err := json.NewDecoder(r.Body).Decode(&model); err != nil {
 		utils.RespondWithError(w, http.StatusBadRequest, "Corpo da requisição inválido")
+		return model, false
+	}
+	if err := h.validateModel(model); err != nil {
+		respondError(w, err, "")
+		return model, false
+	}
+	return model, true
+}
+
+// validateModel junta os erros das tags com os do hook Validate numa única resposta.
+func (h *BaseHandler[T]) validateModel(model T) error {
+	fields := validation.Struct(model)
+	if h.Validate != nil {
+		if err := h.Validate(model); err != nil {
+			var derr *domain.Error
+			switch {
+			case errors.As(err, &derr) && len(derr.Fields) > 0:
+				fields = append(fields, derr.Fields...)
+			case errors.As(err, &derr):
+				fields = append(fields, domain.Field("", derr.Code, derr.Message))
+			default:
+				fields = append(fields, domain.Field("", domain.CodeInvalidValue, err.Error()))
+			}
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return domain.Invalid(fields...)
+}
+
+// Implementações padrão dos handlers
+func (h *BaseHandler[T]) createHandlerDefault(w http.ResponseWriter, r *http.Request) {
+	model, ok := h.decode(w, r)
+	if !ok {
 		return
 	}
-	savedModel, err := h.repo.Save(model)
+	savedModel, err := h.save(r, model)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "Erro ao criar "+h.routeName+": "+err.Error())
+		respondError(w, err, "Erro ao criar "+h.routeName+": ")
 		return
 	}
 	utils.RespondWithJSON(w, http.StatusCreated, savedModel)
 }
 
-func (h *BaseHandler[T]) getAllHandlerDefault(w http.ResponseWriter, _ *http.Request) {
-	models, err := h.repo.Get(nil)
+func (h *BaseHandler[T]) getAllHandlerDefault(w http.ResponseWriter, r *http.Request) {
+	query, err := parseListQuery(r)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "Erro ao obter "+h.routeName+": "+err.Error())
+		respondError(w, err, "")
 		return
 	}
-	utils.RespondWithJSON(w, http.StatusOK, models)
+	h.restrictToReadable(r, &query)
+	page, err := h.repo.List(r.Context(), query)
+	if err != nil {
+		respondError(w, err, "Erro ao obter "+h.routeName+": ")
+		return
+	}
+	respondWithPage(w, r, page, h.filterReadable(r, page.Items))
 }
 
 func (h *BaseHandler[T]) getByIDHandlerDefault(w http.ResponseWriter, r *http.Request) {
@@ -103,12 +182,23 @@ func (h *BaseHandler[T]) getByIDHandlerDefault(w http.ResponseWriter, r *http.Re
 		utils.RespondWithError(w, http.StatusBadRequest, "ID inválido")
 		return
 	}
-	models, err := h.repo.Get(&id)
+	includeDeleted, err := parseIncludeDeleted(r)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "Erro ao buscar "+h.routeName+": "+err.Error())
+		respondError(w, err, "")
 		return
 	}
-	if len(models) == 0 {
+	var models []T
+	if includeDeleted {
+		models, err = h.getIncludingDeleted(r.Context(), id)
+	} else {
+		models, err = h.repo.Get(r.Context(), &id)
+	}
+	if err != nil {
+		respondError(w, err, "Erro ao buscar "+h.routeName+": ")
+		return
+	}
+	// Registro fora da política de leitura é tratado como inexistente
+	if len(models) == 0 || !h.ReadPolicy.allows(auth.UserFromContext(r.Context()), models[0]) {
 		utils.RespondWithError(w, http.StatusNotFound, h.routeName+" não encontrado")
 		return
 	}
@@ -121,15 +211,17 @@ func (h *BaseHandler[T]) updateHandlerDefault(w http.ResponseWriter, r *http.Req
 		utils.RespondWithError(w, http.StatusBadRequest, "ID inválido")
 		return
 	}
-	model := h.newModel()
-	if err := json.NewDecoder(r.Body).Decode(&model); err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, "Corpo da requisição inválido")
+	model, ok := h.decode(w, r)
+	if !ok {
 		return
 	}
 	model.SetID(id)
-	rowsAffected, err := h.repo.Update(model)
+	if !h.authorizeWrite(w, r, id, model) {
+		return
+	}
+	rowsAffected, err := h.update(r, model)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "Erro ao atualizar "+h.routeName+": "+err.Error())
+		respondError(w, err, "Erro ao atualizar "+h.routeName+": ")
 		return
 	}
 	if rowsAffected == 0 {
@@ -145,9 +237,12 @@ func (h *BaseHandler[T]) deleteHandlerDefault(w http.ResponseWriter, r *http.Req
 		utils.RespondWithError(w, http.StatusBadRequest, "ID inválido")
 		return
 	}
-	rowsAffected, err := h.repo.Delete(id)
+	if !h.authorizeWrite(w, r, id) {
+		return
+	}
+	rowsAffected, err := h.delete(r, id)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "Erro ao deletar "+h.routeName+": "+err.Error())
+		respondError(w, err, "Erro ao deletar "+h.routeName+": ")
 		return
 	}
 	if rowsAffected == 0 {
@@ -157,6 +252,235 @@ func (h *BaseHandler[T]) deleteHandlerDefault(w http.ResponseWriter, r *http.Req
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// restoreHandlerDefault desfaz a remoção lógica. 404 se o registro não existir ou não estiver removido.
+func (h *BaseHandler[T]) restoreHandlerDefault(w http.ResponseWriter, r *http.Request) {
+	id, err := h.parseID(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+	h.restoreAndRespond(w, r, id)
+}
+
+// restoreAndRespond restaura o registro e devolve como ficou. A restauração pode bater numa
+// constraint que os registros ativos já ocupam: respondError traduz em 409.
+func (h *BaseHandler[T]) restoreAndRespond(w http.ResponseWriter, r *http.Request, id int64) {
+	rowsAffected, err := h.restore(r, id)
+	if err != nil {
+		respondError(w, err, "Erro ao restaurar "+h.routeName+": ")
+		return
+	}
+	if rowsAffected == 0 {
+		utils.RespondWithError(w, http.StatusNotFound, h.routeName+" removido não encontrado")
+		return
+	}
+	restored, err := h.repo.Get(r.Context(), &id)
+	if err != nil || len(restored) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, restored[0])
+}
+
+// purgeHandlerDefault apaga de vez um registro já removido logicamente (DELETE antes).
+// 409 se ainda houver registros ligados a ele (ex.: apontamentos de um contrato).
+func (h *BaseHandler[T]) purgeHandlerDefault(w http.ResponseWriter, r *http.Request) {
+	id, err := h.parseID(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+	rowsAffected, err := h.purge(r, id)
+	if err != nil {
+		if domain.HasCode(domain.FromPostgresDelete(err), domain.CodeInUse) {
+			utils.RespondWithError(w, http.StatusConflict, "Existem registros ligados a este "+h.routeName+": apague-os antes")
+			return
+		}
+		respondError(w, err, "Erro ao apagar "+h.routeName+": ")
+		return
+	}
+	if rowsAffected == 0 {
+		utils.RespondWithError(w, http.StatusNotFound, h.routeName+" removido não encontrado (só registros já removidos podem ser apagados de vez)")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// respondError responde o erro como problem+json. *domain.Error (inclusive as violações de
+// constraint do PostgreSQL, traduzidas aqui) sai com o status do Kind, o código e os campos;
+// listagem inválida é 400 e modelo sem remoção lógica, 405. O resto é 500 só com prefix:
+// a mensagem do banco vai para o log, não para o cliente.
+func respondError(w http.ResponseWriter, err error, prefix string) {
+	err = domain.FromPostgres(err)
+	if e, ok := domain.As(err); ok {
+		if e.Err != nil {
+			log.Printf("%s%v", prefix, err)
+		}
+		utils.RespondWithProblem(w, utils.Problem{Status: statusOf(e.Kind), Code: e.Code, Detail: e.Message, Errors: e.Fields})
+		return
+	}
+	switch {
+	case errors.Is(err, repository.ErrInvalidListQuery):
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, repository.ErrNotSoftDeletable):
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, err.Error())
+	default:
+		log.Printf("%s%v", prefix, err)
+		utils.RespondWithError(w, http.StatusInternalServerError, strings.TrimSuffix(prefix, ": "))
+	}
+}
+
+// statusOf é o status HTTP de cada tipo de erro de domínio.
+func statusOf(kind domain.Kind) int {
+	switch kind {
+	case domain.KindValidation:
+		return http.StatusBadRequest
+	case domain.KindNotFound:
+		return http.StatusNotFound
+	case domain.KindConflict:
+		return http.StatusConflict
+	case domain.KindForbidden:
+		return http.StatusForbidden
+	case domain.KindUnavailable:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// save, update e delete gravam pelo repositório e registram a operação na trilha de auditoria
+// com o usuário logado. Handlers customizados gravam por eles ou pelo caso de uso, não direto pelo repo.
+func (h *BaseHandler[T]) save(r *http.Request, model T) (T, error) {
+	saved, err := h.repo.Save(r.Context(), model)
+	if err == nil {
+		audit.Record(r.Context(), h.repo.GetTableName(), models.AuditCreate, saved.GetID(), nil, saved)
+	}
+	return saved, err
+}
+
+func (h *BaseHandler[T]) update(r *http.Request, model T) (int64, error) {
+	before := h.current(r.Context(), model.GetID())
+	rowsAffected, err := h.repo.Update(r.Context(), model)
+	if err == nil && rowsAffected > 0 {
+		audit.Record(r.Context(), h.repo.GetTableName(), models.AuditUpdate, model.GetID(), before, model)
+	}
+	return rowsAffected, err
+}
+
+func (h *BaseHandler[T]) delete(r *http.Request, id int64) (int64, error) {
+	before := h.current(r.Context(), id)
+	rowsAffected, err := h.repo.Delete(r.Context(), id)
+	if err == nil && rowsAffected > 0 {
+		audit.Record(r.Context(), h.repo.GetTableName(), models.AuditDelete, id, before, nil)
+	}
+	return rowsAffected, err
+}
+
+// restore e purge registram a operação com o estado do registro removido (deletedAt preenchido).
+func (h *BaseHandler[T]) restore(r *http.Request, id int64) (int64, error) {
+	before := h.currentIncludingDeleted(r.Context(), id)
+	rowsAffected, err := h.repo.Restore(r.Context(), id)
+	if err == nil && rowsAffected > 0 {
+		audit.Record(r.Context(), h.repo.GetTableName(), models.AuditRestore, id, before, h.current(r.Context(), id))
+	}
+	return rowsAffected, err
+}
+
+func (h *BaseHandler[T]) purge(r *http.Request, id int64) (int64, error) {
+	before := h.currentIncludingDeleted(r.Context(), id)
+	rowsAffected, err := h.repo.Purge(r.Context(), id)
+	if err == nil && rowsAffected > 0 {
+		audit.Record(r.Context(), h.repo.GetTableName(), models.AuditPurge, id, before, nil)
+	}
+	return rowsAffected, err
+}
+
+// getIncludingDeleted busca o registro pelo ID mesmo se ele tiver sido removido logicamente.
+func (h *BaseHandler[T]) getIncludingDeleted(ctx context.Context, id int64) ([]T, error) {
+	page, err := h.repo.List(ctx, repository.ListQuery{
+		Filters:        map[string]string{"id": strconv.FormatInt(id, 10)},
+		IncludeDeleted: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+func (h *BaseHandler[T]) currentIncludingDeleted(ctx context.Context, id int64) any {
+	existing, err := h.getIncludingDeleted(ctx, id)
+	if err != nil || len(existing) == 0 {
+		return nil
+	}
+	return existing[0]
+}
+
+// current busca o registro gravado para a auditoria (nil se não existir ou falhar).
+func (h *BaseHandler[T]) current(ctx context.Context, id int64) any {
+	existing, err := h.repo.Get(ctx, &id)
+	if err != nil || len(existing) == 0 {
+		return nil
+	}
+	return existing[0]
+}
+
+// restrictToReadable soma à listagem os filtros do ReadFilter para o usuário logado.
+// Eles prevalecem sobre os filtros da requisição: ?userId=<outro> não fura a política.
+func (h *BaseHandler[T]) restrictToReadable(r *http.Request, query *repository.ListQuery) {
+	if h.ReadFilter == nil {
+		return
+	}
+	for field, value := range h.ReadFilter(auth.UserFromContext(r.Context())) {
+		query.Filters[field] = value
+	}
+}
+
+// filterReadable remove da lista os registros que o usuário logado não pode ler. É a segunda
+// conferência: a restrição que conta para a paginação é a do ReadFilter.
+// Nunca devolve nil, para o JSON sair como [] e não null.
+func (h *BaseHandler[T]) filterReadable(r *http.Request, list []T) []T {
+	if h.ReadPolicy == nil && list != nil {
+		return list
+	}
+	user := auth.UserFromContext(r.Context())
+	readable := make([]T, 0, len(list))
+	for _, model := range list {
+		if h.ReadPolicy(user, model) {
+			readable = append(readable, model)
+		}
+	}
+	return readable
+}
+
+// authorizeWrite aplica a WritePolicy ao registro atual (e aos novos dados, se houver).
+// Já responde ao cliente e devolve false quando o acesso é negado.
+func (h *BaseHandler[T]) authorizeWrite(w http.ResponseWriter, r *http.Request, id int64, incoming ...T) bool {
+	if h.ReadPolicy == nil && h.WritePolicy == nil {
+		return true
+	}
+	user := auth.UserFromContext(r.Context())
+
+	existing, err := h.repo.Get(r.Context(), &id)
+	if err != nil {
+		respondError(w, err, "Erro ao buscar "+h.routeName+": ")
+		return false
+	}
+	if len(existing) == 0 || !h.ReadPolicy.allows(user, existing[0]) {
+		utils.RespondWithError(w, http.StatusNotFound, h.routeName+" não encontrado")
+		return false
+	}
+	if !h.WritePolicy.allows(user, existing[0]) {
+		utils.RespondWithError(w, http.StatusForbidden, "Você não tem permissão para alterar este registro")
+		return false
+	}
+	for _, model := range incoming {
+		if !h.WritePolicy.allows(user, model) {
+			utils.RespondWithError(w, http.StatusForbidden, "Você não tem permissão para alterar este registro")
+			return false
+		}
+	}
+	return true
+}
+
 func (h *BaseHandler[T]) parseID(r *http.Request) (int64, error) {
 	// 1. Tenta obter o ID via Chi Router (param "id") - Mais seguro e correto para sua estrutura
 	if idStr := chi.URLParam(r, "id"); idStr != "" {
@@ -174,3 +498,89 @@ func (h *BaseHandler[T]) parseID(r *http.Request) (int64, error) {
 	}
 	return 0, strconv.ErrSyntax
 }
+
+// listParams são os parâmetros de query string que não são filtros por campo.
+var listParams = map[string]bool{
+	"page": true, "pageSize": true, "after": true, "sort": true, "from": true, "to": true, "include": true,
+	"format": true, "locale": true, "includeDeleted": true,
+}
+
+// parseListQuery lê a linguagem de listagem comum a todas as rotas de lista (erros vão para
+// respondError: parâmetro inválido é 400, includeDeleted sem ser admin é 403):
+// ?page=&pageSize= ou ?after=<cursor>, ?sort=-startTime,id, ?from=&to=, ?<campo>=<valor>
+// e ?includeDeleted=true (só admin) para trazer também os registros removidos.
+func parseListQuery(r *http.Request) (repository.ListQuery, error) {
+	values := r.URL.Query()
+	query := repository.ListQuery{
+		After:   values.Get("after"),
+		From:    values.Get("from"),
+		To:      values.Get("to"),
+		Filters: make(map[string]string),
+	}
+
+	var err error
+	if query.IncludeDeleted, err = parseIncludeDeleted(r); err != nil {
+		return query, err
+	}
+	if v := values.Get("page"); v != "" {
+		if query.Page, err = strconv.Atoi(v); err != nil || query.Page < 1 {
+			return query, fmt.Errorf("%w: page inválido", repository.ErrInvalidListQuery)
+		}
+	}
+	if v := values.Get("pageSize"); v != "" {
+		if query.PageSize, err = strconv.Atoi(v); err != nil || query.PageSize < 1 {
+			return query, fmt.Errorf("%w: pageSize inválido", repository.ErrInvalidListQuery)
+		}
+	}
+	if v := values.Get("sort"); v != "" {
+		query.Sort = strings.Split(v, ",")
+	}
+
+	for key := range values {
+		if !listParams[key] {
+			query.Filters[key] = values.Get(key)
+		}
+	}
+	return query, nil
+}
+
+// parseIncludeDeleted lê ?includeDeleted=true, permitido só para admin (403 para os demais).
+func parseIncludeDeleted(r *http.Request) (bool, error) {
+	v := r.URL.Query().Get("includeDeleted")
+	if v == "" {
+		return false, nil
+	}
+	include, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("%w: includeDeleted inválido", repository.ErrInvalidListQuery)
+	}
+	if include && !auth.IsAdmin(auth.UserFromContext(r.Context())) {
+		return false, domain.Forbidden("include_deleted_admin_only", "includeDeleted é restrito a administradores")
+	}
+	return include, nil
+}
+
+// respondWithPage devolve os itens como array e a paginação nos headers:
+// X-Total-Count, X-Next-Cursor e Link (rel="next").
+func respondWithPage[T any](w http.ResponseWriter, r *http.Request, page *repository.Page[T], items []T) {
+	w.Header().Set("X-Total-Count", strconv.FormatInt(page.Total, 10))
+	if page.HasNext() {
+		next := url.Values{}
+		for key, v := range r.URL.Query() {
+			next[key] = v
+		}
+		// Na paginação por página o próximo link segue por página; senão, por cursor
+		if page.Page > 0 {
+			next.Set("page", strconv.Itoa(page.Page+1))
+		} else {
+			next.Set("after", page.NextCursor)
+		}
+		next.Set("pageSize", strconv.Itoa(page.PageSize))
+		w.Header().Set("X-Next-Cursor", page.NextCursor)
+		w.Header().Set("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, r.URL.Path, next.Encode()))
+	}
+	if items == nil {
+		items = []T{}
+	}
+	utils.RespondWithJSON(w, http.StatusOK, items)
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"nexus/internal/domain"
+	"nexus/internal/models"
+	"nexus/internal/repository"
+	"nexus/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// BillingRateHandler lida com os valores-hora dos contratos.
+type BillingRateHandler struct {
+	*BaseHandler[*models.BillingRate]
+}
+
+// NewBillingRateHandler cria o handler de valores-hora, validando create/update.
+func NewBillingRateHandler(repo repository.Repository[*models.BillingRate]) *BillingRateHandler {
+	handler := &BillingRateHandler{BaseHandler: NewBaseHandler(repo, "billing-rates")}
+	handler.Validate = validateBillingRate
+	handler.CreateHandler = handler.CreateRateHandler
+	handler.UpdateHandler = handler.UpdateRateHandler
+	return handler
+}
+
+// CreateRate godoc
+// @Summary      Cadastra um valor-hora
+// @Description  Sem userId é o valor padrão do contrato; com userId vale só para o consultor. Vigências do mesmo contrato/consultor não podem se sobrepor.
+// @Tags         billing-rates
+// @Accept       json
+// @Produce      json
+// @Param        rate body models.BillingRate true "Valor-hora (hourlyRate como string, ex.: \"150.00\")"
+// @Success      201  {object}  models.BillingRate
+// @Failure      400  {string}  string "Erro de validação"
+// @Failure      409  {string}  string "Vigência sobreposta"
+// @Router       /api/billing-rates [post]
+func (h *BillingRateHandler) CreateRateHandler(w http.ResponseWriter, r *http.Request) {
+	rate, ok := h.decode(w, r)
+	if !ok {
+		return
+	}
+	saved, err := h.save(r, rate)
+	if err != nil {
+		respondError(w, err, "Erro ao salvar valor-hora: ")
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusCreated, saved)
+}
+
+// UpdateRate godoc
+// @Summary      Atualiza um valor-hora
+// @Description  Para reajustes, prefira encerrar a vigência (validTo) e cadastrar um novo valor: alterar o valor muda o faturamento do período já lançado.
+// @Tags         billing-rates
+// @Accept       json
+// @Produce      json
+// @Param        id   path int                true "ID do Valor-hora"
+// @Param        rate body models.BillingRate true "Valor-hora"
+// @Success      200  {object}  models.BillingRate
+// @Failure      400  {string}  string "Erro de validação"
+// @Failure      404  {string}  string "Valor-hora não encontrado"
+// @Failure      409  {string}  string "Vigência sobreposta"
+// @Router       /api/billing-rates/{id} [put]
+func (h *BillingRateHandler) UpdateRateHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := h.parseID(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+	rate, ok := h.decode(w, r)
+	if !ok {
+		return
+	}
+	rate.SetID(id)
+	rowsAffected, err := h.update(r, rate)
+	if err != nil {
+		respondError(w, err, "Erro ao atualizar valor-hora: ")
+		return
+	}
+	if rowsAffected == 0 {
+		utils.RespondWithError(w, http.StatusNotFound, "Valor-hora não encontrado")
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, rate)
+}
+
+// ListRatesByContract godoc
+// @Summary      Valores-hora de um contrato
+// @Description  Valores padrão e por consultor, com vigência (aceita paginação, ordenação e filtros, ex.: userId)
+// @Tags         contracts
+// @Produce      json
+// @Param        contractID path int true "ID do Contrato"
+// @Success      200  {array}  models.BillingRate
+// @Router       /api/contracts/{contractID}/billing-rates [get]
+func (h *BillingRateHandler) ListRatesByContract(w http.ResponseWriter, r *http.Request) {
+	contractID, err := strconv.ParseInt(chi.URLParam(r, "contractID"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID do contrato inválido")
+		return
+	}
+	query, err := parseListQuery(r)
+	if err != nil {
+		respondError(w, err, "")
+		return
+	}
+	query.Filters["contractId"] = strconv.FormatInt(contractID, 10)
+	page, err := h.repo.List(r.Context(), query)
+	if err != nil {
+		respondError(w, err, "Erro ao buscar valores-hora: ")
+		return
+	}
+	respondWithPage(w, r, page, page.Items)
+}
+
+// validateBillingRate confere valor e vigência; contrato e início da vigência ficam nas tags.
+func validateBillingRate(rate *models.BillingRate) error {
+	var fields []domain.FieldError
+	if rate.HourlyRate.IsNegative() {
+		fields = append(fields, domain.Field("hourlyRate", domain.CodeInvalidValue, "O valor-hora não pode ser negativo"))
+	} else if !rate.HourlyRate.Equal(rate.HourlyRate.Round(2)) {
+		fields = append(fields, domain.Field("hourlyRate", domain.CodeInvalidValue, "O valor-hora aceita no máximo 2 casas decimais"))
+	}
+	if rate.ValidTo != nil && rate.ValidTo.Before(rate.ValidFrom) {
+		fields = append(fields, domain.Field("validTo", domain.CodeInvalidValue, "O fim da vigência não pode ser anterior ao início"))
+	}
+	if len(fields) > 0 {
+		return domain.Invalid(fields...)
+	}
+	return nil
+}
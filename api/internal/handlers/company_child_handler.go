@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"nexus/internal/models"
+	"nexus/internal/repository"
+	"nexus/internal/usecase"
+	"nexus/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// CompanyChildHandler lida com os cadastros de uma empresa, em /api/companies/{companyID}/...
+// (contatos e endereços de cobrança). A empresa vem sempre do caminho: registro de outra
+// empresa é tratado como inexistente.
+type CompanyChildHandler[T models.CompanyChild] struct {
+	*BaseHandler[T]
+	usecase usecase.CompanyChildUsecase[T]
+	noun    string // Nome do registro nas mensagens (ex.: "Contato")
+}
+
+// NewCompanyContactHandler cria o handler de /api/companies/{companyID}/contacts.
+func NewCompanyContactHandler(repo repository.Repository[*models.CompanyContact], contactUsecase usecase.CompanyChildUsecase[*models.CompanyContact]) *CompanyChildHandler[*models.CompanyContact] {
+	return newCompanyChildHandler(repo, contactUsecase, "contacts", "Contato")
+}
+
+// NewCompanyAddressHandler cria o handler de /api/companies/{companyID}/addresses.
+func NewCompanyAddressHandler(repo repository.Repository[*models.CompanyAddress], addressUsecase usecase.CompanyChildUsecase[*models.CompanyAddress]) *CompanyChildHandler[*models.CompanyAddress] {
+	return newCompanyChildHandler(repo, addressUsecase, "addresses", "Endereço")
+}
+
+func newCompanyChildHandler[T models.CompanyChild](repo repository.Repository[T], childUsecase usecase.CompanyChildUsecase[T], routeName, noun string) *CompanyChildHandler[T] {
+	handler := &CompanyChildHandler[T]{
+		BaseHandler: NewBaseHandler(repo, routeName),
+		usecase:     childUsecase,
+		noun:        noun,
+	}
+	handler.CreateHandler = handler.CreateChild
+	handler.UpdateHandler = handler.UpdateChild
+	handler.GetAllHandler = handler.ListChildren
+	handler.GetByIDHandler = handler.inCompany(handler.GetByIDHandler)
+	handler.DeleteHandler = handler.inCompany(handler.DeleteHandler)
+	handler.RestoreHandler = handler.inCompany(handler.RestoreHandler)
+	handler.PurgeHandler = handler.inCompany(handler.PurgeHandler)
+	return handler
+}
+
+// ListChildren lista os registros da empresa do caminho, o principal primeiro. Aceita os
+// parâmetros comuns de listagem (ex.: ?billing=true para os contatos do financeiro).
+func (h *CompanyChildHandler[T]) ListChildren(w http.ResponseWriter, r *http.Request) {
+	companyID, err := parseCompanyID(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID da empresa inválido")
+		return
+	}
+	query, err := parseListQuery(r)
+	if err != nil {
+		respondError(w, err, "")
+		return
+	}
+	query.Filters["companyId"] = strconv.FormatInt(companyID, 10)
+	if len(query.Sort) == 0 {
+		query.Sort = []string{"-primary", "id"}
+	}
+	page, err := h.repo.List(r.Context(), query)
+	if err != nil {
+		respondError(w, err, "Erro ao buscar "+h.routeName+": ")
+		return
+	}
+	respondWithPage(w, r, page, page.Items)
+}
+
+// CreateChild cadastra o registro na empresa do caminho (companyId do corpo é ignorado).
+// Marcado como principal, desmarca o principal anterior.
+func (h *CompanyChildHandler[T]) CreateChild(w http.ResponseWriter, r *http.Request) {
+	companyID, err := parseCompanyID(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID da empresa inválido")
+		return
+	}
+	child := h.newModel()
+	if err := json.NewDecoder(r.Body).Decode(&child); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Corpo da requisição inválido")
+		return
+	}
+	child.SetCompanyID(companyID)
+
+	saved, err := h.usecase.Create(r.Context(), child)
+	if err != nil {
+		respondError(w, err, "Erro ao salvar "+h.routeName+": ")
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusCreated, saved)
+}
+
+// UpdateChild atualiza o registro; ele não muda de empresa.
+func (h *CompanyChildHandler[T]) UpdateChild(w http.ResponseWriter, r *http.Request) {
+	companyID, err := parseCompanyID(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID da empresa inválido")
+		return
+	}
+	id, err := h.parseID(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+	child := h.newModel()
+	if err := json.NewDecoder(r.Body).Decode(&child); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Corpo da requisição inválido")
+		return
+	}
+	child.SetID(id)
+	child.SetCompanyID(companyID)
+
+	if err := h.usecase.Update(r.Context(), child); err != nil {
+		respondError(w, err, "Erro ao atualizar "+h.routeName+": ")
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, child)
+}
+
+// inCompany só deixa passar para next o registro {id} que é da empresa {companyID}
+// (inclusive os removidos, para restore e purge).
+func (h *CompanyChildHandler[T]) inCompany(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		companyID, err := parseCompanyID(r)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "ID da empresa inválido")
+			return
+		}
+		id, err := h.parseID(r)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "ID inválido")
+			return
+		}
+		found, err := h.getIncludingDeleted(r.Context(), id)
+		if err != nil {
+			respondError(w, err, "Erro ao buscar "+h.routeName+": ")
+			return
+		}
+		if len(found) == 0 || found[0].GetCompanyID() != companyID {
+			utils.RespondWithError(w, http.StatusNotFound, h.noun+" não encontrado")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// parseCompanyID lê o {companyID} das rotas aninhadas em /api/companies.
+func parseCompanyID(r *http.Request) (int64, error) {
+	return strconv.ParseInt(chi.URLParam(r, "companyID"), 10, 64)
+}
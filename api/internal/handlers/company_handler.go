@@ -3,28 +3,33 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
-	"strings"
 
+	"nexus/internal/document"
 	"nexus/internal/models"
 	"nexus/internal/repository"
+	"nexus/internal/usecase"
 	"nexus/internal/utils"
 )
 
 // CompanyHandler lida com as requisições para Companies.
 type CompanyHandler struct {
 	*BaseHandler[*models.Company]
-	repo repository.CompanyRepository
+	repo    repository.CompanyRepository
+	usecase usecase.CompanyUsecase
 }
 
-// NewCompanyHandler cria um novo handler de companies, sobrescrevendo o CreateHandler.
-func NewCompanyHandler(repo repository.CompanyRepository) *CompanyHandler {
+// NewCompanyHandler cria um novo handler de companies, sobrescrevendo o CreateHandler e o UpdateHandler.
+func NewCompanyHandler(repo repository.CompanyRepository, companyUsecase usecase.CompanyUsecase) *CompanyHandler {
 	baseHandler := NewBaseHandler(repo, "companies")
 	handler := &CompanyHandler{
 		BaseHandler: baseHandler,
 		repo:        repo,
+		usecase:     companyUsecase,
 	}
 	// Sobrescreve o handler de criação padrão pelo customizado
 	handler.CreateHandler = handler.CreateCompanyHandler
+	handler.UpdateHandler = handler.UpdateCompanyHandler
+	handler.GetAllHandler = handler.ListCompanies
 	return handler
 }
 
@@ -59,33 +64,67 @@ func (h *CompanyHandler) CreateCompanyHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	for _, company := range companiesToSave {
-		if company.Name == "" || company.CNPJ == "" {
-			utils.RespondWithError(w, http.StatusBadRequest, "Nome e CNPJ são obrigatórios para todas as empresas")
-			return
-		}
+	saved, err := h.usecase.Create(r.Context(), companiesToSave)
+	if err != nil {
+		respondError(w, err, "Erro ao criar empresa: ")
+		return
 	}
+	if len(saved) == 1 {
+		utils.RespondWithJSON(w, http.StatusCreated, saved[0])
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusCreated, saved)
+}
 
-	if len(companiesToSave) == 1 {
-		savedCompany, err := h.repo.Save(companiesToSave[0])
-		if err != nil {
-			if strings.Contains(err.Error(), "companies_cnpj_key") {
-				utils.RespondWithError(w, http.StatusConflict, "Este CNPJ já está cadastrado no sistema.")
-				return
-			}
+// UpdateCompanyHandler atualiza uma empresa pelo caso de uso, que valida nome e CNPJ.
+func (h *CompanyHandler) UpdateCompanyHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := h.parseID(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+	company := h.newModel()
+	if err := json.NewDecoder(r.Body).Decode(&company); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Corpo da requisição inválido")
+		return
+	}
+	company.SetID(id)
 
-			utils.RespondWithError(w, http.StatusInternalServerError, "Erro ao criar empresa: "+err.Error())
-			return
-		}
-		utils.RespondWithJSON(w, http.StatusCreated, savedCompany)
-	} else if len(companiesToSave) > 1 {
-		savedCompanies, err := h.repo.SaveBatch(companiesToSave)
-		if err != nil {
-			utils.RespondWithError(w, http.StatusInternalServerError, "Erro ao criar empresas em lote: "+err.Error())
-			return
-		}
-		utils.RespondWithJSON(w, http.StatusCreated, savedCompanies)
-	} else {
-		utils.RespondWithError(w, http.StatusBadRequest, "Nenhuma empresa para cadastrar")
+	if err := h.usecase.Update(r.Context(), company); err != nil {
+		respondError(w, err, "Erro ao atualizar empresa: ")
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, company)
+}
+
+// ListCompanies é a listagem padrão com o filtro ?cnpj= aceitando máscara: o valor é
+// normalizado como no cadastro antes de comparar com o CNPJ gravado.
+func (h *CompanyHandler) ListCompanies(w http.ResponseWriter, r *http.Request) {
+	if cnpj := r.URL.Query().Get("cnpj"); cnpj != "" {
+		r = r.Clone(r.Context())
+		values := r.URL.Query()
+		values.Set("cnpj", document.NormalizeCNPJ(cnpj))
+		r.URL.RawQuery = values.Encode()
+	}
+	h.getAllHandlerDefault(w, r)
+}
+
+// LookupCNPJ godoc
+// @Summary      Consulta um CNPJ
+// @Description  Razão social, nome fantasia e endereço no provedor de consulta (NEXUS_CNPJ_LOOKUP), para preencher o cadastro. Aceita CNPJ numérico ou alfanumérico, com ou sem máscara.
+// @Tags         companies
+// @Produce      json
+// @Param        cnpj query string true "CNPJ"
+// @Success      200  {object}  models.CompanyInfo
+// @Failure      400  {string}  string "CNPJ inválido"
+// @Failure      404  {string}  string "CNPJ não encontrado"
+// @Failure      503  {string}  string "Consulta não configurada ou fora do ar"
+// @Router       /api/companies/lookup [get]
+func (h *CompanyHandler) LookupCNPJ(w http.ResponseWriter, r *http.Request) {
+	info, err := h.usecase.Lookup(r.Context(), r.URL.Query().Get("cnpj"))
+	if err != nil {
+		respondError(w, err, "Erro ao consultar CNPJ: ")
+		return
 	}
+	utils.RespondWithJSON(w, http.StatusOK, info)
 }
@@ -3,10 +3,15 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
+	"nexus/internal/auth"
 	"nexus/internal/models"
 	"nexus/internal/repository"
+	"nexus/internal/usecase"
 	"nexus/internal/utils"
 
 	"github.com/go-chi/chi/v5"
@@ -15,20 +20,33 @@ import (
 // ContractHandler lida com as requisições para Contratos.
 type ContractHandler struct {
 	*BaseHandler[*models.Contract]
-	repo repository.ContractRepository
+	repo    repository.ContractRepository
+	usecase usecase.ContractUsecase
 }
 
 // NewContractHandler cria um novo handler de contratos, sobrescrevendo os handlers.
-func NewContractHandler(repo repository.ContractRepository) *ContractHandler {
+func NewContractHandler(repo repository.ContractRepository, contractUsecase usecase.ContractUsecase) *ContractHandler {
 	baseHandler := NewBaseHandler(repo, "contracts")
 	handler := &ContractHandler{
 		BaseHandler: baseHandler,
 		repo:        repo,
+		usecase:     contractUsecase,
 	}
 	// Sobrescreve os handlers padrão pelos customizados
 	handler.CreateHandler = handler.CreateContractHandler
 	handler.UpdateHandler = handler.UpdateContractHandler
 	handler.GetAllHandler = handler.ListContracts
+
+	// Consultor só enxerga contratos ativos
+	handler.ReadPolicy = func(user *models.User, contract *models.Contract) bool {
+		return auth.IsAdmin(user) || contract.IsActive
+	}
+	handler.ReadFilter = func(user *models.User) map[string]string {
+		if auth.IsAdmin(user) {
+			return nil
+		}
+		return map[string]string{"isActive": "true"}
+	}
 	return handler
 }
 
@@ -36,15 +54,15 @@ func NewContractHandler(repo repository.ContractRepository) *ContractHandler {
 
 // createContractHandler godoc
 // @Summary      Cria um novo contrato
-// @Description  Valida as datas e insere um novo contrato no banco
+// @Description  Valida as datas e a empresa (existente e não removida) e insere um novo contrato no banco
 // @Tags         contracts
 // @Accept       json
 // @Produce      json
 // @Param        contract body models.Contract true "Objeto Contrato"
 // @Success      201  {object}  models.Contract
-// @Failure      400  {string}  string "Erro de validação"
+// @Failure      400  {string}  string "Erro de validação ou empresa não encontrada"
 // @Router       /api/contracts [post]
-// CreateContractHandler sobrescreve o método base para adicionar validação de data.
+// CreateContractHandler sobrescreve o método base para validar pelo caso de uso.
 func (h *ContractHandler) CreateContractHandler(w http.ResponseWriter, r *http.Request) {
 	contract := h.newModel()
 	if err := json.NewDecoder(r.Body).Decode(&contract); err != nil {
@@ -52,14 +70,9 @@ func (h *ContractHandler) CreateContractHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	if contract.EndDate.Before(contract.StartDate) {
-		utils.RespondWithError(w, http.StatusBadRequest, "Data de fim não pode ser anterior à data de início")
-		return
-	}
-
-	savedContract, err := h.repo.Save(contract)
+	savedContract, err := h.usecase.Create(r.Context(), contract)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "Erro ao criar contrato: "+err.Error())
+		respondError(w, err, "Erro ao criar contrato: ")
 		return
 	}
 	utils.RespondWithJSON(w, http.StatusCreated, savedContract)
@@ -77,7 +90,7 @@ func (h *ContractHandler) CreateContractHandler(w http.ResponseWriter, r *http.R
 // @Failure      400  {string}  string "ID inválido ou datas incorretas"
 // @Failure      404  {string}  string "Contrato não encontrado"
 // @Router       /api/contracts/{id} [put]
-// UpdateContractHandler sobrescreve o método base para adicionar validação de data.
+// UpdateContractHandler sobrescreve o método base para validar pelo caso de uso.
 func (h *ContractHandler) UpdateContractHandler(w http.ResponseWriter, r *http.Request) {
 	id, err := h.parseID(r)
 	if err != nil {
@@ -90,19 +103,9 @@ func (h *ContractHandler) UpdateContractHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	if contract.EndDate.Before(contract.StartDate) {
-		utils.RespondWithError(w, http.StatusBadRequest, "Data de fim não pode ser anterior à data de início")
-		return
-	}
-
 	contract.SetID(id)
-	rowsAffected, err := h.repo.Update(contract)
-	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "Erro ao atualizar contrato: "+err.Error())
-		return
-	}
-	if rowsAffected == 0 {
-		utils.RespondWithError(w, http.StatusNotFound, "Contrato não encontrado")
+	if err := h.usecase.Update(r.Context(), contract); err != nil {
+		respondError(w, err, "Erro ao atualizar contrato: ")
 		return
 	}
 	utils.RespondWithJSON(w, http.StatusOK, contract)
@@ -110,30 +113,107 @@ func (h *ContractHandler) UpdateContractHandler(w http.ResponseWriter, r *http.R
 
 // ListContracts godoc
 // @Summary      Lista todos os contratos
-// @Description  Retorna a lista completa de contratos com dados da empresa (JOIN)
+// @Description  Retorna a lista completa de contratos com dados da empresa (JOIN). Com ?include=balance traz também o saldo de horas.
 // @Tags         contracts
 // @Accept       json
 // @Produce      json
+// @Param        include  query string false "Dados extras (balance)"
+// @Param        page     query int    false "Página (começa em 1)"
+// @Param        pageSize query int    false "Itens por página (padrão 50, máx. 200)"
+// @Param        sort     query string false "Ex.: companyName,-startDate"
+// @Param        companyId query int   false "Filtra por empresa"
+// @Param        isActive query bool   false "Filtra por situação"
 // @Success      200  {array}  models.Contract
 // @Router       /api/contracts [get]
 func (h *ContractHandler) ListContracts(w http.ResponseWriter, r *http.Request) {
-	contracts, err := h.repo.GetAllWithCompany()
+	h.listWithCompany(w, r, nil)
+}
+
+// listWithCompany lista contratos com a linguagem de listagem comum, somando os filtros fixos da rota.
+func (h *ContractHandler) listWithCompany(w http.ResponseWriter, r *http.Request, fixed map[string]string) {
+	query, err := parseListQuery(r)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "Erro ao buscar contratos: "+err.Error())
+		respondError(w, err, "")
 		return
 	}
+	for field, value := range fixed {
+		query.Filters[field] = value
+	}
+	// Consultor só enxerga contratos ativos: filtra no banco para a paginação bater
+	h.restrictToReadable(r, &query)
 
-	// Se não tiver nada, retorna array vazio [] em vez de null
-	if contracts == nil {
-		utils.RespondWithJSON(w, http.StatusOK, []*models.Contract{})
+	page, err := h.repo.GetAllWithCompany(r.Context(), query)
+	if err != nil {
+		respondError(w, err, "Erro ao buscar contratos: ")
 		return
 	}
 
-	utils.RespondWithJSON(w, http.StatusOK, contracts)
+	if slices.Contains(strings.Split(r.URL.Query().Get("include"), ","), "balance") {
+		ids := make([]int64, len(page.Items))
+		for i, contract := range page.Items {
+			ids[i] = contract.ID
+		}
+		balances, err := h.repo.GetBalances(r.Context(), ids, time.Now())
+		if err != nil {
+			respondError(w, err, "Erro ao calcular saldos: ")
+			return
+		}
+		for _, contract := range page.Items {
+			contract.Balance = balances[contract.ID]
+		}
+	}
+
+	respondWithPage(w, r, page, h.filterReadable(r, page.Items))
 }
 
 // MÉTODOS ESPECÍFICOS - Apontar para o router
 
+// GetContractBalance godoc
+// @Summary      Saldo de horas do contrato
+// @Description  Horas contratadas, consumidas, restantes, % usado e data projetada de esgotamento pelo ritmo dos últimos 30 dias. No banco de horas o saldo é do período (mensal, a partir do início do contrato) que contém a data, com a sobra trazida do anterior; sob demanda não há limite.
+// @Tags         contracts
+// @Produce      json
+// @Param        id   path      int     true  "ID do Contrato"
+// @Param        at   query     string  false "Data do período no banco de horas (AAAA-MM-DD, padrão: hoje)"
+// @Success      200  {object}  models.ContractBalance
+// @Failure      404  {string}  string "Contrato não encontrado"
+// @Router       /api/contracts/{id}/balance [get]
+func (h *ContractHandler) GetContractBalance(w http.ResponseWriter, r *http.Request) {
+	id, err := h.parseID(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	contracts, err := h.repo.Get(r.Context(), &id)
+	if err != nil {
+		respondError(w, err, "Erro ao buscar contrato: ")
+		return
+	}
+	if len(contracts) == 0 || !h.ReadPolicy.allows(auth.UserFromContext(r.Context()), contracts[0]) {
+		utils.RespondWithError(w, http.StatusNotFound, "Contrato não encontrado")
+		return
+	}
+
+	at := time.Now()
+	if v := r.URL.Query().Get("at"); v != "" {
+		if at, err = time.Parse(time.DateOnly, v); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Data inválida: use o formato AAAA-MM-DD")
+			return
+		}
+	}
+	balance, err := h.repo.GetBalance(r.Context(), id, at)
+	if err != nil {
+		respondError(w, err, "Erro ao calcular saldo: ")
+		return
+	}
+	if balance == nil {
+		utils.RespondWithError(w, http.StatusNotFound, "Contrato não encontrado")
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, balance)
+}
+
 // ListContractsByCompany lida com a busca de contratos por ID da empresa.
 func (h *ContractHandler) ListContractsByCompany(w http.ResponseWriter, r *http.Request) {
 	// 1. O Chi já separou o ID pra gente. É só pegar.
@@ -152,17 +232,6 @@ func (h *ContractHandler) ListContractsByCompany(w http.ResponseWriter, r *http.
 		return
 	}
 
-	// 3. Chamada ao Banco (sem mexer na lógica)
-	contracts, err := h.repo.GetByCompanyID(companyID)
-	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "Erro ao buscar contratos: "+err.Error())
-		return
-	}
-
-	// 4. Retorno Vazio (Array vazio é melhor que null)
-	if contracts == nil {
-		utils.RespondWithJSON(w, http.StatusOK, []*models.Contract{})
-		return
-	}
-	utils.RespondWithJSON(w, http.StatusOK, contracts)
+	// 3. Mesma listagem de /api/contracts, presa à empresa da URL
+	h.listWithCompany(w, r, map[string]string{"companyId": strconv.FormatInt(companyID, 10)})
 }
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"nexus/internal/utils"
+)
+
+// tableExport abre o arquivo só na primeira linha: assim um erro da consulta
+// (ex.: filtro inválido) ainda pode virar uma resposta JSON com o status certo.
+type tableExport struct {
+	w        http.ResponseWriter
+	format   string
+	locale   utils.ExportLocale
+	filename string
+	columns  []utils.ExportColumn
+	enc      utils.TableEncoder
+}
+
+func newTableExport(w http.ResponseWriter, r *http.Request, format, filename string, columns []utils.ExportColumn) *tableExport {
+	return &tableExport{
+		w:        w,
+		format:   format,
+		locale:   utils.ExportLocaleFromRequest(r),
+		filename: filename,
+		columns:  columns,
+	}
+}
+
+func (e *tableExport) open() error {
+	if e.enc != nil {
+		return nil
+	}
+	enc, err := utils.NewTableEncoder(e.w, e.format, e.locale, e.filename, e.columns)
+	if err != nil {
+		return err
+	}
+	e.enc = enc
+	return nil
+}
+
+// WriteRow escreve uma linha, abrindo o arquivo se for a primeira.
+func (e *tableExport) WriteRow(values ...any) error {
+	if err := e.open(); err != nil {
+		return err
+	}
+	return e.enc.WriteRow(values...)
+}
+
+// Finish fecha o arquivo. Se nada foi escrito ainda, err vira uma resposta de erro
+// via respondErr; depois que o download começou, só dá para registrar no log.
+func (e *tableExport) Finish(err error, respondErr func(error)) {
+	if err != nil && e.enc == nil {
+		respondErr(err)
+		return
+	}
+	if err == nil {
+		err = e.open() // Lista vazia: devolve só o cabeçalho
+	}
+	if e.enc != nil {
+		if closeErr := e.enc.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		log.Printf("Erro ao exportar %s: %v", e.filename, err)
+	}
+}
@@ -0,0 +1,497 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"nexus/internal/audit"
+	"nexus/internal/models"
+	"nexus/internal/notify"
+	"nexus/internal/pdf"
+	"nexus/internal/repository"
+	"nexus/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/shopspring/decimal"
+)
+
+// InvoiceHandler lida com as faturas montadas a partir das horas aprovadas.
+type InvoiceHandler struct {
+	*BaseHandler[*models.Invoice]
+	repo        repository.InvoiceRepository
+	companyRepo repository.CompanyRepository
+	addressRepo repository.Repository[*models.CompanyAddress]
+	recipients  *notify.Router
+}
+
+// NewInvoiceHandler cria um novo handler de faturas, sobrescrevendo os handlers.
+// A fatura vai para os contatos do financeiro, no endereço de cobrança principal.
+func NewInvoiceHandler(
+	repo repository.InvoiceRepository,
+	companyRepo repository.CompanyRepository,
+	addressRepo repository.Repository[*models.CompanyAddress],
+	recipients *notify.Router,
+) *InvoiceHandler {
+	baseHandler := NewBaseHandler(repo, "invoices")
+	handler := &InvoiceHandler{
+		BaseHandler: baseHandler,
+		repo:        repo,
+		companyRepo: companyRepo,
+		addressRepo: addressRepo,
+		recipients:  recipients,
+	}
+	handler.CreateHandler = handler.CreateInvoice
+	handler.GetAllHandler = handler.ListInvoices
+	handler.GetByIDHandler = handler.GetInvoice
+	handler.DeleteHandler = handler.DeleteInvoice
+	return handler
+}
+
+// MÉTODOS BASE CUSTOMIZADOS - Apontar para o Handler
+
+// CreateInvoice godoc
+// @Summary      Monta uma fatura rascunho
+// @Description  Junta as horas finalizadas, de semanas aprovadas e ainda não faturadas, dos contratos da empresa no período, com o valor-hora vigente (um item por contrato × consultor × valor). Os apontamentos ficam marcados como faturados. Itens manuais podem vir em lines.
+// @Tags         invoices
+// @Accept       json
+// @Produce      json
+// @Param        invoice body models.CreateInvoiceRequest true "Empresa e período (datas inclusivas)"
+// @Success      201  {object}  models.Invoice
+// @Failure      400  {string}  string "Erro de validação"
+// @Router       /api/invoices [post]
+func (h *InvoiceHandler) CreateInvoice(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateInvoiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "JSON inválido")
+		return
+	}
+	from, err1 := time.Parse(time.DateOnly, req.From)
+	to, err2 := time.Parse(time.DateOnly, req.To)
+	if err1 != nil || err2 != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "from e to são obrigatórios (use AAAA-MM-DD)")
+		return
+	}
+	if to.Before(from) {
+		utils.RespondWithError(w, http.StatusBadRequest, "from deve ser anterior ou igual a to")
+		return
+	}
+	for _, line := range req.Lines {
+		if err := validateInvoiceLine(line); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	companies, err := h.companyRepo.Get(r.Context(), &req.CompanyID)
+	if err != nil {
+		respondError(w, err, "Erro ao buscar empresa: ")
+		return
+	}
+	if len(companies) == 0 {
+		utils.RespondWithError(w, http.StatusBadRequest, "Empresa não encontrada")
+		return
+	}
+
+	invoice, err := h.repo.CreateDraft(r.Context(), &models.Invoice{
+		CompanyID:   req.CompanyID,
+		PeriodStart: from,
+		PeriodEnd:   to,
+		Notes:       req.Notes,
+		CreatedAt:   time.Now(),
+		Lines:       req.Lines,
+	})
+	if err != nil {
+		respondError(w, err, "Erro ao montar fatura: ")
+		return
+	}
+	audit.Record(r.Context(), h.repo.GetTableName(), models.AuditCreate, invoice.ID, nil, invoice)
+	if len(invoice.Lines) == 0 {
+		invoice.Warnings = append(invoice.Warnings, "Nenhuma hora aprovada a faturar no período")
+	}
+	utils.RespondWithJSON(w, http.StatusCreated, invoice)
+}
+
+// ListInvoices godoc
+// @Summary      Lista faturas
+// @Description  Faturas com o nome da empresa (sem os itens). Aceita paginação, ordenação e filtros (companyId, status, year, from/to sobre periodStart).
+// @Tags         invoices
+// @Produce      json
+// @Param        page      query int    false "Página (começa em 1)"
+// @Param        pageSize  query int    false "Itens por página (padrão 50, máx. 200)"
+// @Param        sort      query string false "Ex.: -createdAt,number"
+// @Param        status    query string false "draft, issued, paid ou void"
+// @Param        companyId query int    false "Filtra por empresa"
+// @Success      200  {array}  models.Invoice
+// @Router       /api/invoices [get]
+func (h *InvoiceHandler) ListInvoices(w http.ResponseWriter, r *http.Request) {
+	query, err := parseListQuery(r)
+	if err != nil {
+		respondError(w, err, "")
+		return
+	}
+	page, err := h.repo.GetAllWithDetails(r.Context(), query)
+	if err != nil {
+		respondError(w, err, "Erro ao buscar faturas: ")
+		return
+	}
+	respondWithPage(w, r, page, page.Items)
+}
+
+// GetInvoice godoc
+// @Summary      Detalhes da fatura
+// @Description  Retorna a fatura com os itens.
+// @Tags         invoices
+// @Produce      json
+// @Param        id   path      int  true  "ID da Fatura"
+// @Success      200  {object}  models.Invoice
+// @Failure      404  {string}  string "Fatura não encontrada"
+// @Router       /api/invoices/{id} [get]
+func (h *InvoiceHandler) GetInvoice(w http.ResponseWriter, r *http.Request) {
+	invoice, ok := h.load(w, r)
+	if !ok {
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, invoice)
+}
+
+// DeleteInvoice godoc
+// @Summary      Apaga uma fatura rascunho
+// @Description  Só rascunhos podem ser apagados; as horas voltam a ficar disponíveis. Depois de emitida, use void.
+// @Tags         invoices
+// @Param        id   path      int  true  "ID da Fatura"
+// @Success      204
+// @Failure      404  {string}  string "Fatura não encontrada"
+// @Failure      409  {string}  string "Fatura já emitida"
+// @Router       /api/invoices/{id} [delete]
+func (h *InvoiceHandler) DeleteInvoice(w http.ResponseWriter, r *http.Request) {
+	id, err := h.parseID(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+	before := h.current(r.Context(), id)
+	rowsAffected, err := h.repo.DeleteDraft(r.Context(), id)
+	if !h.respondDraftError(w, err, "Erro ao apagar fatura: ") {
+		return
+	}
+	if rowsAffected == 0 {
+		utils.RespondWithError(w, http.StatusNotFound, "Fatura não encontrada")
+		return
+	}
+	audit.Record(r.Context(), h.repo.GetTableName(), models.AuditDelete, id, before, nil)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// MÉTODOS ESPECÍFICOS - Apontar para o router
+
+// AddLine godoc
+// @Summary      Inclui um item manual
+// @Description  Só em rascunho. amount = quantity × unitPrice, em centavos. unitPrice negativo serve para descontos.
+// @Tags         invoices
+// @Accept       json
+// @Produce      json
+// @Param        id   path int                true "ID da Fatura"
+// @Param        line body models.InvoiceLine true "Descrição, quantidade e preço unitário"
+// @Success      201  {object}  models.InvoiceLine
+// @Failure      400  {string}  string "Erro de validação"
+// @Failure      404  {string}  string "Fatura não encontrada"
+// @Failure      409  {string}  string "Fatura já emitida"
+// @Router       /api/invoices/{id}/lines [post]
+func (h *InvoiceHandler) AddLine(w http.ResponseWriter, r *http.Request) {
+	id, err := h.parseID(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+	var line models.InvoiceLine
+	if err := json.NewDecoder(r.Body).Decode(&line); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "JSON inválido")
+		return
+	}
+	if err := validateInvoiceLine(&line); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	before := h.linesSnapshot(r.Context(), id)
+	saved, err := h.repo.AddLine(r.Context(), id, &line)
+	if !h.respondDraftError(w, err, "Erro ao incluir item: ") {
+		return
+	}
+	if saved == nil {
+		utils.RespondWithError(w, http.StatusNotFound, "Fatura não encontrada")
+		return
+	}
+	audit.Record(r.Context(), h.repo.GetTableName(), models.AuditUpdate, id, before, h.linesSnapshot(r.Context(), id))
+	utils.RespondWithJSON(w, http.StatusCreated, saved)
+}
+
+// DeleteLine godoc
+// @Summary      Remove um item
+// @Description  Só em rascunho. Removendo um item de horas, os apontamentos dele voltam a ficar disponíveis para faturar.
+// @Tags         invoices
+// @Param        id     path int true "ID da Fatura"
+// @Param        lineID path int true "ID do Item"
+// @Success      204
+// @Failure      404  {string}  string "Item não encontrado"
+// @Failure      409  {string}  string "Fatura já emitida"
+// @Router       /api/invoices/{id}/lines/{lineID} [delete]
+func (h *InvoiceHandler) DeleteLine(w http.ResponseWriter, r *http.Request) {
+	id, err := h.parseID(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+	lineID, err := strconv.ParseInt(chi.URLParam(r, "lineID"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID do item inválido")
+		return
+	}
+	before := h.linesSnapshot(r.Context(), id)
+	rowsAffected, err := h.repo.DeleteLine(r.Context(), id, lineID)
+	if !h.respondDraftError(w, err, "Erro ao remover item: ") {
+		return
+	}
+	if rowsAffected == 0 {
+		utils.RespondWithError(w, http.StatusNotFound, "Item não encontrado")
+		return
+	}
+	audit.Record(r.Context(), h.repo.GetTableName(), models.AuditUpdate, id, before, h.linesSnapshot(r.Context(), id))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// invoiceLineColumns são as colunas da planilha de itens da fatura.
+var invoiceLineColumns = []utils.ExportColumn{
+	{Header: "Item", HeaderPT: "Item", Kind: utils.CellInteger},
+	{Header: "Type", HeaderPT: "Tipo"},
+	{Header: "Description", HeaderPT: "Descrição"},
+	{Header: "Quantity", HeaderPT: "Quantidade", Kind: utils.CellNumber},
+	{Header: "Unit price", HeaderPT: "Valor unitário", Kind: utils.CellNumber},
+	{Header: "Amount", HeaderPT: "Valor", Kind: utils.CellNumber},
+}
+
+// ListLines godoc
+// @Summary      Itens da fatura
+// @Description  Retorna os itens em JSON ou, com ?format=csv|xlsx, como planilha.
+// @Tags         invoices
+// @Produce      json
+// @Param        id     path  int    true  "ID da Fatura"
+// @Param        format query string false "Exporta como csv ou xlsx (também via header Accept)"
+// @Param        locale query string false "Idioma da exportação: en (padrão) ou pt-BR"
+// @Success      200  {array}  models.InvoiceLine
+// @Failure      404  {string}  string "Fatura não encontrada"
+// @Router       /api/invoices/{id}/lines [get]
+func (h *InvoiceHandler) ListLines(w http.ResponseWriter, r *http.Request) {
+	invoice, ok := h.load(w, r)
+	if !ok {
+		return
+	}
+	format := utils.ExportFormatFromRequest(r)
+	if format == "" {
+		utils.RespondWithJSON(w, http.StatusOK, invoice.Lines)
+		return
+	}
+
+	export := newTableExport(w, r, format, "fatura-"+invoiceFileName(invoice), invoiceLineColumns)
+	var err error
+	for i, line := range invoice.Lines {
+		if err = export.WriteRow(i+1, line.Kind, line.Description, line.Quantity, line.UnitPrice, line.Amount); err != nil {
+			break
+		}
+	}
+	export.Finish(err, func(err error) {
+		respondError(w, err, "Erro ao exportar fatura: ")
+	})
+}
+
+// InvoicePDF godoc
+// @Summary      Fatura em PDF
+// @Description  Documento da fatura para o cliente: empresa e CNPJ, endereço de cobrança e contatos do financeiro, período, itens e total. Rascunhos saem marcados como tal.
+// @Tags         invoices
+// @Produce      application/pdf
+// @Param        id   path  int  true  "ID da Fatura"
+// @Success      200  {file}    file
+// @Failure      404  {string}  string "Fatura não encontrada"
+// @Router       /api/invoices/{id}/invoice.pdf [get]
+func (h *InvoiceHandler) InvoicePDF(w http.ResponseWriter, r *http.Request) {
+	invoice, ok := h.load(w, r)
+	if !ok {
+		return
+	}
+	companies, err := h.companyRepo.Get(r.Context(), &invoice.CompanyID)
+	if err != nil {
+		respondError(w, err, "Erro ao buscar empresa: ")
+		return
+	}
+	var company *models.Company
+	if len(companies) > 0 {
+		company = companies[0]
+	}
+	billTo, err := h.billTo(r.Context(), invoice.CompanyID)
+	if err != nil {
+		respondError(w, err, "Erro ao buscar destinatários: ")
+		return
+	}
+
+	// Gera em memória para ainda poder responder com erro se algo falhar
+	var buf bytes.Buffer
+	if err := pdf.RenderInvoice(&buf, invoice, company, billTo, time.Now()); err != nil {
+		respondError(w, err, "Erro ao gerar PDF: ")
+		return
+	}
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", `attachment; filename="fatura-`+invoiceFileName(invoice)+`.pdf"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// Transition devolve o handler de uma rota de mudança de status (issue, pay, void).
+// @Summary      Muda o status da fatura
+// @Description  issue → issued (recebe o próximo número do ano e avisa os contatos do financeiro), pay → paid, void → void (as horas voltam a ficar disponíveis). Responde 409 se o status atual não permitir.
+// @Tags         invoices
+// @Produce      json
+// @Param        id   path      int  true  "ID da Fatura"
+// @Success      200  {object}  models.Invoice
+// @Failure      404  {string}  string "Fatura não encontrada"
+// @Failure      409  {string}  string "Transição de status inválida"
+// @Router       /api/invoices/{id}/issue [post]
+// @Router       /api/invoices/{id}/pay [post]
+// @Router       /api/invoices/{id}/void [post]
+func (h *InvoiceHandler) Transition(status string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := h.parseID(r)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "ID inválido")
+			return
+		}
+		before := h.current(r.Context(), id)
+		invoice, err := h.repo.Transition(r.Context(), id, status, time.Now())
+		if errors.Is(err, repository.ErrInvalidTransition) {
+			utils.RespondWithError(w, http.StatusConflict, "A fatura está "+invoice.Status+
+				" e não pode ir para "+status+" (aceito a partir de: "+strings.Join(models.InvoiceTransitionsTo(status), ", ")+")")
+			return
+		}
+		if err != nil {
+			respondError(w, err, "Erro ao alterar status da fatura: ")
+			return
+		}
+		if invoice == nil {
+			utils.RespondWithError(w, http.StatusNotFound, "Fatura não encontrada")
+			return
+		}
+		audit.Record(r.Context(), h.repo.GetTableName(), models.AuditUpdate, id, before, invoice)
+		if status == models.InvoiceIssued {
+			msg := notify.Message{
+				Subject: "Fatura " + invoice.Code + " emitida",
+				Body:    "Período de " + invoice.PeriodStart.Format("02/01/2006") + " a " + invoice.PeriodEnd.Format("02/01/2006") + ", total de R$ " + strings.Replace(invoice.Total.StringFixed(2), ".", ",", 1) + ".",
+			}
+			if err := h.recipients.Send(r.Context(), invoice.CompanyID, models.ContactBilling, msg); err != nil {
+				log.Printf("Erro ao avisar a emissão da fatura %d: %v", id, err)
+			}
+		}
+		utils.RespondWithJSON(w, http.StatusOK, invoice)
+	}
+}
+
+// billTo monta o destinatário da fatura: contatos do financeiro e endereço de cobrança principal.
+func (h *InvoiceHandler) billTo(ctx context.Context, companyID int64) (*models.BillTo, error) {
+	contacts, err := h.recipients.Recipients(ctx, companyID, models.ContactBilling)
+	if err != nil {
+		return nil, err
+	}
+	page, err := h.addressRepo.List(ctx, repository.ListQuery{
+		PageSize: 1,
+		Sort:     []string{"-primary", "id"},
+		Filters:  map[string]string{"companyId": strconv.FormatInt(companyID, 10)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar endereço de cobrança: %w", err)
+	}
+	billTo := &models.BillTo{Contacts: contacts}
+	if len(page.Items) > 0 {
+		billTo.Address = page.Items[0]
+	}
+	return billTo, nil
+}
+
+// invoiceLinesAudit é o que a auditoria guarda de uma edição de itens: o total e os itens.
+// As tags db marcam os campos que entram no audit.Diff.
+type invoiceLinesAudit struct {
+	Total decimal.Decimal       `json:"total" db:"total"`
+	Lines []*models.InvoiceLine `json:"lines" db:"lines"`
+}
+
+// linesSnapshot busca o total e os itens da fatura para a auditoria (nil se não existir ou falhar).
+func (h *InvoiceHandler) linesSnapshot(ctx context.Context, id int64) *invoiceLinesAudit {
+	invoice, err := h.repo.GetWithDetails(ctx, id)
+	if err != nil || invoice == nil {
+		return nil
+	}
+	return &invoiceLinesAudit{Total: invoice.Total, Lines: invoice.Lines}
+}
+
+// load busca a fatura do {id} com os itens. Já responde ao cliente e devolve false se falhar.
+func (h *InvoiceHandler) load(w http.ResponseWriter, r *http.Request) (*models.Invoice, bool) {
+	id, err := h.parseID(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID inválido")
+		return nil, false
+	}
+	invoice, err := h.repo.GetWithDetails(r.Context(), id)
+	if err != nil {
+		respondError(w, err, "Erro ao buscar fatura: ")
+		return nil, false
+	}
+	if invoice == nil {
+		utils.RespondWithError(w, http.StatusNotFound, "Fatura não encontrada")
+		return nil, false
+	}
+	return invoice, true
+}
+
+// respondDraftError responde 409 para fatura que não é mais rascunho e 500 para o resto.
+// Devolve true quando não houve erro.
+func (h *InvoiceHandler) respondDraftError(w http.ResponseWriter, err error, prefix string) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, repository.ErrInvoiceNotDraft):
+		utils.RespondWithError(w, http.StatusConflict, "A fatura já foi emitida ou cancelada e não pode mais ser alterada")
+	default:
+		respondError(w, err, prefix)
+	}
+	return false
+}
+
+// validateInvoiceLine confere um item manual. O erro já é a mensagem para o cliente.
+func validateInvoiceLine(line *models.InvoiceLine) error {
+	line.Description = strings.TrimSpace(line.Description)
+	if line.Description == "" {
+		return errors.New("A descrição do item é obrigatória")
+	}
+	if !line.Quantity.IsPositive() {
+		return errors.New("A quantidade do item deve ser maior que zero")
+	}
+	if !line.Quantity.Equal(line.Quantity.Round(2)) || !line.UnitPrice.Equal(line.UnitPrice.Round(2)) {
+		return errors.New("Quantidade e valor unitário aceitam no máximo 2 casas decimais")
+	}
+	// Itens manuais não são ligados a horas
+	line.ContractID, line.UserID = nil, nil
+	return nil
+}
+
+// invoiceFileName é o nome do arquivo: o código da fatura (2026-0007) ou "rascunho-<id>".
+func invoiceFileName(invoice *models.Invoice) string {
+	if invoice.Code == "" {
+		return "rascunho-" + strconv.FormatInt(invoice.ID, 10)
+	}
+	return strings.ReplaceAll(invoice.Code, "/", "-")
+}
@@ -0,0 +1,399 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"nexus/internal/auth"
+	"nexus/internal/models"
+	"nexus/internal/repository"
+	"nexus/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ReportHandler lida com os relatórios agregados.
+type ReportHandler struct {
+	repo         repository.ReportRepository
+	contractRepo repository.ContractRepository
+}
+
+// NewReportHandler cria um novo handler de relatórios.
+func NewReportHandler(repo repository.ReportRepository, contractRepo repository.ContractRepository) *ReportHandler {
+	return &ReportHandler{repo: repo, contractRepo: contractRepo}
+}
+
+// HoursReport godoc
+// @Summary      Relatório de horas agrupado
+// @Description  Soma as horas por empresa, contrato e/ou usuário e por período (date_trunc sobre o início do apontamento). Consultor só vê as próprias horas.
+// @Tags         reports
+// @Produce      json
+// @Param        groupBy    query string false "Dimensões separadas por vírgula: company, contract, user, ticket"
+// @Param        period     query string false "Quebra por período: day, week, month, quarter, year"
+// @Param        from       query string false "Data inicial (2026-01-01), inclusiva"
+// @Param        to         query string false "Data final (2026-03-31), inclusiva"
+// @Param        companyId  query int    false "Filtra por empresa"
+// @Param        contractId query int    false "Filtra por contrato"
+// @Param        userId     query int    false "Filtra por consultor"
+// @Param        ticketId   query int    false "Filtra por chamado"
+// @Param        approvalStatus query string false "Status da semana: draft, submitted, approved ou rejected"
+// @Param        format     query string false "Exporta como csv ou xlsx (também via header Accept)"
+// @Param        locale     query string false "Idioma da exportação: en (padrão) ou pt-BR"
+// @Success      200  {object}  models.HoursReport
+// @Failure      400  {string}  string "Parâmetros inválidos"
+// @Router       /api/reports/hours [get]
+func (h *ReportHandler) HoursReport(w http.ResponseWriter, r *http.Request) {
+	query, report, err := parseHoursReportQuery(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Consultor só enxerga a própria produtividade
+	if user := auth.UserFromContext(r.Context()); !auth.IsAdmin(user) {
+		query.UserID = user.ID
+	}
+
+	rows, err := h.repo.HoursReport(r.Context(), query)
+	if err != nil {
+		respondError(w, err, "Erro ao gerar relatório: ")
+		return
+	}
+
+	report.Rows = rows
+	if report.Rows == nil {
+		report.Rows = []*models.HoursReportRow{}
+	}
+	for _, row := range report.Rows {
+		report.DurationSeconds += row.DurationSeconds
+	}
+	report.TotalHours = float64(report.DurationSeconds) / 3600
+
+	if format := utils.ExportFormatFromRequest(r); format != "" {
+		exportHoursReport(w, r, format, report)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, report)
+}
+
+// RevenueReport godoc
+// @Summary      Relatório de faturamento
+// @Description  Horas dos apontamentos finalizados multiplicadas pelo valor-hora vigente no dia (do consultor no contrato ou, na falta, o padrão do contrato). Valores em decimal exato, como string. Aceita os mesmos parâmetros do relatório de horas.
+// @Tags         reports
+// @Produce      json
+// @Param        groupBy        query string false "Dimensões separadas por vírgula: company, contract, user, ticket"
+// @Param        period         query string false "Quebra por período: day, week, month, quarter, year"
+// @Param        from           query string false "Data inicial (2026-01-01), inclusiva"
+// @Param        to             query string false "Data final (2026-03-31), inclusiva"
+// @Param        approvalStatus query string false "Status da semana: draft, submitted, approved ou rejected"
+// @Param        format         query string false "Exporta como csv ou xlsx (também via header Accept)"
+// @Param        locale         query string false "Idioma da exportação: en (padrão) ou pt-BR"
+// @Success      200  {object}  models.RevenueReport
+// @Failure      400  {string}  string "Parâmetros inválidos"
+// @Router       /api/reports/revenue [get]
+func (h *ReportHandler) RevenueReport(w http.ResponseWriter, r *http.Request) {
+	query, hours, err := parseHoursReportQuery(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rows, err := h.repo.RevenueReport(r.Context(), query)
+	if err != nil {
+		respondError(w, err, "Erro ao gerar relatório: ")
+		return
+	}
+
+	report := &models.RevenueReport{
+		GroupBy: hours.GroupBy,
+		Period:  hours.Period,
+		From:    hours.From,
+		To:      hours.To,
+		Rows:    rows,
+	}
+	if report.Rows == nil {
+		report.Rows = []*models.RevenueReportRow{}
+	}
+	var unratedSeconds int64
+	for _, row := range report.Rows {
+		report.DurationSeconds += row.DurationSeconds
+		unratedSeconds += row.UnratedSeconds
+		report.Amount = report.Amount.Add(row.Amount)
+	}
+	report.TotalHours = float64(report.DurationSeconds) / 3600
+	report.UnratedHours = float64(unratedSeconds) / 3600
+
+	if format := utils.ExportFormatFromRequest(r); format != "" {
+		exportRevenueReport(w, r, format, report)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, report)
+}
+
+// reportDimensions devolve as colunas do período e das dimensões agrupadas de um relatório,
+// com a função que lê cada uma da linha.
+func reportDimensions(period string, groupBy []string) ([]utils.ExportColumn, []func(*models.HoursReportRow) any) {
+	var columns []utils.ExportColumn
+	var values []func(*models.HoursReportRow) any
+	if period != "" {
+		columns = append(columns, utils.ExportColumn{Header: "Period", HeaderPT: "Período", Kind: utils.CellDate})
+		values = append(values, func(row *models.HoursReportRow) any { return row.Period })
+	}
+	for _, dim := range groupBy {
+		switch dim {
+		case "company":
+			columns = append(columns, utils.ExportColumn{Header: "Company", HeaderPT: "Empresa"})
+			values = append(values, func(row *models.HoursReportRow) any { return row.CompanyName })
+		case "contract":
+			columns = append(columns, utils.ExportColumn{Header: "Contract", HeaderPT: "Contrato"})
+			values = append(values, func(row *models.HoursReportRow) any { return row.ContractTitle })
+		case "user":
+			columns = append(columns, utils.ExportColumn{Header: "Consultant", HeaderPT: "Consultor"})
+			values = append(values, func(row *models.HoursReportRow) any { return row.UserName })
+		case "ticket":
+			columns = append(columns, utils.ExportColumn{Header: "Ticket", HeaderPT: "Chamado"})
+			values = append(values, func(row *models.HoursReportRow) any { return row.TicketTitle })
+		}
+	}
+	return columns, values
+}
+
+// exportHoursReport gera a planilha só com as colunas agrupadas, mais período, quantidade e horas.
+func exportHoursReport(w http.ResponseWriter, r *http.Request, format string, report *models.HoursReport) {
+	columns, values := reportDimensions(report.Period, report.GroupBy)
+	columns = append(columns,
+		utils.ExportColumn{Header: "Appointments", HeaderPT: "Apontamentos", Kind: utils.CellInteger},
+		utils.ExportColumn{Header: "Hours", HeaderPT: "Horas", Kind: utils.CellNumber},
+	)
+
+	export := newTableExport(w, r, format, "relatorio-horas", columns)
+	var err error
+	for _, row := range report.Rows {
+		cells := make([]any, 0, len(columns))
+		for _, value := range values {
+			cells = append(cells, value(row))
+		}
+		cells = append(cells, row.AppointmentCount, row.TotalHours)
+		if err = export.WriteRow(cells...); err != nil {
+			break
+		}
+	}
+	export.Finish(err, func(err error) {
+		respondError(w, err, "Erro ao exportar relatório: ")
+	})
+}
+
+// exportRevenueReport gera a planilha do faturamento: as colunas agrupadas, horas, horas sem
+// valor-hora e o valor.
+func exportRevenueReport(w http.ResponseWriter, r *http.Request, format string, report *models.RevenueReport) {
+	columns, values := reportDimensions(report.Period, report.GroupBy)
+	columns = append(columns,
+		utils.ExportColumn{Header: "Appointments", HeaderPT: "Apontamentos", Kind: utils.CellInteger},
+		utils.ExportColumn{Header: "Hours", HeaderPT: "Horas", Kind: utils.CellNumber},
+		utils.ExportColumn{Header: "Unrated hours", HeaderPT: "Horas sem valor-hora", Kind: utils.CellNumber},
+		utils.ExportColumn{Header: "Amount", HeaderPT: "Valor", Kind: utils.CellNumber},
+	)
+
+	export := newTableExport(w, r, format, "relatorio-faturamento", columns)
+	var err error
+	for _, row := range report.Rows {
+		cells := make([]any, 0, len(columns))
+		for _, value := range values {
+			cells = append(cells, value(&row.HoursReportRow))
+		}
+		cells = append(cells, row.AppointmentCount, row.TotalHours, row.UnratedHours, row.Amount)
+		if err = export.WriteRow(cells...); err != nil {
+			break
+		}
+	}
+	export.Finish(err, func(err error) {
+		respondError(w, err, "Erro ao exportar relatório: ")
+	})
+}
+
+// SLAReport godoc
+// @Summary      Conformidade de SLA do contrato
+// @Description  Por mês de abertura dos chamados: % respondidos e % resolvidos dentro do prazo, entre os chamados com resultado conhecido.
+// @Tags         contracts
+// @Produce      json
+// @Param        id    path  int    true  "ID do Contrato"
+// @Param        from  query string false "Mês inicial (2026-01), inclusivo"
+// @Param        to    query string false "Mês final (2026-06), inclusivo"
+// @Param        format query string false "Exporta como csv ou xlsx (também via header Accept)"
+// @Param        locale query string false "Idioma da exportação: en (padrão) ou pt-BR"
+// @Success      200  {object}  models.SLAReport
+// @Failure      400  {string}  string "Parâmetros inválidos"
+// @Failure      404  {string}  string "Contrato não encontrado"
+// @Router       /api/contracts/{id}/sla-report [get]
+func (h *ReportHandler) SLAReport(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	report := &models.SLAReport{ContractID: id, Months: []*models.SLAReportRow{}}
+	var to *time.Time // Exclusivo (início do mês seguinte)
+	if v := r.URL.Query().Get("from"); v != "" {
+		from, err := time.Parse("2006-01", v)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "from inválido (use AAAA-MM)")
+			return
+		}
+		report.From = &from
+	}
+	if v := r.URL.Query().Get("to"); v != "" {
+		month, err := time.Parse("2006-01", v)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "to inválido (use AAAA-MM)")
+			return
+		}
+		end := month.AddDate(0, 1, 0)
+		report.To, to = &month, &end
+	}
+
+	contracts, err := h.contractRepo.Get(r.Context(), &id)
+	if err != nil {
+		respondError(w, err, "Erro ao buscar contrato: ")
+		return
+	}
+	if len(contracts) == 0 {
+		utils.RespondWithError(w, http.StatusNotFound, "Contrato não encontrado")
+		return
+	}
+	report.SLAPolicyID = contracts[0].SLAPolicyID
+
+	rows, err := h.repo.SLAReport(r.Context(), id, report.From, to, time.Now())
+	if err != nil {
+		respondError(w, err, "Erro ao gerar relatório de SLA: ")
+		return
+	}
+	for _, row := range rows {
+		row.FillCompliance()
+		report.Months = append(report.Months, row)
+		report.Total.Tickets += row.Tickets
+		report.Total.ResponseMeasured += row.ResponseMeasured
+		report.Total.ResponseMet += row.ResponseMet
+		report.Total.ResolutionMeasured += row.ResolutionMeasured
+		report.Total.ResolutionMet += row.ResolutionMet
+	}
+	report.Total.FillCompliance()
+
+	if format := utils.ExportFormatFromRequest(r); format != "" {
+		exportSLAReport(w, r, format, report)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, report)
+}
+
+// slaReportColumns são as colunas da planilha de conformidade de SLA.
+var slaReportColumns = []utils.ExportColumn{
+	{Header: "Month", HeaderPT: "Mês", Kind: utils.CellDate},
+	{Header: "Tickets", HeaderPT: "Chamados", Kind: utils.CellInteger},
+	{Header: "Responses measured", HeaderPT: "Respostas medidas", Kind: utils.CellInteger},
+	{Header: "Responses on time", HeaderPT: "Respostas no prazo", Kind: utils.CellInteger},
+	{Header: "Response compliance (%)", HeaderPT: "Conformidade de resposta (%)", Kind: utils.CellNumber},
+	{Header: "Resolutions measured", HeaderPT: "Resoluções medidas", Kind: utils.CellInteger},
+	{Header: "Resolutions on time", HeaderPT: "Resoluções no prazo", Kind: utils.CellInteger},
+	{Header: "Resolution compliance (%)", HeaderPT: "Conformidade de resolução (%)", Kind: utils.CellNumber},
+}
+
+// exportSLAReport gera a planilha com uma linha por mês e, no fim, a do total do período.
+func exportSLAReport(w http.ResponseWriter, r *http.Request, format string, report *models.SLAReport) {
+	export := newTableExport(w, r, format, "sla-contrato-"+strconv.FormatInt(report.ContractID, 10), slaReportColumns)
+	rows := append(slices.Clone(report.Months), &report.Total)
+	var err error
+	for i, row := range rows {
+		var month any = row.Month
+		if i == len(rows)-1 {
+			month = "Total"
+		}
+		err = export.WriteRow(month, row.Tickets, row.ResponseMeasured, row.ResponseMet, percentCell(row.ResponseCompliance),
+			row.ResolutionMeasured, row.ResolutionMet, percentCell(row.ResolutionCompliance))
+		if err != nil {
+			break
+		}
+	}
+	export.Finish(err, func(err error) {
+		respondError(w, err, "Erro ao exportar relatório de SLA: ")
+	})
+}
+
+// percentCell devolve o percentual para a planilha; sem chamados medidos, a célula fica vazia.
+func percentCell(p *float64) any {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+// parseHoursReportQuery valida a query string do relatório de horas.
+// O erro devolvido já é a mensagem para o cliente.
+func parseHoursReportQuery(r *http.Request) (repository.HoursReportQuery, *models.HoursReport, error) {
+	values := r.URL.Query()
+	query := repository.HoursReportQuery{Period: values.Get("period")}
+	report := &models.HoursReport{Period: query.Period, GroupBy: []string{}}
+
+	if v := values.Get("groupBy"); v != "" {
+		for _, dim := range strings.Split(v, ",") {
+			if !slices.Contains(repository.ReportDimensions, dim) {
+				return query, nil, errors.New("groupBy inválido: use " + strings.Join(repository.ReportDimensions, ", "))
+			}
+			if !slices.Contains(query.GroupBy, dim) {
+				query.GroupBy = append(query.GroupBy, dim)
+			}
+		}
+	}
+	report.GroupBy = append(report.GroupBy, query.GroupBy...)
+
+	if query.Period != "" && !slices.Contains(repository.ReportPeriods, query.Period) {
+		return query, nil, errors.New("period inválido: use " + strings.Join(repository.ReportPeriods, ", "))
+	}
+
+	if v := values.Get("from"); v != "" {
+		from, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			return query, nil, errors.New("from inválido (use AAAA-MM-DD)")
+		}
+		query.From, report.From = &from, &from
+	}
+	if v := values.Get("to"); v != "" {
+		to, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			return query, nil, errors.New("to inválido (use AAAA-MM-DD)")
+		}
+		// "to" é inclusivo para o cliente; no banco vira "antes do dia seguinte"
+		end := to.AddDate(0, 0, 1)
+		query.To, report.To = &end, &to
+	}
+	if query.From != nil && query.To != nil && !query.From.Before(*query.To) {
+		return query, nil, errors.New("from deve ser anterior ou igual a to")
+	}
+
+	if v := values.Get("approvalStatus"); v != "" {
+		if !slices.Contains(models.ApprovalStatuses, v) {
+			return query, nil, errors.New("approvalStatus inválido: use " + strings.Join(models.ApprovalStatuses, ", "))
+		}
+		query.ApprovalStatus = v
+	}
+
+	for param, dest := range map[string]*int64{
+		"companyId":  &query.CompanyID,
+		"contractId": &query.ContractID,
+		"userId":     &query.UserID,
+		"ticketId":   &query.TicketID,
+	} {
+		if v := values.Get(param); v != "" {
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return query, nil, errors.New(param + " inválido")
+			}
+			*dest = id
+		}
+	}
+	return query, report, nil
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"nexus/internal/models"
+	"nexus/internal/repository"
+	"nexus/internal/sla"
+)
+
+// SLAPolicyHandler lida com as políticas de SLA.
+type SLAPolicyHandler struct {
+	*BaseHandler[*models.SLAPolicy]
+}
+
+// NewSLAPolicyHandler cria o handler de políticas, validando create/update.
+func NewSLAPolicyHandler(repo repository.Repository[*models.SLAPolicy]) *SLAPolicyHandler {
+	handler := &SLAPolicyHandler{BaseHandler: NewBaseHandler(repo, "sla-policies")}
+	handler.Validate = sla.ValidatePolicy
+	return handler
+}
+
+// BusinessCalendarHandler lida com os calendários de horário comercial.
+type BusinessCalendarHandler struct {
+	*BaseHandler[*models.BusinessCalendar]
+}
+
+// NewBusinessCalendarHandler cria o handler de calendários, validando create/update.
+func NewBusinessCalendarHandler(repo repository.Repository[*models.BusinessCalendar]) *BusinessCalendarHandler {
+	handler := &BusinessCalendarHandler{BaseHandler: NewBaseHandler(repo, "business-calendars")}
+	handler.Validate = sla.ValidateCalendar
+	return handler
+}
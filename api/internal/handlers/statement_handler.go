@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"time"
+
+	"nexus/internal/models"
+	"nexus/internal/notify"
+	"nexus/internal/pdf"
+	"nexus/internal/repository"
+	"nexus/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// StatementHandler gera os extratos mensais enviados aos clientes.
+type StatementHandler struct {
+	contractRepo    repository.ContractRepository
+	companyRepo     repository.CompanyRepository
+	appointmentRepo repository.AppointmentRepository
+	reportRepo      repository.ReportRepository
+	recipients      *notify.Router
+}
+
+// NewStatementHandler cria um novo handler de extratos.
+func NewStatementHandler(
+	contractRepo repository.ContractRepository,
+	companyRepo repository.CompanyRepository,
+	appointmentRepo repository.AppointmentRepository,
+	reportRepo repository.ReportRepository,
+	recipients *notify.Router,
+) *StatementHandler {
+	return &StatementHandler{
+		contractRepo:    contractRepo,
+		companyRepo:     companyRepo,
+		appointmentRepo: appointmentRepo,
+		reportRepo:      reportRepo,
+		recipients:      recipients,
+	}
+}
+
+// ContractStatementPDF godoc
+// @Summary      Extrato mensal do contrato em PDF
+// @Description  Documento de fechamento para o cliente, endereçado aos gestores que aprovam: empresa e CNPJ, contrato, apontamentos do mês e totais contra as horas contratadas (no banco de horas, o saldo do período que contém o fim do mês)
+// @Tags         contracts
+// @Produce      application/pdf
+// @Param        id     path  int    true  "ID do Contrato"
+// @Param        month  query string false "Mês no formato 2026-09 (padrão: mês atual)"
+// @Success      200  {file}    file
+// @Failure      400  {string}  string "Mês inválido"
+// @Failure      404  {string}  string "Contrato não encontrado"
+// @Router       /api/contracts/{id}/statement.pdf [get]
+func (h *StatementHandler) ContractStatementPDF(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	now := time.Now()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if v := r.URL.Query().Get("month"); v != "" {
+		if month, err = time.Parse("2006-01", v); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Mês inválido: use o formato AAAA-MM (ex.: 2026-09)")
+			return
+		}
+	}
+	nextMonth := month.AddDate(0, 1, 0)
+
+	contracts, err := h.contractRepo.Get(r.Context(), &id)
+	if err != nil {
+		respondError(w, err, "Erro ao buscar contrato: ")
+		return
+	}
+	if len(contracts) == 0 {
+		utils.RespondWithError(w, http.StatusNotFound, "Contrato não encontrado")
+		return
+	}
+	statement := &models.ContractStatement{
+		Contract:     contracts[0],
+		Month:        month,
+		Appointments: []*models.Appointment{},
+		GeneratedAt:  now,
+	}
+
+	companies, err := h.companyRepo.Get(r.Context(), &statement.Contract.CompanyId)
+	if err != nil {
+		respondError(w, err, "Erro ao buscar empresa: ")
+		return
+	}
+	if len(companies) > 0 {
+		statement.Company = companies[0]
+	}
+	if statement.Approvers, err = h.recipients.Recipients(r.Context(), statement.Contract.CompanyId, models.ContactApprover); err != nil {
+		respondError(w, err, "Erro ao buscar destinatários: ")
+		return
+	}
+
+	// Apontamentos do mês (to com data pura inclui o último dia inteiro)
+	query := repository.ListQuery{
+		Sort:    []string{"startTime"},
+		Filters: map[string]string{"contractId": strconv.FormatInt(id, 10)},
+		From:    month.Format(time.DateOnly),
+		To:      nextMonth.AddDate(0, 0, -1).Format(time.DateOnly),
+	}
+	err = h.appointmentRepo.EachWithContract(r.Context(), query, func(a *models.Appointment) error {
+		statement.Appointments = append(statement.Appointments, a)
+		statement.MonthHours += a.TotalHours
+		return nil
+	})
+	if err != nil {
+		respondError(w, err, "Erro ao buscar apontamentos: ")
+		return
+	}
+
+	// Consumo acumulado do contrato até o fim do mês
+	rows, err := h.reportRepo.HoursReport(r.Context(), repository.HoursReportQuery{ContractID: id, To: &nextMonth})
+	if err != nil {
+		respondError(w, err, "Erro ao calcular consumo: ")
+		return
+	}
+	for _, row := range rows {
+		statement.ConsumedHours += row.TotalHours
+	}
+	if statement.Contract.ContractType == models.ContractHourBank {
+		if statement.Balance, err = h.contractRepo.GetBalance(r.Context(), id, nextMonth.AddDate(0, 0, -1)); err != nil {
+			respondError(w, err, "Erro ao calcular saldo: ")
+			return
+		}
+	}
+
+	// Gera em memória para ainda poder responder com erro se algo falhar
+	var buf bytes.Buffer
+	if err := pdf.RenderStatement(&buf, statement); err != nil {
+		respondError(w, err, "Erro ao gerar PDF: ")
+		return
+	}
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", `attachment; filename="extrato-`+strconv.FormatInt(id, 10)+"-"+month.Format("2006-01")+`.pdf"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
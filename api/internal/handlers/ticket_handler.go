@@ -0,0 +1,334 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"nexus/internal/audit"
+	"nexus/internal/auth"
+	"nexus/internal/models"
+	"nexus/internal/notify"
+	"nexus/internal/repository"
+	"nexus/internal/sla"
+	"nexus/internal/utils"
+)
+
+// TicketHandler lida com as requisições para Chamados.
+type TicketHandler struct {
+	*BaseHandler[*models.Ticket]
+	repo         repository.TicketRepository
+	contractRepo repository.ContractRepository
+	sla          *sla.Service
+	recipients   *notify.Router
+}
+
+// NewTicketHandler cria um novo handler de chamados, sobrescrevendo os handlers.
+// Abertura, espera pelo cliente e resolução avisam os contatos técnicos da empresa e o solicitante.
+func NewTicketHandler(repo repository.TicketRepository, contractRepo repository.ContractRepository, slaService *sla.Service, recipients *notify.Router) *TicketHandler {
+	baseHandler := NewBaseHandler(repo, "tickets")
+	handler := &TicketHandler{
+		BaseHandler:  baseHandler,
+		repo:         repo,
+		contractRepo: contractRepo,
+		sla:          slaService,
+		recipients:   recipients,
+	}
+	handler.CreateHandler = handler.CreateTicketHandler
+	handler.UpdateHandler = handler.UpdateTicketHandler
+	handler.GetAllHandler = handler.ListTickets
+	handler.GetByIDHandler = handler.GetTicket
+
+	// Consultor altera os chamados atribuídos a ele ou ainda sem responsável
+	handler.WritePolicy = func(user *models.User, ticket *models.Ticket) bool {
+		return auth.IsAdmin(user) || ticket.AssigneeID == nil || *ticket.AssigneeID == user.ID
+	}
+	return handler
+}
+
+// MÉTODOS BASE CUSTOMIZADOS - Apontar para o Handler
+
+// CreateTicket godoc
+// @Summary      Abre um chamado
+// @Description  Cria um chamado para uma empresa (contrato opcional, da mesma empresa). Status inicial: open. Os prazos de SLA saem da política do contrato.
+// @Tags         tickets
+// @Accept       json
+// @Produce      json
+// @Param        ticket body models.Ticket true "Dados do Chamado"
+// @Success      201  {object}  models.Ticket
+// @Failure      400  {string}  string "Erro de validação"
+// @Router       /api/tickets [post]
+func (h *TicketHandler) CreateTicketHandler(w http.ResponseWriter, r *http.Request) {
+	ticket, ok := h.decode(w, r)
+	if !ok {
+		return
+	}
+
+	// Status só muda pelas rotas de transição
+	now := time.Now()
+	ticket.Status = models.TicketOpen
+	ticket.CreatedAt = now
+	ticket.UpdatedAt = now
+	ticket.ResolvedAt = nil
+	ticket.ClosedAt = nil
+	ticket.FirstResponseAt = nil
+	if !h.validate(w, r, ticket) {
+		return
+	}
+	if err := h.sla.Schedule(r.Context(), ticket); err != nil {
+		respondError(w, err, "Erro ao calcular SLA: ")
+		return
+	}
+
+	saved, err := h.save(r, ticket)
+	if err != nil {
+		respondError(w, err, "Erro ao salvar chamado: ")
+		return
+	}
+	h.notifyClient(r, saved, "aberto")
+	utils.RespondWithJSON(w, http.StatusCreated, saved)
+}
+
+// UpdateTicket godoc
+// @Summary      Atualiza um chamado
+// @Description  Altera dados do chamado. O status é ignorado aqui: use as rotas de transição.
+// @Tags         tickets
+// @Accept       json
+// @Produce      json
+// @Param        id     path int           true "ID do Chamado"
+// @Param        ticket body models.Ticket true "Dados do Chamado"
+// @Success      200  {object}  models.Ticket
+// @Failure      400  {string}  string "Erro de validação"
+// @Failure      404  {string}  string "Chamado não encontrado"
+// @Router       /api/tickets/{id} [put]
+func (h *TicketHandler) UpdateTicketHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := h.parseID(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+	ticket, ok := h.decode(w, r)
+	if !ok {
+		return
+	}
+	ticket.SetID(id)
+
+	existing, err := h.repo.Get(r.Context(), &id)
+	if err != nil {
+		respondError(w, err, "Erro ao buscar chamado: ")
+		return
+	}
+	if len(existing) == 0 {
+		utils.RespondWithError(w, http.StatusNotFound, "Chamado não encontrado")
+		return
+	}
+	if !h.WritePolicy.allows(auth.UserFromContext(r.Context()), existing[0]) {
+		utils.RespondWithError(w, http.StatusForbidden, "Você não tem permissão para alterar este registro")
+		return
+	}
+
+	// Campos controlados pelo servidor
+	previous := existing[0]
+	ticket.Status = previous.Status
+	ticket.CreatedAt = previous.CreatedAt
+	ticket.ResolvedAt = previous.ResolvedAt
+	ticket.ClosedAt = previous.ClosedAt
+	ticket.FirstResponseAt = previous.FirstResponseAt
+	ticket.UpdatedAt = time.Now()
+	if !h.validate(w, r, ticket) {
+		return
+	}
+
+	// Prazos só são recalculados (desde a abertura) se mudar a prioridade ou o contrato
+	ticket.ResponseDueAt, ticket.ResponseRiskAt = previous.ResponseDueAt, previous.ResponseRiskAt
+	ticket.ResolutionDueAt, ticket.ResolutionRiskAt = previous.ResolutionDueAt, previous.ResolutionRiskAt
+	ticket.SLAStatus = previous.SLAStatus
+	if ticket.Priority != previous.Priority || !sameID(ticket.ContractID, previous.ContractID) {
+		if err := h.sla.Schedule(r.Context(), ticket); err != nil {
+			respondError(w, err, "Erro ao calcular SLA: ")
+			return
+		}
+	}
+
+	rowsAffected, err := h.update(r, ticket)
+	if err != nil {
+		respondError(w, err, "Erro ao atualizar chamado: ")
+		return
+	}
+	if rowsAffected == 0 {
+		utils.RespondWithError(w, http.StatusNotFound, "Chamado não encontrado")
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, ticket)
+}
+
+// ListTickets godoc
+// @Summary      Lista chamados
+// @Description  Chamados com empresa, contrato, responsável e horas gastas. Aceita paginação, ordenação e filtros (companyId, contractId, assigneeId, status, priority, from/to sobre createdAt).
+// @Tags         tickets
+// @Produce      json
+// @Param        page       query int    false "Página (começa em 1)"
+// @Param        pageSize   query int    false "Itens por página (padrão 50, máx. 200)"
+// @Param        sort       query string false "Ex.: -createdAt,priority"
+// @Param        status     query string false "open, in_progress, waiting_client, resolved, closed"
+// @Param        assigneeId query int    false "Filtra por responsável"
+// @Success      200  {array}  models.Ticket
+// @Router       /api/tickets [get]
+func (h *TicketHandler) ListTickets(w http.ResponseWriter, r *http.Request) {
+	query, err := parseListQuery(r)
+	if err != nil {
+		respondError(w, err, "")
+		return
+	}
+	page, err := h.repo.GetAllWithDetails(r.Context(), query)
+	if err != nil {
+		respondError(w, err, "Erro ao buscar chamados: ")
+		return
+	}
+	respondWithPage(w, r, page, h.filterReadable(r, page.Items))
+}
+
+// GetTicket godoc
+// @Summary      Detalhes do chamado
+// @Description  Retorna o chamado com o total de horas apontadas nele.
+// @Tags         tickets
+// @Produce      json
+// @Param        id   path      int  true  "ID do Chamado"
+// @Success      200  {object}  models.Ticket
+// @Failure      404  {string}  string "Chamado não encontrado"
+// @Router       /api/tickets/{id} [get]
+func (h *TicketHandler) GetTicket(w http.ResponseWriter, r *http.Request) {
+	id, err := h.parseID(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+	ticket, err := h.repo.GetWithDetails(r.Context(), id)
+	if err != nil {
+		respondError(w, err, "Erro ao buscar chamado: ")
+		return
+	}
+	if ticket == nil || !h.ReadPolicy.allows(auth.UserFromContext(r.Context()), ticket) {
+		utils.RespondWithError(w, http.StatusNotFound, "Chamado não encontrado")
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, ticket)
+}
+
+// MÉTODOS ESPECÍFICOS - Apontar para o router
+
+// Transition devolve o handler de uma rota de transição de status (start, wait, resolve, close, reopen).
+// Ao iniciar o atendimento, um chamado sem responsável fica com o usuário logado.
+// @Summary      Muda o status do chamado
+// @Description  start → in_progress, wait → waiting_client, resolve → resolved, close → closed, reopen → open. Responde 409 se o status atual não permitir a transição.
+// @Tags         tickets
+// @Produce      json
+// @Param        id   path      int  true  "ID do Chamado"
+// @Success      200  {object}  models.Ticket
+// @Failure      404  {string}  string "Chamado não encontrado"
+// @Failure      409  {string}  string "Transição de status inválida"
+// @Router       /api/tickets/{id}/start [post]
+// @Router       /api/tickets/{id}/wait [post]
+// @Router       /api/tickets/{id}/resolve [post]
+// @Router       /api/tickets/{id}/close [post]
+// @Router       /api/tickets/{id}/reopen [post]
+func (h *TicketHandler) Transition(status string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := h.parseID(r)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "ID inválido")
+			return
+		}
+		if !h.authorizeWrite(w, r, id) {
+			return
+		}
+
+		current, err := h.repo.Get(r.Context(), &id)
+		if err != nil {
+			respondError(w, err, "Erro ao buscar chamado: ")
+			return
+		}
+		var before *models.Ticket
+		if len(current) > 0 {
+			before = current[0]
+		}
+
+		var assigneeID *int64
+		if status == models.TicketInProgress && before != nil && before.AssigneeID == nil {
+			assigneeID = &auth.UserFromContext(r.Context()).ID
+		}
+
+		ticket, err := h.repo.Transition(r.Context(), id, status, assigneeID, time.Now())
+		if errors.Is(err, repository.ErrInvalidTransition) {
+			utils.RespondWithError(w, http.StatusConflict, "O chamado está "+ticket.Status+
+				" e não pode ir para "+status+" (aceito a partir de: "+strings.Join(models.TicketTransitionsTo(status), ", ")+")")
+			return
+		}
+		if err != nil {
+			respondError(w, err, "Erro ao alterar status do chamado: ")
+			return
+		}
+		if ticket == nil {
+			utils.RespondWithError(w, http.StatusNotFound, "Chamado não encontrado")
+			return
+		}
+		audit.Record(r.Context(), h.repo.GetTableName(), models.AuditUpdate, id, before, ticket)
+		switch status {
+		case models.TicketWaitingClient:
+			h.notifyClient(r, ticket, "aguardando retorno do cliente")
+		case models.TicketResolved:
+			h.notifyClient(r, ticket, "resolvido")
+		}
+		utils.RespondWithJSON(w, http.StatusOK, ticket)
+	}
+}
+
+// notifyClient avisa os contatos técnicos da empresa e o solicitante do chamado.
+// Falha no envio não desfaz a operação: só vai para o log.
+func (h *TicketHandler) notifyClient(r *http.Request, ticket *models.Ticket, event string) {
+	msg := notify.Message{
+		To:      []string{ticket.RequesterEmail},
+		Subject: fmt.Sprintf("Chamado #%d %s: %s", ticket.ID, event, ticket.Title),
+	}
+	if err := h.recipients.Send(r.Context(), ticket.CompanyID, models.ContactTechnical, msg); err != nil {
+		log.Printf("Erro ao notificar o chamado %d: %v", ticket.ID, err)
+	}
+}
+
+// validate preenche a prioridade padrão e confere se o contrato (opcional) é da empresa do
+// chamado; título, empresa e prioridade válida já passaram pelas tags em decode.
+// Já responde ao cliente e devolve false quando o chamado é inválido.
+func (h *TicketHandler) validate(w http.ResponseWriter, r *http.Request, ticket *models.Ticket) bool {
+	if ticket.Priority == "" {
+		ticket.Priority = models.PriorityMedium
+	}
+
+	if ticket.ContractID == nil {
+		return true
+	}
+	contracts, err := h.contractRepo.Get(r.Context(), ticket.ContractID)
+	if err != nil {
+		respondError(w, err, "Erro ao buscar contrato: ")
+		return false
+	}
+	if len(contracts) == 0 {
+		utils.RespondWithError(w, http.StatusBadRequest, "Contrato não encontrado")
+		return false
+	}
+	if contracts[0].CompanyId != ticket.CompanyID {
+		utils.RespondWithError(w, http.StatusBadRequest, "O contrato informado não pertence à empresa do chamado")
+		return false
+	}
+	return true
+}
+
+// sameID compara dois IDs opcionais.
+func sameID(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
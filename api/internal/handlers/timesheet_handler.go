@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"nexus/internal/audit"
+	"nexus/internal/auth"
+	"nexus/internal/models"
+	"nexus/internal/repository"
+	"nexus/internal/utils"
+)
+
+// TimesheetHandler lida com o envio e a aprovação das folhas de horas semanais.
+type TimesheetHandler struct {
+	*BaseHandler[*models.Timesheet]
+	repo repository.TimesheetRepository
+}
+
+// NewTimesheetHandler cria um novo handler de folhas de horas.
+func NewTimesheetHandler(repo repository.TimesheetRepository) *TimesheetHandler {
+	baseHandler := NewBaseHandler(repo, "timesheets")
+	handler := &TimesheetHandler{
+		BaseHandler: baseHandler,
+		repo:        repo,
+	}
+	handler.CreateHandler = handler.SubmitTimesheet
+	handler.GetAllHandler = handler.ListTimesheets
+	handler.GetByIDHandler = handler.GetTimesheet
+
+	// Consultor só enxerga as próprias semanas
+	handler.ReadPolicy = ownTimesheet
+	handler.ReadFilter = ownTimesheetFilter
+	return handler
+}
+
+// MÉTODOS BASE CUSTOMIZADOS - Apontar para o Handler
+
+// SubmitTimesheet godoc
+// @Summary      Envia a semana para aprovação
+// @Description  Envia os apontamentos da semana (segunda a domingo) do consultor. Uma semana recusada pode ser reenviada. Enquanto enviada, o consultor não altera os apontamentos dela.
+// @Tags         timesheets
+// @Accept       json
+// @Produce      json
+// @Param        timesheet body models.SubmitTimesheetRequest true "Qualquer dia da semana (userId opcional, só admin)"
+// @Success      201  {object}  models.Timesheet
+// @Failure      400  {string}  string "Erro de validação"
+// @Failure      409  {string}  string "Semana já enviada/aprovada ou com apontamento em andamento"
+// @Router       /api/timesheets [post]
+func (h *TimesheetHandler) SubmitTimesheet(w http.ResponseWriter, r *http.Request) {
+	var req models.SubmitTimesheetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "JSON inválido")
+		return
+	}
+	day, err := time.Parse(time.DateOnly, req.WeekStart)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "weekStart inválido (use AAAA-MM-DD)")
+		return
+	}
+	week := models.WeekStart(day)
+
+	currentUser := auth.UserFromContext(r.Context())
+	if req.UserID == 0 {
+		req.UserID = currentUser.ID
+	} else if req.UserID != currentUser.ID && !auth.IsAdmin(currentUser) {
+		utils.RespondWithError(w, http.StatusForbidden, "Você não pode enviar a semana de outro usuário")
+		return
+	}
+
+	before, err := h.weekTimesheet(r.Context(), req.UserID, week)
+	if err != nil {
+		respondError(w, err, "Erro ao buscar folha de horas: ")
+		return
+	}
+	timesheet, err := h.repo.Submit(r.Context(), req.UserID, week, time.Now())
+	switch {
+	case errors.Is(err, repository.ErrTimesheetLocked):
+		utils.RespondWithError(w, http.StatusConflict, "A semana de "+week.Format("02/01/2006")+" já foi enviada ou aprovada")
+		return
+	case errors.Is(err, repository.ErrTimesheetRunning):
+		utils.RespondWithError(w, http.StatusConflict, "Pare o cronômetro em andamento antes de enviar a semana")
+		return
+	case err != nil:
+		respondError(w, err, "Erro ao enviar folha de horas: ")
+		return
+	case timesheet == nil:
+		utils.RespondWithError(w, http.StatusBadRequest, "Usuário não encontrado")
+		return
+	}
+	if before == nil {
+		audit.Record(r.Context(), h.repo.GetTableName(), models.AuditCreate, timesheet.ID, nil, timesheet)
+	} else {
+		audit.Record(r.Context(), h.repo.GetTableName(), models.AuditUpdate, timesheet.ID, before, timesheet)
+	}
+	utils.RespondWithJSON(w, http.StatusCreated, timesheet)
+}
+
+// weekTimesheet busca a folha da semana do usuário (nil se ainda não foi enviada).
+func (h *TimesheetHandler) weekTimesheet(ctx context.Context, userID int64, week time.Time) (*models.Timesheet, error) {
+	page, err := h.repo.GetAllWithDetails(ctx, repository.ListQuery{
+		PageSize: 1,
+		Filters: map[string]string{
+			"userId":    strconv.FormatInt(userID, 10),
+			"weekStart": week.Format(time.DateOnly),
+		},
+	})
+	if err != nil || len(page.Items) == 0 {
+		return nil, err
+	}
+	return page.Items[0], nil
+}
+
+// ListTimesheets godoc
+// @Summary      Lista folhas de horas
+// @Description  Semanas enviadas com consultor, revisor e horas lançadas. Consultor vê só as próprias. Aceita paginação, ordenação e filtros (userId, status, from/to sobre weekStart).
+// @Tags         timesheets
+// @Produce      json
+// @Param        page     query int    false "Página (começa em 1)"
+// @Param        pageSize query int    false "Itens por página (padrão 50, máx. 200)"
+// @Param        sort     query string false "Ex.: -weekStart,userName"
+// @Param        status   query string false "submitted, approved ou rejected"
+// @Param        userId   query int    false "Filtra por consultor (admin)"
+// @Success      200  {array}  models.Timesheet
+// @Router       /api/timesheets [get]
+func (h *TimesheetHandler) ListTimesheets(w http.ResponseWriter, r *http.Request) {
+	query, err := parseListQuery(r)
+	if err != nil {
+		respondError(w, err, "")
+		return
+	}
+	h.restrictToReadable(r, &query)
+	page, err := h.repo.GetAllWithDetails(r.Context(), query)
+	if err != nil {
+		respondError(w, err, "Erro ao buscar folhas de horas: ")
+		return
+	}
+	respondWithPage(w, r, page, h.filterReadable(r, page.Items))
+}
+
+// GetTimesheet godoc
+// @Summary      Detalhes da folha de horas
+// @Description  Retorna a semana com o total de horas e de apontamentos.
+// @Tags         timesheets
+// @Produce      json
+// @Param        id   path      int  true  "ID da Folha de Horas"
+// @Success      200  {object}  models.Timesheet
+// @Failure      404  {string}  string "Folha de horas não encontrada"
+// @Router       /api/timesheets/{id} [get]
+func (h *TimesheetHandler) GetTimesheet(w http.ResponseWriter, r *http.Request) {
+	id, err := h.parseID(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+	timesheet, err := h.repo.GetWithDetails(r.Context(), id)
+	if err != nil {
+		respondError(w, err, "Erro ao buscar folha de horas: ")
+		return
+	}
+	if timesheet == nil || !h.ReadPolicy.allows(auth.UserFromContext(r.Context()), timesheet) {
+		utils.RespondWithError(w, http.StatusNotFound, "Folha de horas não encontrada")
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, timesheet)
+}
+
+// MÉTODOS ESPECÍFICOS - Apontar para o router
+
+// Review devolve o handler de aprovação (approved) ou recusa (rejected) de uma semana enviada.
+// A recusa exige comentário e devolve os apontamentos da semana para edição.
+// @Summary      Aprova ou recusa a semana
+// @Description  approve → approved (apontamentos travados), reject → rejected (comentário obrigatório; apontamentos liberados). Responde 409 se a semana não estiver enviada.
+// @Tags         timesheets
+// @Accept       json
+// @Produce      json
+// @Param        id     path int                           true  "ID da Folha de Horas"
+// @Param        review body models.ReviewTimesheetRequest false "Comentário"
+// @Success      200  {object}  models.Timesheet
+// @Failure      400  {string}  string "Comentário obrigatório na recusa"
+// @Failure      404  {string}  string "Folha de horas não encontrada"
+// @Failure      409  {string}  string "Semana não está aguardando aprovação"
+// @Router       /api/timesheets/{id}/approve [post]
+// @Router       /api/timesheets/{id}/reject [post]
+func (h *TimesheetHandler) Review(status string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := h.parseID(r)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "ID inválido")
+			return
+		}
+		var req models.ReviewTimesheetRequest
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				utils.RespondWithError(w, http.StatusBadRequest, "JSON inválido")
+				return
+			}
+		}
+		req.Comment = strings.TrimSpace(req.Comment)
+		if status == models.TimesheetRejected && req.Comment == "" {
+			utils.RespondWithError(w, http.StatusBadRequest, "Informe o motivo da recusa em comment")
+			return
+		}
+
+		before := h.current(r.Context(), id)
+		reviewer := auth.UserFromContext(r.Context())
+		timesheet, err := h.repo.Review(r.Context(), id, status, reviewer.ID, req.Comment, time.Now())
+		if errors.Is(err, repository.ErrInvalidTransition) {
+			utils.RespondWithError(w, http.StatusConflict, "A semana está "+timesheet.Status+" e não aguarda aprovação")
+			return
+		}
+		if err != nil {
+			respondError(w, err, "Erro ao revisar folha de horas: ")
+			return
+		}
+		if timesheet == nil {
+			utils.RespondWithError(w, http.StatusNotFound, "Folha de horas não encontrada")
+			return
+		}
+		audit.Record(r.Context(), h.repo.GetTableName(), models.AuditUpdate, id, before, timesheet)
+		utils.RespondWithJSON(w, http.StatusOK, timesheet)
+	}
+}
+
+// ownTimesheet é a política de leitura das folhas de horas: admin ou o próprio consultor.
+func ownTimesheet(user *models.User, timesheet *models.Timesheet) bool {
+	return auth.IsAdmin(user) || (user != nil && timesheet.UserID == user.ID)
+}
+
+// ownTimesheetFilter é o ownTimesheet das listagens: consultor só lista as próprias semanas.
+func ownTimesheetFilter(user *models.User) map[string]string {
+	if auth.IsAdmin(user) {
+		return nil
+	}
+	var userID int64 // sem usuário, nenhum registro
+	if user != nil {
+		userID = user.ID
+	}
+	return map[string]string{"userId": strconv.FormatInt(userID, 10)}
+}
@@ -6,23 +6,27 @@ import (
 
 	"nexus/internal/models"
 	"nexus/internal/repository"
+	"nexus/internal/usecase"
 	"nexus/internal/utils"
 )
 
 // UserHandler lida com as requisições para usuários.
 type UserHandler struct {
 	*BaseHandler[*models.User]
-	repo repository.UserRepository
+	repo    repository.UserRepository
+	usecase usecase.UserUsecase
 }
 
-// NewUserHandler cria um novo handler de usuários, sobrescrevendo o CreateHandler.
-func NewUserHandler(repo repository.UserRepository) *UserHandler {
+// NewUserHandler cria um novo handler de usuários, sobrescrevendo o CreateHandler e o UpdateHandler.
+func NewUserHandler(repo repository.UserRepository, userUsecase usecase.UserUsecase) *UserHandler {
 	baseHandler := NewBaseHandler(repo, "users")
 	handler := &UserHandler{
 		BaseHandler: baseHandler,
 		repo:        repo,
+		usecase:     userUsecase,
 	}
 	handler.CreateHandler = handler.createUserHandler
+	handler.UpdateHandler = handler.updateUserHandler
 	return handler
 }
 
@@ -36,26 +40,32 @@ func (h *UserHandler) createUserHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if user.Name == "" {
-		utils.RespondWithError(w, http.StatusBadRequest, "O nome do usuário não pode ser vazio")
+	savedUser, err := h.usecase.Create(r.Context(), user)
+	if err != nil {
+		respondError(w, err, "Erro ao criar usuário: ")
 		return
 	}
 
-	exists, err := h.repo.EmailExists(user.Email)
+	utils.RespondWithJSON(w, http.StatusCreated, savedUser)
+}
+
+// updateUserHandler atualiza um usuário. Se "password" vier vazio, mantém a senha atual.
+func (h *UserHandler) updateUserHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := h.parseID(r)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "Erro ao verificar e-mail")
+		utils.RespondWithError(w, http.StatusBadRequest, "ID inválido")
 		return
 	}
-	if exists {
-		utils.RespondWithError(w, http.StatusConflict, "E-mail já cadastrado")
+	user := h.newModel()
+	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Corpo da requisição inválido")
 		return
 	}
+	user.SetID(id)
 
-	savedUser, err := h.repo.Save(user)
-	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "Erro ao criar usuário")
+	if err := h.usecase.Update(r.Context(), user); err != nil {
+		respondError(w, err, "Erro ao atualizar usuário: ")
 		return
 	}
-
-	utils.RespondWithJSON(w, http.StatusCreated, savedUser)
+	utils.RespondWithJSON(w, http.StatusOK, user)
 }
@@ -0,0 +1,45 @@
+package lookup
+
+import (
+	"context"
+	"sync"
+
+	"nexus/internal/document"
+	"nexus/internal/models"
+)
+
+// Fake é um provedor em memória, para testes e desenvolvimento sem acesso ao serviço real.
+type Fake struct {
+	mu        sync.RWMutex
+	companies map[string]models.CompanyInfo
+}
+
+// NewFake cria o provedor com as empresas informadas (CNPJ com ou sem máscara).
+func NewFake(companies ...models.CompanyInfo) *Fake {
+	f := &Fake{companies: make(map[string]models.CompanyInfo)}
+	for _, info := range companies {
+		f.Add(info)
+	}
+	return f
+}
+
+// Add cadastra (ou substitui) uma empresa no provedor.
+func (f *Fake) Add(info models.CompanyInfo) {
+	info.CNPJ = document.NormalizeCNPJ(info.CNPJ)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.companies[info.CNPJ] = info
+}
+
+func (f *Fake) LookupCNPJ(ctx context.Context, cnpj string) (*models.CompanyInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	info, ok := f.companies[document.NormalizeCNPJ(cnpj)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &info, nil
+}
@@ -0,0 +1,60 @@
+package lookup
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"nexus/internal/models"
+)
+
+func TestFakeLookupCNPJ(t *testing.T) {
+	fake := NewFake(
+		models.CompanyInfo{CNPJ: "11.222.333/0001-81", Name: "Numérica Ltda", Address: models.Address{City: "São Paulo", State: "SP"}},
+		models.CompanyInfo{CNPJ: "12.abc.345/01de-35", Name: "Alfanumérica Ltda"},
+	)
+
+	tests := []struct {
+		name     string
+		cnpj     string
+		wantName string
+		wantErr  error
+	}{
+		{"sem máscara", "11222333000181", "Numérica Ltda", nil},
+		{"com máscara", "11.222.333/0001-81", "Numérica Ltda", nil},
+		{"alfanumérico em minúsculas", "12abc34501de35", "Alfanumérica Ltda", nil},
+		{"desconhecido", "12ABC34501DE35X", "", ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := fake.LookupCNPJ(context.Background(), tt.cnpj)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("LookupCNPJ(%q) erro = %v, esperado %v", tt.cnpj, err, tt.wantErr)
+			}
+			if err == nil && info.Name != tt.wantName {
+				t.Errorf("LookupCNPJ(%q).Name = %q, esperado %q", tt.cnpj, info.Name, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestFakeAddReplaces(t *testing.T) {
+	fake := NewFake(models.CompanyInfo{CNPJ: "11222333000181", Name: "Antiga"})
+	fake.Add(models.CompanyInfo{CNPJ: "11.222.333/0001-81", Name: "Nova"})
+
+	info, err := fake.LookupCNPJ(context.Background(), "11222333000181")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Name != "Nova" || info.CNPJ != "11222333000181" {
+		t.Errorf("depois do Add: %+v, esperado Nova com o CNPJ sem máscara", info)
+	}
+}
+
+func TestFakeCanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewFake().LookupCNPJ(ctx, "11222333000181"); !errors.Is(err, context.Canceled) {
+		t.Errorf("erro = %v, esperado context.Canceled", err)
+	}
+}
@@ -0,0 +1,19 @@
+// Package lookup consulta dados cadastrais de empresas pelo CNPJ (razão social e endereço)
+// em um provedor externo. O provedor é opcional: sem ele o cadastro segue manual.
+package lookup
+
+import (
+	"context"
+	"errors"
+
+	"nexus/internal/models"
+)
+
+// ErrNotFound indica que o provedor não conhece o CNPJ.
+var ErrNotFound = errors.New("CNPJ não encontrado no provedor")
+
+// Provider consulta um CNPJ já normalizado (sem máscara, ver document.NormalizeCNPJ).
+// Devolve ErrNotFound quando o CNPJ não existe no provedor.
+type Provider interface {
+	LookupCNPJ(ctx context.Context, cnpj string) (*models.CompanyInfo, error)
+}
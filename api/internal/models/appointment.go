@@ -4,19 +4,44 @@ import "time"
 
 type Appointment struct {
 	ID         int64 `json:"id" db:"id"`
-	ContractID int64 `json:"contractId" db:"contract_id"`
+	ContractID int64 `json:"contractId" db:"contract_id" validate:"required"`
 	UserID     int64 `json:"userId" db:"user_id"`
 
-	StartTime   time.Time  `json:"startTime" db:"start_time"`
+	// Chamado atendido (opcional); as horas entram no total do chamado
+	TicketID *int64 `json:"ticketId" db:"ticket_id"`
+
+	StartTime   time.Time  `json:"startTime" db:"start_time" validate:"required"`
 	EndTime     *time.Time `json:"endTime" db:"end_time"`
 	Description string     `json:"description" db:"description"`
 
+	// Liberado por admin (?allowOverlap=true) para trabalho paralelo; não entra na checagem de sobreposição
+	AllowOverlap bool `json:"allowOverlap" db:"allow_overlap"`
+
+	// Lançado além do saldo de um contrato com política "flag"
+	IsOverrun bool `json:"isOverrun" db:"is_overrun"`
+
+	// Item de fatura que cobrou este apontamento (null = ainda não faturado); controlado pelo servidor
+	InvoiceLineID *int64     `json:"invoiceLineId" db:"invoice_line_id"`
+	DeletedAt     *time.Time `json:"deletedAt,omitempty" db:"deleted_at"`
+
 	// Calculadas
-	ContractTitle   string    `json:"contractTitle,omitempty"` // Para mostrar "Ademicon" no grid
-	UserName        string    `json:"userName,omitempty"`      // Para mostrar "Lucas"
-	TotalHours      float64   `json:"totalHours"`              // Calculado (Fim - Início)
+	ApprovalStatus  string    `json:"approvalStatus,omitempty"` // Status da semana na folha de horas (draft se não enviada)
+	ContractTitle   string    `json:"contractTitle,omitempty"`  // Para mostrar "Ademicon" no grid
+	UserName        string    `json:"userName,omitempty"`       // Para mostrar "Lucas"
+	TotalHours      float64   `json:"totalHours"`               // Calculado (Fim - Início)
 	CreatedAt       time.Time `json:"createdAt" db:"created_at"`
 	DurationSeconds int64     `json:"durationSeconds"`
+	Warnings        []string  `json:"warnings,omitempty"` // Avisos da validação (ex.: saldo estourado)
+}
+
+// FillDuration preenche os campos calculados de duração (em andamento conta até "now").
+func (a *Appointment) FillDuration(now time.Time) {
+	end := now
+	if a.EndTime != nil {
+		end = *a.EndTime
+	}
+	a.DurationSeconds = int64(end.Sub(a.StartTime).Seconds())
+	a.TotalHours = end.Sub(a.StartTime).Hours()
 }
 
 func (a *Appointment) GetID() int64 {
@@ -26,3 +51,18 @@ func (a *Appointment) GetID() int64 {
 func (a *Appointment) SetID(id int64) {
 	a.ID = id
 }
+
+// StartTimerRequest é o corpo de POST /api/appointments/start.
+// UserID é opcional: por padrão o cronômetro é do usuário logado.
+type StartTimerRequest struct {
+	ContractID  int64  `json:"contractId"`
+	UserID      int64  `json:"userId,omitempty"`
+	TicketID    *int64 `json:"ticketId,omitempty"`
+	Description string `json:"description"`
+}
+
+// StartTimerResponse traz o apontamento iniciado e, se havia, o que foi parado.
+type StartTimerResponse struct {
+	Started *Appointment `json:"started"`
+	Stopped *Appointment `json:"stopped,omitempty"`
+}
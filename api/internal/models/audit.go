@@ -0,0 +1,44 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Operações registradas na auditoria
+const (
+	AuditCreate  = "create"
+	AuditUpdate  = "update"
+	AuditDelete  = "delete"  // Remoção lógica (deleted_at)
+	AuditRestore = "restore" // Desfaz a remoção lógica
+	AuditPurge   = "purge"   // Remoção definitiva de um registro já removido
+)
+
+// AuditEntry é uma linha da trilha de auditoria: quem (ActorID) fez o quê (Operation) em qual
+// registro (Entity + EntityID) e quando. Changes traz só os campos alterados, no formato
+// {"campo": {"old": ..., "new": ...}}; na criação old é null, na remoção new é null.
+type AuditEntry struct {
+	ID         int64           `json:"id" db:"id"`
+	ActorID    *int64          `json:"actorId" db:"actor_id"` // null em ações do sistema ou usuário removido
+	OccurredAt time.Time       `json:"occurredAt" db:"occurred_at"`
+	Entity     string          `json:"entity" db:"entity"` // Nome da tabela (ex.: appointments)
+	EntityID   int64           `json:"entityId" db:"entity_id"`
+	Operation  string          `json:"operation" db:"operation"`
+	Changes    json.RawMessage `json:"changes" db:"changes"`
+
+	ActorName string `json:"actorName,omitempty"` // Calculado (JOIN com users)
+}
+
+func (a *AuditEntry) GetID() int64 {
+	return a.ID
+}
+
+func (a *AuditEntry) SetID(id int64) {
+	a.ID = id
+}
+
+// AuditChange é o valor de um campo antes e depois da operação.
+type AuditChange struct {
+	Old json.RawMessage `json:"old"`
+	New json.RawMessage `json:"new"`
+}
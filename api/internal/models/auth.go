@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// LoginRequest é o corpo esperado em POST /api/auth/login.
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// RefreshRequest é o corpo esperado em POST /api/auth/refresh e /api/auth/logout.
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// TokenResponse é devolvido no login e no refresh.
+type TokenResponse struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+	TokenType    string `json:"tokenType"`
+	ExpiresIn    int64  `json:"expiresIn"` // Segundos até o access token expirar
+	User         *User  `json:"user"`
+}
+
+// RefreshToken representa um refresh token emitido (guardado para permitir revogação).
+type RefreshToken struct {
+	ID        string     `json:"id" db:"id"` // jti do token
+	UserID    int64      `json:"userId" db:"user_id"`
+	ExpiresAt time.Time  `json:"expiresAt" db:"expires_at"`
+	RevokedAt *time.Time `json:"revokedAt" db:"revoked_at"`
+	CreatedAt time.Time  `json:"createdAt" db:"created_at"`
+}
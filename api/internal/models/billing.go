@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// BillingRate é o valor-hora de um contrato a partir de uma data.
+// Sem UserID é o valor padrão do contrato; com UserID vale só para aquele consultor e tem precedência.
+// Valores monetários usam decimal exato (no JSON saem como string: "150.00").
+type BillingRate struct {
+	ID         int64           `json:"id" db:"id"`
+	ContractID int64           `json:"contractId" db:"contract_id" validate:"required"`
+	UserID     *int64          `json:"userId" db:"user_id"`
+	HourlyRate decimal.Decimal `json:"hourlyRate" db:"hourly_rate"`
+	ValidFrom  time.Time       `json:"validFrom" db:"valid_from" validate:"required"`
+	ValidTo    *time.Time      `json:"validTo" db:"valid_to"` // Inclusivo; null = sem fim
+	DeletedAt  *time.Time      `json:"deletedAt,omitempty" db:"deleted_at"`
+}
+
+func (b *BillingRate) GetID() int64 {
+	return b.ID
+}
+
+func (b *BillingRate) SetID(id int64) {
+	b.ID = id
+}
+
+// RevenueReportRow é uma linha do relatório de faturamento: as horas do grupo e o valor delas.
+// Só entram apontamentos finalizados. Horas sem valor-hora vigente ficam em UnratedHours e fora de Amount.
+type RevenueReportRow struct {
+	HoursReportRow
+	UnratedSeconds int64           `json:"unratedSeconds"`
+	UnratedHours   float64         `json:"unratedHours"`
+	Amount         decimal.Decimal `json:"amount"` // Arredondado em centavos
+}
+
+// RevenueReport é a resposta de GET /api/reports/revenue.
+// Amount é a soma dos valores já arredondados das linhas.
+type RevenueReport struct {
+	GroupBy         []string            `json:"groupBy"`
+	Period          string              `json:"period,omitempty"`
+	From            *time.Time          `json:"from,omitempty"`
+	To              *time.Time          `json:"to,omitempty"`
+	Rows            []*RevenueReportRow `json:"rows"`
+	DurationSeconds int64               `json:"durationSeconds"`
+	TotalHours      float64             `json:"totalHours"`
+	UnratedHours    float64             `json:"unratedHours"`
+	Amount          decimal.Decimal     `json:"amount"`
+}
@@ -1,10 +1,20 @@
 package models
 
+import (
+	"encoding/json"
+	"time"
+
+	"nexus/internal/document"
+)
+
+// Company é um cliente. O CNPJ é gravado sem máscara (ver document.NormalizeCNPJ)
+// e sai formatado no JSON; na entrada, aceita com ou sem máscara.
 type Company struct {
-	ID           int64  `json:"id" db:"id"`
-	Name         string `json:"name" db:"name"`
-	CNPJ         string `json:"cnpj" db:"cnpj"`
-	ContactEmail string `json:"email" db:"contact_email"`
+	ID           int64      `json:"id" db:"id"`
+	Name         string     `json:"name" db:"name" validate:"required,max=255"`
+	CNPJ         string     `json:"cnpj" db:"cnpj" validate:"required,max=18,cnpj"`
+	ContactEmail string     `json:"email" db:"contact_email" validate:"email,max=255"`
+	DeletedAt    *time.Time `json:"deletedAt,omitempty" db:"deleted_at"`
 }
 
 func (c *Company) GetID() int64 {
@@ -14,3 +24,34 @@ func (c *Company) GetID() int64 {
 func (c *Company) SetID(id int64) {
 	c.ID = id
 }
+
+// FormattedCNPJ é o CNPJ com máscara (00.000.000/0000-00), para exibição.
+func (c *Company) FormattedCNPJ() string {
+	return document.FormatCNPJ(c.CNPJ)
+}
+
+// MarshalJSON devolve o CNPJ com máscara.
+func (c Company) MarshalJSON() ([]byte, error) {
+	type plain Company
+	c.CNPJ = c.FormattedCNPJ()
+	return json.Marshal(plain(c))
+}
+
+// Address é um endereço postal brasileiro.
+type Address struct {
+	Street     string `json:"street"`
+	Number     string `json:"number"`
+	Complement string `json:"complement,omitempty"`
+	District   string `json:"district"`
+	City       string `json:"city"`
+	State      string `json:"state"` // UF, ex.: SP
+	ZipCode    string `json:"zipCode"`
+}
+
+// CompanyInfo são os dados cadastrais de um CNPJ devolvidos pelo provedor de consulta.
+type CompanyInfo struct {
+	CNPJ      string  `json:"cnpj"`
+	Name      string  `json:"name"`                // razão social
+	TradeName string  `json:"tradeName,omitempty"` // nome fantasia
+	Address   Address `json:"address"`
+}
@@ -0,0 +1,87 @@
+package models
+
+import "time"
+
+// Tipos de contato: cada envio da empresa vai para o tipo certo.
+const (
+	ContactBilling   = "billing"   // Financeiro: recebe as faturas
+	ContactTechnical = "technical" // Técnico: abre chamados e recebe as notificações deles
+	ContactApprover  = "approver"  // Gestor: aprova os extratos mensais
+)
+
+// ContactTypes são os tipos aceitos em ?type= e no roteamento dos envios.
+var ContactTypes = []string{ContactBilling, ContactTechnical, ContactApprover}
+
+// CompanyChild é um cadastro que pertence a uma empresa e tem um principal por empresa
+// (contatos e endereços). As rotas ficam em /api/companies/{companyID}/...
+type CompanyChild interface {
+	Model
+	GetCompanyID() int64
+	SetCompanyID(id int64)
+	IsPrimary() bool
+	SetPrimary(primary bool)
+}
+
+// CompanyContact é uma pessoa da empresa cliente. Um contato pode ter vários papéis.
+type CompanyContact struct {
+	ID        int64      `json:"id" db:"id"`
+	CompanyID int64      `json:"companyId" db:"company_id"`
+	Name      string     `json:"name" db:"name" validate:"required,max=255"`
+	Email     string     `json:"email" db:"email" validate:"required,email,max=255"`
+	Phone     string     `json:"phone" db:"phone" validate:"max=32"`
+	Billing   bool       `json:"billing" db:"is_billing"`
+	Technical bool       `json:"technical" db:"is_technical"`
+	Approver  bool       `json:"approver" db:"is_approver"`
+	Primary   bool       `json:"primary" db:"is_primary"`
+	DeletedAt *time.Time `json:"deletedAt,omitempty" db:"deleted_at"`
+}
+
+func (c *CompanyContact) GetID() int64            { return c.ID }
+func (c *CompanyContact) SetID(id int64)          { c.ID = id }
+func (c *CompanyContact) GetCompanyID() int64     { return c.CompanyID }
+func (c *CompanyContact) SetCompanyID(id int64)   { c.CompanyID = id }
+func (c *CompanyContact) IsPrimary() bool         { return c.Primary }
+func (c *CompanyContact) SetPrimary(primary bool) { c.Primary = primary }
+
+// Has indica se o contato tem o papel (ContactBilling, ContactTechnical ou ContactApprover).
+func (c *CompanyContact) Has(contactType string) bool {
+	switch contactType {
+	case ContactBilling:
+		return c.Billing
+	case ContactTechnical:
+		return c.Technical
+	case ContactApprover:
+		return c.Approver
+	}
+	return false
+}
+
+// CompanyAddress é um endereço de cobrança da empresa; o principal sai na fatura.
+type CompanyAddress struct {
+	ID         int64      `json:"id" db:"id"`
+	CompanyID  int64      `json:"companyId" db:"company_id"`
+	Street     string     `json:"street" db:"street" validate:"required,max=255"`
+	Number     string     `json:"number" db:"number" validate:"max=20"`
+	Complement string     `json:"complement" db:"complement" validate:"max=255"`
+	District   string     `json:"district" db:"district" validate:"max=255"`
+	City       string     `json:"city" db:"city" validate:"required,max=255"`
+	State      string     `json:"state" db:"state" validate:"required,min=2,max=2"` // UF, ex.: SP
+	ZipCode    string     `json:"zipCode" db:"zip_code" validate:"required,max=9"`
+	Primary    bool       `json:"primary" db:"is_primary"`
+	DeletedAt  *time.Time `json:"deletedAt,omitempty" db:"deleted_at"`
+}
+
+func (a *CompanyAddress) GetID() int64            { return a.ID }
+func (a *CompanyAddress) SetID(id int64)          { a.ID = id }
+func (a *CompanyAddress) GetCompanyID() int64     { return a.CompanyID }
+func (a *CompanyAddress) SetCompanyID(id int64)   { a.CompanyID = id }
+func (a *CompanyAddress) IsPrimary() bool         { return a.Primary }
+func (a *CompanyAddress) SetPrimary(primary bool) { a.Primary = primary }
+
+// Address devolve o endereço no formato comum (o mesmo da consulta de CNPJ).
+func (a *CompanyAddress) Address() Address {
+	return Address{
+		Street: a.Street, Number: a.Number, Complement: a.Complement,
+		District: a.District, City: a.City, State: a.State, ZipCode: a.ZipCode,
+	}
+}
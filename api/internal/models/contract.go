@@ -2,16 +2,53 @@ package models
 
 import "time"
 
+// Políticas para lançamentos em contrato com saldo de horas esgotado
+const (
+	OverrunReject = "reject" // Recusa o apontamento
+	OverrunWarn   = "warn"   // Aceita e devolve um aviso
+	OverrunFlag   = "flag"   // Aceita e marca o apontamento como excedente
+)
+
+// Tipos de contrato
+const (
+	ContractHourBank   = "hour_bank"   // Banco de horas mensal: TotalHours a cada período, contado a partir de StartDate
+	ContractFixedPrice = "fixed_price" // Projeto fechado: TotalHours é o orçamento da vigência inteira
+	ContractOnDemand   = "on_demand"   // Sob demanda: sem limite, faturado pelo uso
+)
+
+// ContractTypes são os tipos aceitos em ContractType.
+var ContractTypes = []string{ContractHourBank, ContractFixedPrice, ContractOnDemand}
+
 type Contract struct {
-	ID           int64     `json:"id" db:"id"`
-	CompanyId    int64     `json:"companyId" db:"company_id"`
-	CompanyName  string    `json:"companyName,omitempty" db:"company_name,omitempty"`
-	Title        string    `json:"title" db:"title"`
-	ContractType string    `json:"contractType" db:"contract_type"`
-	TotalHours   int       `json:"totalHours" db:"total_hours"`
-	StartDate    time.Time `json:"startDate" db:"start_date"`
-	EndDate      time.Time `json:"endDate" db:"end_date"`
-	IsActive     bool      `json:"isActive" db:"is_active"`
+	ID           int64  `json:"id" db:"id"`
+	CompanyId    int64  `json:"companyId" db:"company_id" validate:"required"`
+	CompanyName  string `json:"companyName,omitempty"` // Calculado (JOIN com companies)
+	Title        string `json:"title" db:"title" validate:"required,max=255"`
+	ContractType string `json:"contractType" db:"contract_type"` // hour_bank, fixed_price ou on_demand
+	TotalHours   int    `json:"totalHours" db:"total_hours"`     // Por período no banco de horas; 0 sob demanda
+	// Banco de horas: teto da sobra que passa de um período para o seguinte (0 = não acumula)
+	RolloverCapHours int        `json:"rolloverCapHours" db:"rollover_cap_hours"`
+	StartDate        time.Time  `json:"startDate" db:"start_date" validate:"required"`
+	EndDate          time.Time  `json:"endDate" db:"end_date" validate:"required"`
+	IsActive         bool       `json:"isActive" db:"is_active"`
+	OverrunPolicy    string     `json:"overrunPolicy" db:"overrun_policy"` // reject, warn ou flag
+	SLAPolicyID      *int64     `json:"slaPolicyId" db:"sla_policy_id"`    // Prazos de atendimento dos chamados
+	DeletedAt        *time.Time `json:"deletedAt,omitempty" db:"deleted_at"`
+
+	Balance *ContractBalance `json:"balance,omitempty"` // Só com ?include=balance
+}
+
+// CoversDay indica se o dia de t está dentro da vigência (StartDate e EndDate inclusivos).
+func (c *Contract) CoversDay(t time.Time) bool {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	start := time.Date(c.StartDate.Year(), c.StartDate.Month(), c.StartDate.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(c.EndDate.Year(), c.EndDate.Month(), c.EndDate.Day(), 0, 0, 0, 0, time.UTC)
+	return !day.Before(start) && !day.After(end)
+}
+
+// HasHourLimit indica se o contrato tem saldo de horas a controlar (todos, menos sob demanda).
+func (c *Contract) HasHourLimit() bool {
+	return c.ContractType != ContractOnDemand && c.TotalHours > 0
 }
 
 func (c *Contract) GetID() int64 {
@@ -21,3 +58,60 @@ func (c *Contract) GetID() int64 {
 func (c *Contract) SetID(id int64) {
 	c.ID = id
 }
+
+// BurnRateWindowDays é a janela (em dias) usada para calcular o ritmo recente de consumo.
+const BurnRateWindowDays = 30
+
+// ContractBalance é o saldo de horas de um contrato.
+// No banco de horas vale para um período (PeriodStart a PeriodEnd): ContractedHours soma as horas
+// do período e a sobra trazida do anterior (RolloverHours), e ConsumedHours conta só o período.
+// Sob demanda não há limite: o saldo fica zerado e nunca estoura.
+type ContractBalance struct {
+	ContractID      int64      `json:"contractId"`
+	ContractType    string     `json:"contractType"`
+	PeriodStart     *time.Time `json:"periodStart,omitempty"` // Só no banco de horas
+	PeriodEnd       *time.Time `json:"periodEnd,omitempty"`   // Inclusivo
+	RolloverHours   float64    `json:"rolloverHours"`
+	ContractedHours float64    `json:"contractedHours"`
+	ConsumedHours   float64    `json:"consumedHours"`
+	RemainingHours  float64    `json:"remainingHours"` // Negativo quando estourou
+	PercentUsed     float64    `json:"percentUsed"`
+	IsOverrun       bool       `json:"isOverrun"`
+
+	// Projeção pelo ritmo dos últimos BurnRateWindowDays dias
+	RecentHours             float64    `json:"recentHours"`
+	BurnRatePerDay          float64    `json:"burnRatePerDay"`
+	ProjectedExhaustionDate *time.Time `json:"projectedExhaustionDate"` // null se não há consumo recente ou já esgotou
+}
+
+// Calculate preenche os campos derivados a partir de ContractedHours, ConsumedHours e RecentHours.
+func (b *ContractBalance) Calculate(now time.Time) {
+	b.BurnRatePerDay = b.RecentHours / BurnRateWindowDays
+	b.ProjectedExhaustionDate = nil
+	if b.ContractType == ContractOnDemand {
+		b.RemainingHours, b.PercentUsed, b.IsOverrun = 0, 0, false
+		return
+	}
+
+	b.RemainingHours = b.ContractedHours - b.ConsumedHours
+	b.IsOverrun = b.RemainingHours < 0
+	if b.ContractedHours > 0 {
+		b.PercentUsed = b.ConsumedHours / b.ContractedHours * 100
+	}
+	if b.RemainingHours > 0 && b.BurnRatePerDay > 0 {
+		daysLeft := b.RemainingHours / b.BurnRatePerDay
+		exhaustion := now.Add(time.Duration(daysLeft * float64(24*time.Hour)))
+		// No banco de horas o saldo renova no próximo período: só projeta se esgotar antes
+		if b.PeriodEnd == nil || !exhaustion.After(b.PeriodEnd.AddDate(0, 0, 1)) {
+			b.ProjectedExhaustionDate = &exhaustion
+		}
+	}
+}
+
+// CoversPeriod indica se t cai no período do saldo. Fora do banco de horas o período é a vigência inteira.
+func (b *ContractBalance) CoversPeriod(t time.Time) bool {
+	if b.PeriodStart == nil || b.PeriodEnd == nil {
+		return true
+	}
+	return !t.Before(*b.PeriodStart) && t.Before(b.PeriodEnd.AddDate(0, 0, 1))
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Status da fatura
+const (
+	InvoiceDraft  = "draft"  // Em montagem: itens podem ser incluídos e removidos
+	InvoiceIssued = "issued" // Emitida: recebe o número do ano e não muda mais
+	InvoicePaid   = "paid"
+	InvoiceVoid   = "void" // Cancelada: as horas voltam a ficar disponíveis para faturar
+)
+
+// invoiceTransitions diz, para cada status de destino, de quais status a fatura pode vir.
+var invoiceTransitions = map[string][]string{
+	InvoiceIssued: {InvoiceDraft},
+	InvoicePaid:   {InvoiceIssued},
+	InvoiceVoid:   {InvoiceDraft, InvoiceIssued},
+}
+
+// InvoiceTransitionsTo devolve os status de origem aceitos para chegar em status.
+func InvoiceTransitionsTo(status string) []string {
+	return invoiceTransitions[status]
+}
+
+// Tipos de item da fatura
+const (
+	InvoiceLineHours  = "hours"  // Horas aprovadas de um consultor em um contrato, a um valor-hora
+	InvoiceLineManual = "manual" // Lançado à mão (ex.: despesas, ajustes)
+)
+
+// Invoice é a fatura de uma empresa para um período. Valores em decimal exato.
+type Invoice struct {
+	ID          int64           `json:"id" db:"id"`
+	CompanyID   int64           `json:"companyId" db:"company_id"`
+	Status      string          `json:"status" db:"status"`
+	Year        *int            `json:"year" db:"year"`     // Preenchidos na emissão
+	Number      *int            `json:"number" db:"number"` // Sequencial dentro do ano
+	PeriodStart time.Time       `json:"periodStart" db:"period_start"`
+	PeriodEnd   time.Time       `json:"periodEnd" db:"period_end"` // Inclusivo
+	Notes       string          `json:"notes" db:"notes"`
+	Total       decimal.Decimal `json:"total" db:"total"`
+	CreatedAt   time.Time       `json:"createdAt" db:"created_at"`
+	IssuedAt    *time.Time      `json:"issuedAt" db:"issued_at"`
+	PaidAt      *time.Time      `json:"paidAt" db:"paid_at"`
+	VoidedAt    *time.Time      `json:"voidedAt" db:"voided_at"`
+
+	// Calculados
+	CompanyName string         `json:"companyName,omitempty"`
+	Code        string         `json:"code,omitempty"` // Ex.: 2026/0007 (só depois de emitida)
+	Lines       []*InvoiceLine `json:"lines,omitempty"`
+	Warnings    []string       `json:"warnings,omitempty"` // Avisos da montagem (ex.: horas sem valor-hora)
+}
+
+func (i *Invoice) GetID() int64 {
+	return i.ID
+}
+
+func (i *Invoice) SetID(id int64) {
+	i.ID = id
+}
+
+// FillCode preenche o código de exibição a partir do ano e do número.
+func (i *Invoice) FillCode() {
+	i.Code = ""
+	if i.Year != nil && i.Number != nil {
+		i.Code = fmt.Sprintf("%d/%04d", *i.Year, *i.Number)
+	}
+}
+
+// InvoiceLine é um item da fatura. Amount = Quantity × UnitPrice, arredondado em centavos.
+type InvoiceLine struct {
+	ID          int64           `json:"id" db:"id"`
+	InvoiceID   int64           `json:"invoiceId" db:"invoice_id"`
+	Kind        string          `json:"kind" db:"kind"`
+	Description string          `json:"description" db:"description"`
+	ContractID  *int64          `json:"contractId" db:"contract_id"`
+	UserID      *int64          `json:"userId" db:"user_id"`
+	Quantity    decimal.Decimal `json:"quantity" db:"quantity"` // Horas, no item de horas
+	UnitPrice   decimal.Decimal `json:"unitPrice" db:"unit_price"`
+	Amount      decimal.Decimal `json:"amount" db:"amount"`
+}
+
+func (l *InvoiceLine) GetID() int64 {
+	return l.ID
+}
+
+func (l *InvoiceLine) SetID(id int64) {
+	l.ID = id
+}
+
+// BillTo é para quem a fatura vai: os contatos do financeiro e o endereço de cobrança principal.
+type BillTo struct {
+	Contacts []*CompanyContact
+	Address  *CompanyAddress // nil se a empresa não tiver endereço de cobrança
+}
+
+// CreateInvoiceRequest é o corpo de POST /api/invoices: a empresa, o período (datas inclusivas)
+// e, opcionalmente, itens manuais já na criação.
+type CreateInvoiceRequest struct {
+	CompanyID int64          `json:"companyId"`
+	From      string         `json:"from"` // AAAA-MM-DD
+	To        string         `json:"to"`
+	Notes     string         `json:"notes"`
+	Lines     []*InvoiceLine `json:"lines,omitempty"`
+}
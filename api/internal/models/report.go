@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// HoursReportRow é uma linha do relatório de horas: um grupo (empresa/contrato/usuário) em um período.
+// Só vêm preenchidos os campos das dimensões pedidas em groupBy (no grupo "ticket", horas sem chamado vêm com ticket nulo).
+type HoursReportRow struct {
+	Period           *time.Time `json:"period,omitempty"` // Início do período (dia, semana, mês...)
+	CompanyID        *int64     `json:"companyId,omitempty"`
+	CompanyName      *string    `json:"companyName,omitempty"`
+	ContractID       *int64     `json:"contractId,omitempty"`
+	ContractTitle    *string    `json:"contractTitle,omitempty"`
+	UserID           *int64     `json:"userId,omitempty"`
+	UserName         *string    `json:"userName,omitempty"`
+	TicketID         *int64     `json:"ticketId,omitempty"`
+	TicketTitle      *string    `json:"ticketTitle,omitempty"`
+	AppointmentCount int64      `json:"appointmentCount"`
+	DurationSeconds  int64      `json:"durationSeconds"`
+	TotalHours       float64    `json:"totalHours"`
+}
+
+// HoursReport é a resposta de GET /api/reports/hours.
+type HoursReport struct {
+	GroupBy         []string          `json:"groupBy"`
+	Period          string            `json:"period,omitempty"`
+	From            *time.Time        `json:"from,omitempty"`
+	To              *time.Time        `json:"to,omitempty"`
+	Rows            []*HoursReportRow `json:"rows"`
+	DurationSeconds int64             `json:"durationSeconds"`
+	TotalHours      float64           `json:"totalHours"`
+}
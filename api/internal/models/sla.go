@@ -0,0 +1,140 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Situação do SLA de um chamado (vazio = chamado sem política de SLA)
+const (
+	SLAOnTrack  = "on_track"
+	SLAAtRisk   = "at_risk"
+	SLABreached = "breached"
+)
+
+// DefaultAtRiskPercent é o quanto do prazo pode passar antes do chamado ficar "em risco".
+const DefaultAtRiskPercent = 80
+
+// BusinessWindow é um expediente em um dia da semana (0 = domingo), em "HH:MM" no fuso do calendário.
+type BusinessWindow struct {
+	Weekday int    `json:"weekday"`
+	Start   string `json:"start"`
+	End     string `json:"end"`
+}
+
+// BusinessHours é a grade semanal do calendário, gravada como JSONB.
+type BusinessHours []BusinessWindow
+
+func (h BusinessHours) Value() (driver.Value, error) { return jsonValue(h) }
+func (h *BusinessHours) Scan(src any) error          { return jsonScan(src, h) }
+
+// Holidays são os feriados (AAAA-MM-DD) do calendário, gravados como JSONB.
+type Holidays []string
+
+func (h Holidays) Value() (driver.Value, error) { return jsonValue(h) }
+func (h *Holidays) Scan(src any) error          { return jsonScan(src, h) }
+
+// BusinessCalendar define o horário comercial em que os prazos de SLA correm.
+type BusinessCalendar struct {
+	ID        int64         `json:"id" db:"id"`
+	Name      string        `json:"name" db:"name" validate:"required,max=255"`
+	Timezone  string        `json:"timezone" db:"timezone" validate:"max=64"` // Ex.: America/Sao_Paulo
+	Hours     BusinessHours `json:"hours" db:"hours"`
+	Holidays  Holidays      `json:"holidays" db:"holidays"`
+	DeletedAt *time.Time    `json:"deletedAt,omitempty" db:"deleted_at"`
+}
+
+func (c *BusinessCalendar) GetID() int64 {
+	return c.ID
+}
+
+func (c *BusinessCalendar) SetID(id int64) {
+	c.ID = id
+}
+
+// SLATarget são os prazos de uma prioridade, em minutos de horário comercial.
+type SLATarget struct {
+	ResponseMinutes   int `json:"responseMinutes"`
+	ResolutionMinutes int `json:"resolutionMinutes"`
+}
+
+// SLATargets são os prazos por prioridade (low, medium, high, urgent), gravados como JSONB.
+type SLATargets map[string]SLATarget
+
+func (t SLATargets) Value() (driver.Value, error) { return jsonValue(t) }
+func (t *SLATargets) Scan(src any) error          { return jsonScan(src, t) }
+
+// SLAPolicy é uma política de SLA que pode ser ligada a contratos.
+// Sem calendário, os prazos correm 24x7.
+type SLAPolicy struct {
+	ID            int64      `json:"id" db:"id"`
+	Name          string     `json:"name" db:"name" validate:"required,max=255"`
+	CalendarID    *int64     `json:"calendarId" db:"calendar_id"`
+	Targets       SLATargets `json:"targets" db:"targets"`
+	AtRiskPercent int        `json:"atRiskPercent" db:"at_risk_percent" validate:"min=1,max=100"` // % do prazo que dispara "em risco"
+	DeletedAt     *time.Time `json:"deletedAt,omitempty" db:"deleted_at"`
+}
+
+func (p *SLAPolicy) GetID() int64 {
+	return p.ID
+}
+
+func (p *SLAPolicy) SetID(id int64) {
+	p.ID = id
+}
+
+// SLAReportRow é a conformidade de um mês (chamados abertos no mês).
+// "Medidos" são os chamados com resultado conhecido: já atendidos/resolvidos ou com prazo vencido.
+type SLAReportRow struct {
+	Month                *time.Time `json:"month,omitempty"`
+	Tickets              int64      `json:"tickets"`
+	ResponseMeasured     int64      `json:"responseMeasured"`
+	ResponseMet          int64      `json:"responseMet"`
+	ResponseCompliance   *float64   `json:"responseCompliance"` // % no prazo; null sem chamados medidos
+	ResolutionMeasured   int64      `json:"resolutionMeasured"`
+	ResolutionMet        int64      `json:"resolutionMet"`
+	ResolutionCompliance *float64   `json:"resolutionCompliance"`
+}
+
+// FillCompliance calcula os percentuais a partir das contagens.
+func (r *SLAReportRow) FillCompliance() {
+	r.ResponseCompliance = percent(r.ResponseMet, r.ResponseMeasured)
+	r.ResolutionCompliance = percent(r.ResolutionMet, r.ResolutionMeasured)
+}
+
+// SLAReport é a resposta de GET /api/contracts/{id}/sla-report.
+type SLAReport struct {
+	ContractID  int64           `json:"contractId"`
+	SLAPolicyID *int64          `json:"slaPolicyId"`
+	From        *time.Time      `json:"from,omitempty"`
+	To          *time.Time      `json:"to,omitempty"`
+	Months      []*SLAReportRow `json:"months"`
+	Total       SLAReportRow    `json:"total"` // Soma do período (sem month)
+}
+
+func percent(part, total int64) *float64 {
+	if total == 0 {
+		return nil
+	}
+	p := float64(part) * 100 / float64(total)
+	return &p
+}
+
+func jsonValue(v any) (driver.Value, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+func jsonScan(src, dest any) error {
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, dest)
+	case string:
+		return json.Unmarshal([]byte(v), dest)
+	}
+	return errors.New("tipo incompatível para JSON")
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// ContractStatement é o extrato mensal de um contrato enviado ao cliente no fechamento.
+type ContractStatement struct {
+	Company      *Company
+	Contract     *Contract
+	Month        time.Time      // Primeiro dia do mês
+	Appointments []*Appointment // Apontamentos do mês, em ordem cronológica
+	MonthHours   float64        // Soma dos apontamentos do mês
+	// Horas consumidas desde o início do contrato até o fim do mês
+	ConsumedHours float64
+	// Banco de horas: saldo do período que contém o último dia do mês
+	Balance *ContractBalance
+	// Gestores que aprovam o extrato (contatos do tipo ContactApprover)
+	Approvers   []*CompanyContact
+	GeneratedAt time.Time
+}
+
+// RemainingHours é o saldo do contrato ao fim do mês (negativo quando estourou).
+// No banco de horas é o saldo do período.
+func (s *ContractStatement) RemainingHours() float64 {
+	if s.Balance != nil {
+		return s.Balance.RemainingHours
+	}
+	return float64(s.Contract.TotalHours) - s.ConsumedHours
+}
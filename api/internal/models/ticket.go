@@ -0,0 +1,82 @@
+package models
+
+import "time"
+
+// Status do chamado
+const (
+	TicketOpen          = "open"
+	TicketInProgress    = "in_progress"
+	TicketWaitingClient = "waiting_client"
+	TicketResolved      = "resolved"
+	TicketClosed        = "closed"
+)
+
+// Prioridades do chamado
+const (
+	PriorityLow    = "low"
+	PriorityMedium = "medium"
+	PriorityHigh   = "high"
+	PriorityUrgent = "urgent"
+)
+
+var (
+	TicketStatuses   = []string{TicketOpen, TicketInProgress, TicketWaitingClient, TicketResolved, TicketClosed}
+	TicketPriorities = []string{PriorityLow, PriorityMedium, PriorityHigh, PriorityUrgent}
+)
+
+// ticketTransitions diz, para cada status de destino, de quais status o chamado pode vir.
+// Resolvido e fechado só saem por reabertura (open) ou retomada (in_progress).
+var ticketTransitions = map[string][]string{
+	TicketOpen:          {TicketResolved, TicketClosed},
+	TicketInProgress:    {TicketOpen, TicketWaitingClient, TicketResolved},
+	TicketWaitingClient: {TicketOpen, TicketInProgress},
+	TicketResolved:      {TicketOpen, TicketInProgress, TicketWaitingClient},
+	TicketClosed:        {TicketOpen, TicketInProgress, TicketWaitingClient, TicketResolved},
+}
+
+// TicketTransitionsTo devolve os status de origem aceitos para chegar em status.
+func TicketTransitionsTo(status string) []string {
+	return ticketTransitions[status]
+}
+
+// Ticket é um chamado de service desk de uma empresa, opcionalmente ligado a um contrato.
+// O solicitante é a pessoa do cliente que abriu o chamado; o responsável é um usuário do Nexus.
+type Ticket struct {
+	ID             int64      `json:"id" db:"id"`
+	CompanyID      int64      `json:"companyId" db:"company_id" validate:"required"`
+	ContractID     *int64     `json:"contractId" db:"contract_id"`
+	RequesterName  string     `json:"requesterName" db:"requester_name" validate:"max=255"`
+	RequesterEmail string     `json:"requesterEmail" db:"requester_email" validate:"email,max=255"`
+	AssigneeID     *int64     `json:"assigneeId" db:"assignee_id"`
+	Title          string     `json:"title" db:"title" validate:"required,max=255"`
+	Description    string     `json:"description" db:"description"`
+	Status         string     `json:"status" db:"status"`
+	Priority       string     `json:"priority" db:"priority" validate:"oneof=low medium high urgent"`
+	CreatedAt      time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updatedAt" db:"updated_at"`
+	ResolvedAt     *time.Time `json:"resolvedAt" db:"resolved_at"`
+	ClosedAt       *time.Time `json:"closedAt" db:"closed_at"`
+
+	// SLA: prazos calculados na abertura pela política do contrato (horário comercial)
+	FirstResponseAt  *time.Time `json:"firstResponseAt" db:"first_response_at"` // Primeira saída de "open"
+	ResponseDueAt    *time.Time `json:"responseDueAt" db:"response_due_at"`
+	ResolutionDueAt  *time.Time `json:"resolutionDueAt" db:"resolution_due_at"`
+	ResponseRiskAt   *time.Time `json:"responseRiskAt" db:"response_risk_at"` // A partir daqui fica "em risco"
+	ResolutionRiskAt *time.Time `json:"resolutionRiskAt" db:"resolution_risk_at"`
+	SLAStatus        string     `json:"slaStatus" db:"sla_status"` // on_track, at_risk, breached ou vazio (sem SLA)
+	DeletedAt        *time.Time `json:"deletedAt,omitempty" db:"deleted_at"`
+
+	// Calculados
+	CompanyName   string  `json:"companyName,omitempty"`
+	ContractTitle string  `json:"contractTitle,omitempty"`
+	AssigneeName  string  `json:"assigneeName,omitempty"`
+	TotalHours    float64 `json:"totalHours"` // Soma dos apontamentos do chamado
+}
+
+func (t *Ticket) GetID() int64 {
+	return t.ID
+}
+
+func (t *Ticket) SetID(id int64) {
+	t.ID = id
+}
@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// Status da folha de horas semanal. "draft" não é gravado: é a semana ainda não enviada.
+const (
+	TimesheetDraft     = "draft"
+	TimesheetSubmitted = "submitted"
+	TimesheetApproved  = "approved"
+	TimesheetRejected  = "rejected"
+)
+
+// ApprovalStatuses são os valores aceitos no filtro approvalStatus de apontamentos e relatórios.
+var ApprovalStatuses = []string{TimesheetDraft, TimesheetSubmitted, TimesheetApproved, TimesheetRejected}
+
+// Timesheet é a semana de um consultor enviada para aprovação.
+// A semana vai de segunda (WeekStart) a domingo; os apontamentos entram pelo início (start_time).
+type Timesheet struct {
+	ID          int64      `json:"id" db:"id"`
+	UserID      int64      `json:"userId" db:"user_id"`
+	WeekStart   time.Time  `json:"weekStart" db:"week_start"`
+	Status      string     `json:"status" db:"status"`
+	Comment     string     `json:"comment" db:"comment"` // Comentário de quem aprovou/recusou
+	SubmittedAt time.Time  `json:"submittedAt" db:"submitted_at"`
+	ReviewedAt  *time.Time `json:"reviewedAt" db:"reviewed_at"`
+	ReviewedBy  *int64     `json:"reviewedBy" db:"reviewed_by"`
+
+	// Calculadas
+	UserName         string  `json:"userName,omitempty"`
+	ReviewerName     string  `json:"reviewerName,omitempty"`
+	AppointmentCount int64   `json:"appointmentCount"`
+	TotalHours       float64 `json:"totalHours"`
+}
+
+func (t *Timesheet) GetID() int64 {
+	return t.ID
+}
+
+func (t *Timesheet) SetID(id int64) {
+	t.ID = id
+}
+
+// WeekStart devolve a segunda-feira 00:00 da semana de t, no mesmo fuso.
+func WeekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7 // Segunda = 0
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+}
+
+// TimesheetLocked diz se os apontamentos de uma semana com este status estão travados para o usuário:
+// aprovada trava para todos; enviada trava para o consultor (o admin ainda pode corrigir antes de revisar).
+func TimesheetLocked(status string, admin bool) bool {
+	return status == TimesheetApproved || (status == TimesheetSubmitted && !admin)
+}
+
+// SubmitTimesheetRequest é o corpo de POST /api/timesheets.
+// WeekStart aceita qualquer dia da semana (AAAA-MM-DD); UserID é opcional (só admin envia por outro).
+type SubmitTimesheetRequest struct {
+	UserID    int64  `json:"userId,omitempty"`
+	WeekStart string `json:"weekStart"`
+}
+
+// ReviewTimesheetRequest é o corpo de approve/reject (comentário obrigatório na recusa).
+type ReviewTimesheetRequest struct {
+	Comment string `json:"comment"`
+}
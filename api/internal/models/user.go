@@ -1,10 +1,23 @@
 package models
 
+import "time"
+
+// Papéis aceitos pela constraint users.role
+const (
+	RoleAdmin      = "admin"
+	RoleConsultant = "consultant"
+)
+
 type User struct {
-	ID    int64  `json:"id" db:"id"`
-	Name  string `json:"name" db:"name"`
-	Email string `json:"email" db:"email"`
-	Role  string `json:"role" db:"role"`
+	ID           int64      `json:"id" db:"id"`
+	Name         string     `json:"name" db:"name" validate:"required,max=255"`
+	Email        string     `json:"email" db:"email" validate:"required,email,max=255"`
+	Role         string     `json:"role" db:"role" validate:"required,oneof=admin consultant"`
+	PasswordHash string     `json:"-" db:"password_hash"`
+	DeletedAt    *time.Time `json:"deletedAt,omitempty" db:"deleted_at"`
+
+	// Somente entrada: senha em texto puro, convertida em hash antes de salvar
+	Password string `json:"password,omitempty" validate:"max=72"`
 }
 
 func (u *User) GetID() int64 {
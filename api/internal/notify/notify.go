@@ -0,0 +1,118 @@
+// Package notify encaminha os envios da empresa cliente (faturas, extratos, chamados) ao tipo
+// certo de contato e entrega as mensagens por um Notifier. Ainda não há envio de e-mail:
+// o Notifier padrão só registra a mensagem no log.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"nexus/internal/models"
+	"nexus/internal/repository"
+)
+
+// Message é uma notificação pronta para entrega.
+type Message struct {
+	To      []string
+	Subject string
+	Body    string
+}
+
+// Notifier entrega as mensagens (e-mail, fila, etc.).
+type Notifier interface {
+	Notify(ctx context.Context, msg Message) error
+}
+
+// LogNotifier só escreve a mensagem no log, no lugar do envio real.
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(_ context.Context, msg Message) error {
+	log.Printf("✉️ Para %s: %s", strings.Join(msg.To, ", "), msg.Subject)
+	return nil
+}
+
+// Router escolhe os destinatários de cada tipo de envio nos contatos da empresa.
+type Router struct {
+	contacts  repository.Repository[*models.CompanyContact]
+	companies repository.CompanyRepository
+	notifier  Notifier
+}
+
+// NewRouter cria o roteador; notifier nil usa o LogNotifier.
+func NewRouter(contacts repository.Repository[*models.CompanyContact], companies repository.CompanyRepository, notifier Notifier) *Router {
+	if notifier == nil {
+		notifier = LogNotifier{}
+	}
+	return &Router{contacts: contacts, companies: companies, notifier: notifier}
+}
+
+// Recipients devolve os contatos da empresa com o papel contactType (models.ContactBilling,
+// ContactTechnical ou ContactApprover), o principal primeiro. Sem nenhum contato do tipo,
+// vale o contato principal; sem contatos, o e-mail geral da empresa (contact_email).
+// Lista vazia significa que não há para quem mandar.
+func (r *Router) Recipients(ctx context.Context, companyID int64, contactType string) ([]*models.CompanyContact, error) {
+	page, err := r.contacts.List(ctx, repository.ListQuery{
+		PageSize: repository.MaxPageSize,
+		Sort:     []string{"-primary", "name"},
+		Filters:  map[string]string{"companyId": strconv.FormatInt(companyID, 10)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar contatos da empresa: %w", err)
+	}
+
+	var recipients []*models.CompanyContact
+	for _, contact := range page.Items {
+		if contact.Has(contactType) {
+			recipients = append(recipients, contact)
+		}
+	}
+	if len(recipients) > 0 {
+		return recipients, nil
+	}
+	if len(page.Items) > 0 && page.Items[0].Primary {
+		return page.Items[:1], nil
+	}
+
+	companies, err := r.companies.Get(ctx, &companyID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar empresa: %w", err)
+	}
+	if len(companies) == 0 || companies[0].ContactEmail == "" {
+		return nil, nil
+	}
+	company := companies[0]
+	return []*models.CompanyContact{{CompanyID: company.ID, Name: company.Name, Email: company.ContactEmail}}, nil
+}
+
+// Send entrega msg aos contatos do tipo contactType da empresa, além dos endereços que já
+// estejam em msg.To (ex.: o solicitante do chamado), sem repetir e-mail. Sem destinatário,
+// a mensagem é descartada (com registro no log), sem erro: a operação que notificou já valeu.
+func (r *Router) Send(ctx context.Context, companyID int64, contactType string, msg Message) error {
+	recipients, err := r.Recipients(ctx, companyID, contactType)
+	if err != nil {
+		return err
+	}
+	to := make([]string, 0, len(msg.To)+len(recipients))
+	seen := make(map[string]bool)
+	for _, email := range msg.To {
+		if key := strings.ToLower(email); email != "" && !seen[key] {
+			seen[key] = true
+			to = append(to, email)
+		}
+	}
+	for _, contact := range recipients {
+		if key := strings.ToLower(contact.Email); !seen[key] {
+			seen[key] = true
+			to = append(to, contact.Email)
+		}
+	}
+	if len(to) == 0 {
+		log.Printf("Sem contato %s na empresa %d: notificação %q não enviada", contactType, companyID, msg.Subject)
+		return nil
+	}
+	msg.To = to
+	return r.notifier.Notify(ctx, msg)
+}
@@ -0,0 +1,132 @@
+package pdf
+
+import (
+	"io"
+	"strings"
+	"time"
+
+	"nexus/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// formatMoney escreve um valor em reais (ex.: R$ 1.234,56).
+func formatMoney(v decimal.Decimal) string {
+	sign := ""
+	if v.IsNegative() {
+		sign, v = "-", v.Neg()
+	}
+	s := v.StringFixed(2)
+	whole, cents := s[:len(s)-3], s[len(s)-2:]
+	var b strings.Builder
+	for i, c := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(c)
+	}
+	return sign + "R$ " + b.String() + "," + cents
+}
+
+// formatQuantity escreve uma quantidade com vírgula decimal (ex.: 12,50).
+func formatQuantity(v decimal.Decimal) string {
+	return strings.Replace(v.StringFixed(2), ".", ",", 1)
+}
+
+// formatAddress escreve o endereço em uma linha (ex.: Av. Paulista, 1000 - Bela Vista - São Paulo/SP - 01310-100).
+func formatAddress(a models.Address) string {
+	street := a.Street
+	if a.Number != "" {
+		street += ", " + a.Number
+	}
+	if a.Complement != "" {
+		street += " " + a.Complement
+	}
+	parts := []string{street}
+	if a.District != "" {
+		parts = append(parts, a.District)
+	}
+	parts = append(parts, a.City+"/"+a.State, a.ZipCode)
+	return strings.Join(parts, " - ")
+}
+
+// formatContacts escreve os contatos como "Nome <e-mail>", separados por ponto e vírgula.
+func formatContacts(contacts []*models.CompanyContact) string {
+	names := make([]string, len(contacts))
+	for i, c := range contacts {
+		names[i] = c.Name + " <" + c.Email + ">"
+	}
+	return strings.Join(names, "; ")
+}
+
+// RenderInvoice escreve a fatura em PDF. company pode ser nil se a empresa não for encontrada;
+// billTo traz os contatos do financeiro e o endereço de cobrança (nil ou vazio omite).
+func RenderInvoice(w io.Writer, invoice *models.Invoice, company *models.Company, billTo *models.BillTo, generatedAt time.Time) error {
+	d := newDocument("Gerado em " + generatedAt.Format("02/01/2006 15:04"))
+	d.AddPage()
+
+	// Cabeçalho: número (ou rascunho), cliente e período
+	if invoice.Code != "" {
+		d.text("B", 16, "Fatura "+invoice.Code)
+	} else {
+		d.text("B", 16, "Fatura (rascunho)")
+	}
+	d.Ln(4)
+	if company != nil {
+		d.field("Cliente:", company.Name)
+		d.field("CNPJ:", company.FormattedCNPJ())
+	} else {
+		d.field("Cliente:", invoice.CompanyName)
+	}
+	if billTo != nil {
+		if billTo.Address != nil {
+			d.field("Endereço:", formatAddress(billTo.Address.Address()))
+		}
+		if len(billTo.Contacts) > 0 {
+			d.field("A/C:", formatContacts(billTo.Contacts))
+		}
+	}
+	d.field("Período:", invoice.PeriodStart.Format("02/01/2006")+" a "+invoice.PeriodEnd.Format("02/01/2006"))
+	if invoice.IssuedAt != nil {
+		d.field("Emissão:", invoice.IssuedAt.Format("02/01/2006"))
+	}
+	switch invoice.Status {
+	case models.InvoicePaid:
+		d.field("Situação:", "Paga em "+invoice.PaidAt.Format("02/01/2006"))
+	case models.InvoiceVoid:
+		d.field("Situação:", "Cancelada")
+	}
+	d.Ln(4)
+
+	// Itens
+	columns := []tableColumn{
+		{Title: "Descrição", Width: 100, Align: "L", Wrap: true},
+		{Title: "Qtd.", Width: 20, Align: "R"},
+		{Title: "Valor unit.", Width: 30, Align: "R"},
+		{Title: "Valor", Width: 30, Align: "R"},
+	}
+	d.tableHeader(columns)
+	if len(invoice.Lines) == 0 {
+		d.CellFormat(180, lineHeight+1, d.tr("Nenhum item."), "1", 1, "C", false, 0, "")
+	}
+	for _, l := range invoice.Lines {
+		d.tableRow(columns, []string{
+			l.Description,
+			formatQuantity(l.Quantity),
+			formatMoney(l.UnitPrice),
+			formatMoney(l.Amount),
+		})
+	}
+	d.SetFont("Helvetica", "B", 10)
+	d.CellFormat(150, lineHeight+2, d.tr("Total"), "1", 0, "R", false, 0, "")
+	d.CellFormat(30, lineHeight+2, d.tr(formatMoney(invoice.Total)), "1", 1, "R", false, 0, "")
+
+	if invoice.Notes != "" {
+		d.Ln(6)
+		d.text("B", 11, "Observações")
+		d.Ln(1)
+		d.SetFont("Helvetica", "", 10)
+		d.MultiCell(0, lineHeight, d.tr(invoice.Notes), "", "L", false)
+	}
+	return d.Output(w)
+}
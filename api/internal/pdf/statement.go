@@ -0,0 +1,238 @@
+// Package pdf gera os documentos enviados aos clientes (extratos, faturas) em Go puro.
+package pdf
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"nexus/internal/models"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+var monthNames = []string{
+	"janeiro", "fevereiro", "março", "abril", "maio", "junho",
+	"julho", "agosto", "setembro", "outubro", "novembro", "dezembro",
+}
+
+var contractTypeNames = map[string]string{
+	models.ContractHourBank:   "Banco de horas mensal",
+	models.ContractFixedPrice: "Projeto fechado",
+	models.ContractOnDemand:   "Sob demanda",
+}
+
+const (
+	lineHeight = 5.0
+	margin     = 15.0
+)
+
+// document junta o gofpdf com o tradutor para cp1252 (as fontes padrão não são Unicode).
+type document struct {
+	*gofpdf.Fpdf
+	tr func(string) string
+}
+
+func newDocument(footer string) *document {
+	f := gofpdf.New("P", "mm", "A4", "")
+	f.SetMargins(margin, margin, margin)
+	f.SetAutoPageBreak(true, margin+5)
+	f.AliasNbPages("")
+	d := &document{Fpdf: f, tr: f.UnicodeTranslatorFromDescriptor("")}
+
+	f.SetFooterFunc(func() {
+		f.SetY(-margin)
+		f.SetFont("Helvetica", "", 8)
+		f.SetTextColor(120, 120, 120)
+		f.CellFormat(90, lineHeight, d.tr(footer), "", 0, "L", false, 0, "")
+		f.CellFormat(0, lineHeight, d.tr(fmt.Sprintf("Página %d de {nb}", f.PageNo())), "", 0, "R", false, 0, "")
+		f.SetTextColor(0, 0, 0)
+	})
+	return d
+}
+
+// text escreve uma linha inteira.
+func (d *document) text(style string, size float64, s string) {
+	d.SetFont("Helvetica", style, size)
+	d.CellFormat(0, size*0.5, d.tr(s), "", 1, "L", false, 0, "")
+}
+
+// field escreve "rótulo: valor" em uma linha.
+func (d *document) field(label, value string) {
+	d.SetFont("Helvetica", "B", 10)
+	d.CellFormat(35, lineHeight+1, d.tr(label), "", 0, "L", false, 0, "")
+	d.SetFont("Helvetica", "", 10)
+	d.CellFormat(0, lineHeight+1, d.tr(value), "", 1, "L", false, 0, "")
+}
+
+// tableColumn é uma coluna de tabela; Wrap quebra o texto em várias linhas.
+type tableColumn struct {
+	Title string
+	Width float64
+	Align string
+	Wrap  bool
+}
+
+func (d *document) tableHeader(columns []tableColumn) {
+	d.SetFont("Helvetica", "B", 9)
+	d.SetFillColor(230, 230, 230)
+	for _, col := range columns {
+		d.CellFormat(col.Width, lineHeight+2, d.tr(col.Title), "1", 0, col.Align, true, 0, "")
+	}
+	d.Ln(-1)
+	d.SetFont("Helvetica", "", 9)
+}
+
+// tableRow desenha uma linha com altura ajustada à coluna mais alta,
+// repetindo o cabeçalho se a linha não couber na página.
+func (d *document) tableRow(columns []tableColumn, values []string) {
+	lines := make([][]string, len(columns))
+	rowLines := 1
+	for i, col := range columns {
+		text := d.tr(values[i])
+		if col.Wrap {
+			for _, l := range d.SplitLines([]byte(text), col.Width-2) {
+				lines[i] = append(lines[i], string(l))
+			}
+		}
+		if len(lines[i]) == 0 {
+			lines[i] = []string{text}
+		}
+		rowLines = max(rowLines, len(lines[i]))
+	}
+	height := float64(rowLines)*lineHeight + 1
+
+	_, pageHeight := d.GetPageSize()
+	_, _, _, bottom := d.GetMargins()
+	if d.GetY()+height > pageHeight-bottom {
+		d.AddPage()
+		d.tableHeader(columns)
+	}
+
+	x, y := d.GetX(), d.GetY()
+	for i, col := range columns {
+		d.Rect(x, y, col.Width, height, "D")
+		for j, line := range lines[i] {
+			d.SetXY(x, y+0.5+float64(j)*lineHeight)
+			d.CellFormat(col.Width, lineHeight, line, "", 0, col.Align, false, 0, "")
+		}
+		x += col.Width
+	}
+	d.SetXY(margin, y+height)
+}
+
+// remaining escreve o saldo de horas, marcando quando foi excedido.
+func (d *document) remaining(hours float64) {
+	if hours < 0 {
+		d.field("Saldo:", formatHours(hours)+" h (excedido)")
+	} else {
+		d.field("Saldo:", formatHours(hours)+" h")
+	}
+}
+
+// formatHours escreve horas com vírgula decimal (ex.: 12,50).
+func formatHours(h float64) string {
+	return strings.Replace(strconv.FormatFloat(h, 'f', 2, 64), ".", ",", 1)
+}
+
+func formatMonth(t time.Time) string {
+	return fmt.Sprintf("%s/%d", monthNames[t.Month()-1], t.Year())
+}
+
+// RenderStatement escreve o extrato mensal do contrato em PDF.
+func RenderStatement(w io.Writer, s *models.ContractStatement) error {
+	d := newDocument("Gerado em " + s.GeneratedAt.Format("02/01/2006 15:04"))
+	d.AddPage()
+
+	// Cabeçalho: cliente e contrato
+	d.text("B", 16, "Extrato de Horas")
+	d.Ln(4)
+	if s.Company != nil {
+		d.field("Cliente:", s.Company.Name)
+		d.field("CNPJ:", s.Company.FormattedCNPJ())
+	}
+	d.field("Contrato:", s.Contract.Title)
+	d.field("Vigência:", s.Contract.StartDate.Format("02/01/2006")+" a "+s.Contract.EndDate.Format("02/01/2006"))
+	d.field("Período:", formatMonth(s.Month))
+	if len(s.Approvers) > 0 {
+		d.field("Aprovação:", formatContacts(s.Approvers))
+	}
+	d.Ln(4)
+
+	// Apontamentos do mês
+	columns := []tableColumn{
+		{Title: "Data", Width: 22, Align: "C"},
+		{Title: "Horário", Width: 24, Align: "C"},
+		{Title: "Consultor", Width: 38, Align: "L", Wrap: true},
+		{Title: "Descrição", Width: 78, Align: "L", Wrap: true},
+		{Title: "Horas", Width: 18, Align: "R"},
+	}
+	d.tableHeader(columns)
+	if len(s.Appointments) == 0 {
+		d.CellFormat(180, lineHeight+1, d.tr("Nenhum apontamento no período."), "1", 1, "C", false, 0, "")
+	}
+	for _, a := range s.Appointments {
+		end := "em andamento"
+		if a.EndTime != nil {
+			end = a.EndTime.Format("15:04")
+		}
+		d.tableRow(columns, []string{
+			a.StartTime.Format("02/01/2006"),
+			a.StartTime.Format("15:04") + " - " + end,
+			a.UserName,
+			a.Description,
+			formatHours(a.TotalHours),
+		})
+	}
+	d.SetFont("Helvetica", "B", 9)
+	d.CellFormat(162, lineHeight+2, d.tr("Total do mês"), "1", 0, "R", false, 0, "")
+	d.CellFormat(18, lineHeight+2, formatHours(s.MonthHours), "1", 1, "R", false, 0, "")
+	d.Ln(6)
+
+	// Totais contra o contratado, conforme o tipo do contrato
+	d.text("B", 12, "Resumo do contrato")
+	d.Ln(2)
+	d.field("Tipo:", contractTypeNames[s.Contract.ContractType])
+	switch {
+	case s.Contract.ContractType == models.ContractOnDemand:
+		d.field("Consumido:", formatHours(s.ConsumedHours)+" h (até o fim de "+formatMonth(s.Month)+")")
+	case s.Balance != nil:
+		b := s.Balance
+		if b.PeriodStart != nil {
+			d.field("Período do banco:", b.PeriodStart.Format("02/01/2006")+" a "+b.PeriodEnd.Format("02/01/2006"))
+		}
+		contracted := formatHours(float64(s.Contract.TotalHours)) + " h"
+		if b.RolloverHours > 0 {
+			contracted += " + " + formatHours(b.RolloverHours) + " h acumuladas"
+		}
+		d.field("Contratado:", contracted)
+		d.field("Consumido:", formatHours(b.ConsumedHours)+" h no período")
+		d.remaining(s.RemainingHours())
+	default:
+		d.field("Contratado:", formatHours(float64(s.Contract.TotalHours))+" h")
+		d.field("Consumido:", formatHours(s.ConsumedHours)+" h (até o fim de "+formatMonth(s.Month)+")")
+		d.remaining(s.RemainingHours())
+	}
+
+	// Assinaturas (o bloco não é quebrado entre páginas)
+	_, pageHeight := d.GetPageSize()
+	if d.GetY()+35 > pageHeight-margin-5 {
+		d.AddPage()
+	}
+	d.Ln(20)
+	y := d.GetY()
+	d.Line(margin, y, margin+75, y)
+	d.Line(margin+105, y, margin+180, y)
+	d.SetFont("Helvetica", "", 9)
+	client := "Cliente"
+	if s.Company != nil {
+		client = s.Company.Name
+	}
+	d.CellFormat(75, lineHeight, d.tr(client), "", 0, "C", false, 0, "")
+	d.SetX(margin + 105)
+	d.CellFormat(75, lineHeight, d.tr("Responsável pelo contrato"), "", 1, "C", false, 0, "")
+
+	return d.Output(w)
+}
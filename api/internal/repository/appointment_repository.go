@@ -3,109 +3,163 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
 	"nexus/internal/models"
 )
 
 type AppointmentRepository interface {
 	Repository[*models.Appointment]
-	GetAllWithContract() ([]*models.Appointment, error)
-	GetByContractID(contractID int64) ([]*models.Appointment, error)
-	GetByUserID(userID int64) ([]*models.Appointment, error)
+	GetAllWithContract(ctx context.Context, q ListQuery) (*Page[*models.Appointment], error)
+	EachWithContract(ctx context.Context, q ListQuery, fn func(*models.Appointment) error) error
+	GetRunningByUserID(ctx context.Context, userID int64) (*models.Appointment, error)
+	StartTimer(ctx context.Context, appt *models.Appointment) (*models.Appointment, error)
+	StopTimer(ctx context.Context, id int64, at time.Time) (*models.Appointment, error)
 }
 
 type postgresAppointmentRepository struct {
 	Repository[*models.Appointment]
-	db *sql.DB
+	db          *sql.DB
+	detailsView *listView[*models.Appointment]
 }
 
 func NewAppointmentRepository(db *sql.DB) AppointmentRepository {
+	detailsView := newListView("a", `SELECT a.id, a.contract_id, a.user_id, a.ticket_id, a.start_time, a.end_time, a.description,
+	                 a.allow_overlap, a.is_overrun, a.invoice_line_id, a.created_at, a.deleted_at,
+	                 EXTRACT(EPOCH FROM (COALESCE(a.end_time, CURRENT_TIMESTAMP) - a.start_time)) / 3600 as total_hours,
+	                 EXTRACT(EPOCH FROM (COALESCE(a.end_time, CURRENT_TIMESTAMP) - a.start_time))::bigint as duration_seconds,
+	                 c.title, u.name, COALESCE(ts.status, 'draft')`,
+		`FROM appointments a
+	     JOIN contracts c ON a.contract_id = c.id
+	     JOIN users u ON a.user_id = u.id
+	     LEFT JOIN timesheets ts ON ts.user_id = a.user_id AND ts.week_start = date_trunc('week', a.start_time)::date`,
+		scanAppointment,
+	).
+		withColumn("contractTitle", "c.title").
+		withColumn("userName", "u.name").
+		withColumn("approvalStatus", "COALESCE(ts.status, 'draft')").
+		withPeriod("startTime").
+		withDefaultSort("-startTime")
+
 	return &postgresAppointmentRepository{
-		Repository: NewPostgresRepository[*models.Appointment](db, "appointments"),
-		db:         db,
+		Repository:  NewPostgresRepository[*models.Appointment](db, "appointments"),
+		db:          db,
+		detailsView: detailsView,
 	}
 }
 
-func scanAppointments(rows *sql.Rows) ([]*models.Appointment, error) {
-	var appointments []*models.Appointment
-	for rows.Next() {
-		var a models.Appointment
-		if err := rows.Scan(
-			&a.ID, &a.StartTime, &a.EndTime, &a.Description, &a.TotalHours, &a.DurationSeconds,
-			&a.ContractTitle, &a.UserName,
-		); err != nil {
-			return nil, err
-		}
-		appointments = append(appointments, &a)
-	}
-	return appointments, nil
+func scanAppointment(rows *sql.Rows) (*models.Appointment, error) {
+	var a models.Appointment
+	err := rows.Scan(
+		&a.ID, &a.ContractID, &a.UserID, &a.TicketID, &a.StartTime, &a.EndTime, &a.Description,
+		&a.AllowOverlap, &a.IsOverrun, &a.InvoiceLineID, &a.CreatedAt, &a.DeletedAt,
+		&a.TotalHours, &a.DurationSeconds, &a.ContractTitle, &a.UserName, &a.ApprovalStatus,
+	)
+	return &a, err
 }
 
-func (r *postgresAppointmentRepository) GetAllWithContract() ([]*models.Appointment, error) {
-	query := `SELECT a.id, a.start_time, a.end_time, a.description, -- Adicionei description
-	                 EXTRACT(EPOCH FROM (COALESCE(a.end_time, CURRENT_TIMESTAMP) - a.start_time)) / 3600 as total_hours,
-									 EXTRACT(EPOCH FROM (COALESCE(a.end_time, CURRENT_TIMESTAMP) - a.start_time))::bigint as duration_seconds,
-                     c.title, u.name
-              FROM appointments a
-              JOIN contracts c ON a.contract_id = c.id
-              JOIN users u ON a.user_id = u.id
-              ORDER BY a.created_at DESC`
-
-	rows, err := r.db.QueryContext(context.Background(), query)
+// GetAllWithContract lista apontamentos com título do contrato e nome do consultor.
+// Filtros comuns: userId, contractId, approvalStatus e o período (from/to) sobre startTime.
+func (r *postgresAppointmentRepository) GetAllWithContract(ctx context.Context, q ListQuery) (*Page[*models.Appointment], error) {
+	return r.detailsView.list(ctx, r.db, q)
+}
+
+// EachWithContract percorre, sem paginação, os mesmos apontamentos de GetAllWithContract (usado nas exportações).
+func (r *postgresAppointmentRepository) EachWithContract(ctx context.Context, q ListQuery, fn func(*models.Appointment) error) error {
+	return r.detailsView.each(ctx, r.db, q, fn)
+}
+
+// scanTimer lê as colunas devolvidas pelas consultas de cronômetro (sem JOIN).
+func scanTimer(row *sql.Row) (*models.Appointment, error) {
+	var a models.Appointment
+	err := row.Scan(&a.ID, &a.ContractID, &a.UserID, &a.TicketID, &a.Description, &a.StartTime, &a.EndTime, &a.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-	return scanAppointments(rows)
+	return &a, nil
 }
 
-func (r *postgresAppointmentRepository) GetByContractID(contractID int64) ([]*models.Appointment, error) {
-	query := `SELECT a.id, a.start_time, a.end_time, a.description,
-	                 EXTRACT(EPOCH FROM (COALESCE(a.end_time, CURRENT_TIMESTAMP) - a.start_time)) / 3600 as total_hours,
-									 EXTRACT(EPOCH FROM (COALESCE(a.end_time, CURRENT_TIMESTAMP) - a.start_time))::bigint as duration_seconds,
-                     c.title, u.name
-              FROM appointments a
-              JOIN contracts c ON a.contract_id = c.id
-              JOIN users u ON a.user_id = u.id
-              WHERE a.contract_id = $1
-              ORDER BY a.created_at DESC`
-
-	rows, err := r.db.QueryContext(context.Background(), query, contractID)
+// GetRunningByUserID devolve o apontamento em andamento do usuário, ou nil se não houver.
+func (r *postgresAppointmentRepository) GetRunningByUserID(ctx context.Context, userID int64) (*models.Appointment, error) {
+	query := `
+		SELECT a.id, a.contract_id, a.user_id, a.ticket_id, a.description, a.start_time, a.end_time, a.created_at,
+		       EXTRACT(EPOCH FROM (CURRENT_TIMESTAMP - a.start_time)) / 3600 as total_hours,
+		       EXTRACT(EPOCH FROM (CURRENT_TIMESTAMP - a.start_time))::bigint as duration_seconds,
+		       c.title, u.name
+		FROM appointments a
+		JOIN contracts c ON a.contract_id = c.id
+		JOIN users u ON a.user_id = u.id
+		WHERE a.user_id = $1 AND a.end_time IS NULL AND a.deleted_at IS NULL`
+
+	var a models.Appointment
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, userID).Scan(
+		&a.ID, &a.ContractID, &a.UserID, &a.TicketID, &a.Description, &a.StartTime, &a.EndTime, &a.CreatedAt,
+		&a.TotalHours, &a.DurationSeconds, &a.ContractTitle, &a.UserName,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("erro ao buscar apontamento em andamento: %w", err)
 	}
-	defer rows.Close()
-	return scanAppointments(rows)
+	return &a, nil
 }
 
-func (r *postgresAppointmentRepository) GetByUserID(userID int64) ([]*models.Appointment, error) {
-	query := `
-		SELECT id, contract_id, user_id, description, start_time, end_time, created_at
-		FROM appointments
-		WHERE user_id = $1
-		ORDER BY start_time DESC`
+// StartTimer para o cronômetro em andamento do usuário (se houver) e inicia o novo
+// no mesmo instante (appt.StartTime), tudo em uma transação. Devolve o apontamento parado.
+func (r *postgresAppointmentRepository) StartTimer(ctx context.Context, appt *models.Appointment) (*models.Appointment, error) {
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Trava o usuário: dois "start" simultâneos do mesmo usuário rodam em fila
+	if _, err := tx.ExecContext(ctx, `SELECT id FROM users WHERE id = $1 FOR UPDATE`, appt.UserID); err != nil {
+		return nil, fmt.Errorf("erro ao travar usuário: %w", err)
+	}
+
+	stopped, err := scanTimer(tx.QueryRowContext(ctx, `
+		UPDATE appointments SET end_time = $1
+		WHERE user_id = $2 AND end_time IS NULL AND deleted_at IS NULL
+		RETURNING id, contract_id, user_id, ticket_id, description, start_time, end_time, created_at`,
+		appt.StartTime, appt.UserID,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao parar cronômetro anterior: %w", err)
+	}
 
-	rows, err := r.db.Query(query, userID)
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO appointments (contract_id, user_id, ticket_id, start_time, end_time, description, is_overrun)
+		VALUES ($1, $2, $3, $4, NULL, $5, $6)
+		RETURNING id, created_at`,
+		appt.ContractID, appt.UserID, appt.TicketID, appt.StartTime, appt.Description, appt.IsOverrun,
+	).Scan(&appt.ID, &appt.CreatedAt)
 	if err != nil {
+		return nil, fmt.Errorf("erro ao iniciar cronômetro: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-	var appointments []*models.Appointment
-
-	for rows.Next() {
-		var appt models.Appointment
-		// Lembre-se: appt.EndTime é um ponteiro (*time.Time) para aceitar NULL do banco
-		if err := rows.Scan(
-			&appt.ID,
-			&appt.ContractID,
-			&appt.UserID,
-			&appt.Description,
-			&appt.StartTime,
-			&appt.EndTime,
-			&appt.CreatedAt,
-		); err != nil {
-			return nil, err
-		}
-		appointments = append(appointments, &appt)
+	return stopped, nil
+}
+
+// StopTimer finaliza um apontamento em andamento. Retorna nil, nil se ele não estiver rodando.
+func (r *postgresAppointmentRepository) StopTimer(ctx context.Context, id int64, at time.Time) (*models.Appointment, error) {
+	stopped, err := scanTimer(conn(ctx, r.db).QueryRowContext(ctx, `
+		UPDATE appointments SET end_time = $1
+		WHERE id = $2 AND end_time IS NULL AND deleted_at IS NULL
+		RETURNING id, contract_id, user_id, ticket_id, description, start_time, end_time, created_at`,
+		at, id,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao parar cronômetro: %w", err)
 	}
-	return appointments, nil
+	return stopped, nil
 }
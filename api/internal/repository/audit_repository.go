@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"nexus/internal/models"
+)
+
+// AuditRepository grava e consulta a trilha de auditoria.
+// Só insere: entradas de auditoria nunca são alteradas nem removidas pela API.
+type AuditRepository interface {
+	Record(ctx context.Context, entry *models.AuditEntry) error
+	List(ctx context.Context, q ListQuery) (*Page[*models.AuditEntry], error)
+}
+
+type postgresAuditRepository struct {
+	db   *sql.DB
+	view *listView[*models.AuditEntry]
+}
+
+// NewAuditRepository cria uma nova instância do repositório de auditoria.
+func NewAuditRepository(db *sql.DB) AuditRepository {
+	view := newListView("al", `SELECT al.id, al.actor_id, al.occurred_at, al.entity, al.entity_id, al.operation,
+	                 al.changes::text, COALESCE(u.name, '')`,
+		`FROM audit_log al
+	     LEFT JOIN users u ON al.actor_id = u.id`,
+		scanAuditEntry,
+	).
+		withColumn("actorName", "u.name").
+		withPeriod("occurredAt").
+		withDefaultSort("-occurredAt", "-id")
+
+	return &postgresAuditRepository{db: db, view: view}
+}
+
+func scanAuditEntry(rows *sql.Rows) (*models.AuditEntry, error) {
+	var e models.AuditEntry
+	var changes []byte
+	if err := rows.Scan(&e.ID, &e.ActorID, &e.OccurredAt, &e.Entity, &e.EntityID, &e.Operation,
+		&changes, &e.ActorName); err != nil {
+		return nil, err
+	}
+	e.Changes = changes
+	return &e, nil
+}
+
+// Record grava uma entrada de auditoria, preenchendo ID. Dentro de uma transação o INSERT vai
+// num savepoint: se falhar, só ele é desfeito e a transação de quem chamou segue válida.
+func (r *postgresAuditRepository) Record(ctx context.Context, entry *models.AuditEntry) error {
+	changes := "{}"
+	if len(entry.Changes) > 0 {
+		changes = string(entry.Changes)
+	}
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("erro ao gravar auditoria: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO audit_log (actor_id, occurred_at, entity, entity_id, operation, changes)
+	          VALUES ($1, $2, $3, $4, $5, $6::jsonb) RETURNING id`
+	err = tx.QueryRowContext(ctx, query,
+		entry.ActorID, entry.OccurredAt, entry.Entity, entry.EntityID, entry.Operation, changes,
+	).Scan(&entry.ID)
+	if err != nil {
+		return fmt.Errorf("erro ao gravar auditoria: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao gravar auditoria: %w", err)
+	}
+	return nil
+}
+
+// List lista a trilha com o nome do autor. Filtros comuns: entity, entityId, actorId, operation e from/to sobre occurredAt.
+func (r *postgresAuditRepository) List(ctx context.Context, q ListQuery) (*Page[*models.AuditEntry], error) {
+	return r.view.list(ctx, r.db, q)
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
@@ -12,26 +13,62 @@ import (
 
 // Repository é uma interface para operações de banco de dados genéricas.
 // O tipo T deve ser um ponteiro para uma struct que implementa models.Model.
+// Todos os métodos recebem o contexto da requisição (r.Context()): cliente desconectado
+// ou prazo estourado cancela a consulta no banco.
+//
+// Modelos com a coluna deleted_at (tag db) têm remoção lógica: Delete só marca a data,
+// Get/Update/List ignoram os removidos (List os inclui com ListQuery.IncludeDeleted),
+// Restore desfaz a remoção e Purge apaga de vez um registro já removido.
 type Repository[T models.Model] interface {
-	Save(model T) (T, error)
-	Get(id *int64) ([]T, error)
-	Update(model T) (int64, error)
-	Delete(id int64) (int64, error)
+	Save(ctx context.Context, model T) (T, error)
+	Get(ctx context.Context, id *int64) ([]T, error)
+	Update(ctx context.Context, model T) (int64, error)
+	Delete(ctx context.Context, id int64) (int64, error)
+	Restore(ctx context.Context, id int64) (int64, error)
+	Purge(ctx context.Context, id int64) (int64, error)
+	List(ctx context.Context, q ListQuery) (*Page[T], error)
 	GetTableName() string
 }
 
+// ErrNotSoftDeletable indica restore/purge em um modelo sem a coluna deleted_at.
+var ErrNotSoftDeletable = errors.New("este registro não tem remoção lógica")
+
+// deletedAtColumn é a coluna da remoção lógica, controlada só pelo repositório.
+const deletedAtColumn = "deleted_at"
+
 // postgresRepository é a implementação da interface Repository para o PostgreSQL.
 type postgresRepository[T models.Model] struct {
-	db        *sql.DB
-	tableName string
+	db         *sql.DB
+	tableName  string
+	view       *listView[T]
+	softDelete bool // O modelo tem a coluna deleted_at
 }
 
 // NewPostgresRepository cria uma nova instância de postgresRepository.
 func NewPostgresRepository[T models.Model](db *sql.DB, tableName string) Repository[T] {
-	return &postgresRepository[T]{
+	r := &postgresRepository[T]{
 		db:        db,
 		tableName: tableName,
 	}
+
+	// Listagem padrão: todas as colunas (tag db) da própria tabela
+	var cols []string
+	for i := 0; i < modelType[T]().NumField(); i++ {
+		if dbTag := strings.Split(modelType[T]().Field(i).Tag.Get("db"), ",")[0]; dbTag != "" {
+			cols = append(cols, dbTag)
+			r.softDelete = r.softDelete || dbTag == deletedAtColumn
+		}
+	}
+	r.view = newListView[T]("", "SELECT "+strings.Join(cols, ", "), "FROM "+tableName, scanModel[T])
+	return r
+}
+
+// notDeleted é a condição extra das consultas em modelos com remoção lógica.
+func (r *postgresRepository[T]) notDeleted() string {
+	if r.softDelete {
+		return " AND " + deletedAtColumn + " IS NULL"
+	}
+	return ""
 }
 
 func (r *postgresRepository[T]) GetTableName() string {
@@ -39,7 +76,7 @@ func (r *postgresRepository[T]) GetTableName() string {
 }
 
 // Save insere um novo modelo no banco de dados.
-func (r *postgresRepository[T]) Save(model T) (T, error) {
+func (r *postgresRepository[T]) Save(ctx context.Context, model T) (T, error) {
 	val := reflect.ValueOf(model).Elem()
 	typ := val.Type()
 
@@ -52,6 +89,11 @@ func (r *postgresRepository[T]) Save(model T) (T, error) {
 		}
 		// Usamos o nome da tag db como nome da coluna
 		dbTag := strings.Split(field.Tag.Get("db"), ",")[0]
+		if dbTag == deletedAtColumn {
+			// Só Delete/Restore mexem na remoção lógica
+			val.Field(i).SetZero()
+			continue
+		}
 		if dbTag != "" {
 			cols = append(cols, dbTag)
 			values = append(values, val.Field(i).Interface())
@@ -70,7 +112,7 @@ func (r *postgresRepository[T]) Save(model T) (T, error) {
 	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) RETURNING id", r.tableName, colNames, placeholders)
 
 	var id int64
-	err := r.db.QueryRowContext(context.Background(), query, values...).Scan(&id)
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, values...).Scan(&id)
 	if err != nil {
 		return model, fmt.Errorf("erro ao inserir no banco de dados: %w", err)
 	}
@@ -80,27 +122,29 @@ func (r *postgresRepository[T]) Save(model T) (T, error) {
 }
 
 // Get recupera um ou todos os modelos do banco de dados.
-func (r *postgresRepository[T]) Get(id *int64) ([]T, error) {
+func (r *postgresRepository[T]) Get(ctx context.Context, id *int64) ([]T, error) {
 	var t T
 	typ := reflect.TypeOf(t).Elem()
 
 	var cols []string
+	var fieldIndexes []int
 	for i := 0; i < typ.NumField(); i++ {
 		dbTag := strings.Split(typ.Field(i).Tag.Get("db"), ",")[0]
 		if dbTag != "" {
 			cols = append(cols, dbTag)
+			fieldIndexes = append(fieldIndexes, i)
 		}
 	}
 	colNames := strings.Join(cols, ", ")
 
-	query := fmt.Sprintf("SELECT %s FROM %s", colNames, r.tableName)
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE true%s", colNames, r.tableName, r.notDeleted())
 	args := []interface{}{}
 	if id != nil {
-		query += " WHERE id = $1"
+		query += " AND id = $1"
 		args = append(args, *id)
 	}
 
-	rows, err := r.db.QueryContext(context.Background(), query, args...)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao consultar o banco de dados: %w", err)
 	}
@@ -111,10 +155,11 @@ func (r *postgresRepository[T]) Get(id *int64) ([]T, error) {
 		newElemPtr := reflect.New(typ)
 		result := newElemPtr.Interface().(T)
 
+		// Só escaneia os campos com tag db (campos calculados ou de entrada ficam de fora)
 		resultValue := newElemPtr.Elem()
-		scanArgs := make([]interface{}, resultValue.NumField())
-		for i := 0; i < resultValue.NumField(); i++ {
-			scanArgs[i] = resultValue.Field(i).Addr().Interface()
+		scanArgs := make([]interface{}, len(fieldIndexes))
+		for i, fieldIndex := range fieldIndexes {
+			scanArgs[i] = resultValue.Field(fieldIndex).Addr().Interface()
 		}
 
 		if err := rows.Scan(scanArgs...); err != nil {
@@ -130,8 +175,31 @@ func (r *postgresRepository[T]) Get(id *int64) ([]T, error) {
 	return results, nil
 }
 
+// List lista os modelos com filtros, ordenação e paginação (ver ListQuery).
+func (r *postgresRepository[T]) List(ctx context.Context, q ListQuery) (*Page[T], error) {
+	return r.view.list(ctx, r.db, q)
+}
+
+// scanModel escaneia uma linha com todas as colunas (tag db) do modelo, na ordem da struct.
+func scanModel[T models.Model](rows *sql.Rows) (T, error) {
+	typ := modelType[T]()
+	newElemPtr := reflect.New(typ)
+	result := newElemPtr.Interface().(T)
+
+	var scanArgs []interface{}
+	for i := 0; i < typ.NumField(); i++ {
+		if dbTag := strings.Split(typ.Field(i).Tag.Get("db"), ",")[0]; dbTag != "" {
+			scanArgs = append(scanArgs, newElemPtr.Elem().Field(i).Addr().Interface())
+		}
+	}
+	if err := rows.Scan(scanArgs...); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
 // Update atualiza um modelo existente no banco de dados.
-func (r *postgresRepository[T]) Update(model T) (int64, error) {
+func (r *postgresRepository[T]) Update(ctx context.Context, model T) (int64, error) {
 	val := reflect.ValueOf(model).Elem()
 	typ := val.Type()
 
@@ -144,6 +212,10 @@ func (r *postgresRepository[T]) Update(model T) (int64, error) {
 			continue
 		}
 		dbTag := strings.Split(field.Tag.Get("db"), ",")[0]
+		if dbTag == deletedAtColumn {
+			val.Field(i).SetZero()
+			continue
+		}
 		if dbTag != "" {
 			setClauses = append(setClauses, fmt.Sprintf("%s = $%d", dbTag, argCount))
 			values = append(values, val.Field(i).Interface())
@@ -153,20 +225,51 @@ func (r *postgresRepository[T]) Update(model T) (int64, error) {
 
 	values = append(values, model.GetID())
 
-	query := fmt.Sprintf("UPDATE %s SET %s WHERE id = $%d", r.tableName, strings.Join(setClauses, ", "), argCount)
-	res, err := r.db.ExecContext(context.Background(), query, values...)
+	// Registro removido não é alterado (conta como não encontrado)
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE id = $%d%s", r.tableName, strings.Join(setClauses, ", "), argCount, r.notDeleted())
+	res, err := conn(ctx, r.db).ExecContext(ctx, query, values...)
 	if err != nil {
 		return 0, fmt.Errorf("erro ao atualizar no banco de dados: %w", err)
 	}
 	return res.RowsAffected()
 }
 
-// Delete remove um modelo do banco de dados.
-func (r *postgresRepository[T]) Delete(id int64) (int64, error) {
+// Delete remove um modelo: com remoção lógica, só marca deleted_at (0 se já estava removido).
+func (r *postgresRepository[T]) Delete(ctx context.Context, id int64) (int64, error) {
 	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1", r.tableName)
-	res, err := r.db.ExecContext(context.Background(), query, id)
+	if r.softDelete {
+		query = fmt.Sprintf("UPDATE %s SET %s = CURRENT_TIMESTAMP WHERE id = $1 AND %[2]s IS NULL", r.tableName, deletedAtColumn)
+	}
+	res, err := conn(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return 0, fmt.Errorf("erro ao deletar no banco de dados: %w", err)
 	}
 	return res.RowsAffected()
 }
+
+// Restore desfaz a remoção lógica. Devolve 0 se o registro não existir ou não estiver removido.
+func (r *postgresRepository[T]) Restore(ctx context.Context, id int64) (int64, error) {
+	if !r.softDelete {
+		return 0, ErrNotSoftDeletable
+	}
+	query := fmt.Sprintf("UPDATE %s SET %s = NULL WHERE id = $1 AND %[2]s IS NOT NULL", r.tableName, deletedAtColumn)
+	res, err := conn(ctx, r.db).ExecContext(ctx, query, id)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao restaurar no banco de dados: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// Purge apaga de vez um registro que já foi removido logicamente (0 se não existir ou estiver ativo).
+// As chaves estrangeiras com ON DELETE RESTRICT recusam o purge enquanto houver registros ligados.
+func (r *postgresRepository[T]) Purge(ctx context.Context, id int64) (int64, error) {
+	if !r.softDelete {
+		return 0, ErrNotSoftDeletable
+	}
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1 AND %s IS NOT NULL", r.tableName, deletedAtColumn)
+	res, err := conn(ctx, r.db).ExecContext(ctx, query, id)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao apagar no banco de dados: %w", err)
+	}
+	return res.RowsAffected()
+}
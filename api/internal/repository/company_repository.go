@@ -10,7 +10,7 @@ import (
 // CompanyRepository define a interface para as operações com empresas.
 type CompanyRepository interface {
 	Repository[*models.Company]
-	SaveBatch(companies []*models.Company) ([]*models.Company, error)
+	SaveBatch(ctx context.Context, companies []*models.Company) ([]*models.Company, error)
 }
 
 // postgresCompanyRepository é a implementação da interface para o PostgreSQL.
@@ -28,14 +28,14 @@ func NewCompanyRepository(db *sql.DB) CompanyRepository {
 }
 
 // SaveBatch salva uma lista de empresas em uma única transação.
-func (r *postgresCompanyRepository) SaveBatch(companies []*models.Company) ([]*models.Company, error) {
-	tx, err := r.db.Begin()
+func (r *postgresCompanyRepository) SaveBatch(ctx context.Context, companies []*models.Company) ([]*models.Company, error) {
+	tx, err := begin(ctx, r.db)
 	if err != nil {
 		return nil, err
 	}
 	defer tx.Rollback()
 
-	stmt, err := tx.PrepareContext(context.Background(), `INSERT INTO companies (name
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO companies (name
 	                                                                            ,cnpj
 																																					    ,contact_email)
 																												VALUES ($1
@@ -51,7 +51,7 @@ func (r *postgresCompanyRepository) SaveBatch(companies []*models.Company) ([]*m
 	var companiesSaved []*models.Company
 	for _, company := range companies {
 		var newID int64
-		err := stmt.QueryRowContext(context.Background(), company.Name, company.CNPJ, company.ContactEmail).Scan(&newID)
+		err := stmt.QueryRowContext(ctx, company.Name, company.CNPJ, company.ContactEmail).Scan(&newID)
 		if err != nil {
 			return nil, err
 		}
@@ -4,100 +4,177 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
+
 	"nexus/internal/models"
 )
 
 // ContractRepository define a interface para as operações com contratos.
 type ContractRepository interface {
 	Repository[*models.Contract]
-	GetByCompanyID(companyID int64) ([]*models.Contract, error)
-	GetAllWithCompany() ([]*models.Contract, error)
-	Delete(id int64) (int64, error)
+	GetAllWithCompany(ctx context.Context, q ListQuery) (*Page[*models.Contract], error)
+	GetBalance(ctx context.Context, id int64, at time.Time) (*models.ContractBalance, error)
+	GetBalances(ctx context.Context, ids []int64, at time.Time) (map[int64]*models.ContractBalance, error)
 }
 
 // postgresContractRepository é a implementação da interface para o PostgreSQL.
 type postgresContractRepository struct {
 	Repository[*models.Contract]
-	db *sql.DB
+	db          *sql.DB
+	companyView *listView[*models.Contract]
 }
 
 // NewContractRepository cria uma nova instância do repositório de contratos.
 func NewContractRepository(db *sql.DB) ContractRepository {
+	companyView := newListView("contracts", `
+		SELECT contracts.id
+		      ,contracts.company_id
+		      ,companies.name
+		      ,contracts.title
+		      ,contracts.contract_type
+		      ,COALESCE(contracts.total_hours,0)
+		      ,contracts.rollover_cap_hours
+		      ,contracts.start_date
+		      ,contracts.end_date
+		      ,contracts.is_active
+		      ,contracts.overrun_policy
+		      ,contracts.sla_policy_id
+		      ,contracts.deleted_at`,
+		`FROM contracts
+		     INNER JOIN companies
+		     ON contracts.company_id = companies.id`,
+		scanContractWithCompany,
+	).
+		withColumn("companyName", "companies.name").
+		withPeriod("startDate").
+		withDefaultSort("-id")
+
 	return &postgresContractRepository{
-		Repository: NewPostgresRepository[*models.Contract](db, "contracts"),
-		db:         db,
+		Repository:  NewPostgresRepository[*models.Contract](db, "contracts"),
+		db:          db,
+		companyView: companyView,
 	}
 }
 
-func (r *postgresContractRepository) GetAllWithCompany() ([]*models.Contract, error) {
-	query := `
-		SELECT contracts.id
-		      ,contracts.company_id
-					,companies.name
-					,contracts.contract_type
-					,COALESCE(contracts.total_hours,0)
-					,contracts.start_date
-					,contracts.end_date
-					,contracts.is_active
-		FROM contracts
-		     INNER JOIN companies
-		     ON contracts.company_id = companies.id
-		ORDER BY contracts.created_at DESC
-	`
+// GetAllWithCompany lista contratos com o nome da empresa (JOIN). Filtros comuns: companyId, isActive.
+func (r *postgresContractRepository) GetAllWithCompany(ctx context.Context, q ListQuery) (*Page[*models.Contract], error) {
+	return r.companyView.list(ctx, r.db, q)
+}
 
-	rows, err := r.db.QueryContext(context.Background(), query)
-	if err != nil {
+func scanContractWithCompany(rows *sql.Rows) (*models.Contract, error) {
+	var c models.Contract
+	if err := rows.Scan(
+		&c.ID, &c.CompanyId, &c.CompanyName, &c.Title,
+		&c.ContractType, &c.TotalHours, &c.RolloverCapHours, &c.StartDate, &c.EndDate, &c.IsActive, &c.OverrunPolicy, &c.SLAPolicyID,
+		&c.DeletedAt,
+	); err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	return &c, nil
+}
 
-	var contracts []*models.Contract
-	for rows.Next() {
-		var c models.Contract
-		if err := rows.Scan(
-			&c.ID, &c.CompanyId, &c.CompanyName,
-			&c.ContractType, &c.TotalHours, &c.StartDate, &c.EndDate, &c.IsActive,
-		); err != nil {
-			return nil, err
-		}
-		// Regra de negócio: Montar o Título para o Front
-		c.Title = fmt.Sprintf("%s - %s", c.CompanyName, c.ContractType)
+// balanceQuery soma as horas de cada contrato por período, até o período que contém a data $2
+// (limitada à vigência): o consumo e a parte dele dentro da janela recente ($1 dias).
+// No banco de horas os períodos são mensais a partir de start_date (o PostgreSQL ajusta o dia
+// ao fim do mês: 31/01 + 1 mês = 28/02) e cada apontamento conta no período do seu início.
+// Nos outros tipos há um período só, a vigência inteira. Apontamentos em andamento contam até agora.
+const balanceQuery = `
+	SELECT c.id
+	      ,c.contract_type
+	      ,c.total_hours
+	      ,c.rollover_cap_hours
+	      ,p.period_start
+	      ,p.period_end
+	      ,COALESCE(SUM(EXTRACT(EPOCH FROM (COALESCE(a.end_time, CURRENT_TIMESTAMP) - a.start_time))), 0) / 3600
+	      ,COALESCE(SUM(EXTRACT(EPOCH FROM (COALESCE(a.end_time, CURRENT_TIMESTAMP) - GREATEST(a.start_time, CURRENT_TIMESTAMP - make_interval(days => $1)))))
+	                FILTER (WHERE COALESCE(a.end_time, CURRENT_TIMESTAMP) > CURRENT_TIMESTAMP - make_interval(days => $1)), 0) / 3600
+	FROM contracts c
+	     CROSS JOIN LATERAL (
+	         SELECT c.start_date + n * interval '1 month' AS period_start
+	               ,LEAST(c.start_date + (n + 1) * interval '1 month', c.end_date + 1) AS period_end
+	         FROM generate_series(0, CASE WHEN c.contract_type = 'hour_bank'
+	                  THEN (EXTRACT(YEAR FROM age(c.end_date, c.start_date)) * 12 + EXTRACT(MONTH FROM age(c.end_date, c.start_date)))::int + 1
+	                  ELSE 0 END) n
+	         WHERE c.start_date + n * interval '1 month' <= GREATEST(LEAST($2::date, c.end_date), c.start_date)
+	     ) p
+	     LEFT JOIN appointments a
+	     ON a.contract_id = c.id
+	    AND a.deleted_at IS NULL
+	    AND (c.contract_type <> 'hour_bank' OR (a.start_time >= p.period_start AND a.start_time < p.period_end))
+`
 
-		contracts = append(contracts, &c)
+const balanceGroupBy = " GROUP BY c.id, p.period_start, p.period_end ORDER BY c.id, p.period_start"
+
+// GetBalance calcula o saldo de horas de um contrato no período que contém at.
+// Retorna nil, nil se o contrato não existir.
+func (r *postgresContractRepository) GetBalance(ctx context.Context, id int64, at time.Time) (*models.ContractBalance, error) {
+	balances, err := r.balances(ctx, balanceQuery+" WHERE c.id = $3"+balanceGroupBy,
+		models.BurnRateWindowDays, at.Format(time.DateOnly), id)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao calcular saldo do contrato: %w", err)
+	}
+	return balances[id], nil
+}
+
+// GetBalances calcula de uma vez o saldo dos contratos ids (ex.: os da página listada) no período
+// que contém at, indexado pelo ID do contrato.
+func (r *postgresContractRepository) GetBalances(ctx context.Context, ids []int64, at time.Time) (map[int64]*models.ContractBalance, error) {
+	if len(ids) == 0 {
+		return map[int64]*models.ContractBalance{}, nil
+	}
+	balances, err := r.balances(ctx, balanceQuery+" WHERE c.id = ANY($3)"+balanceGroupBy,
+		models.BurnRateWindowDays, at.Format(time.DateOnly), ids)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao calcular saldos: %w", err)
 	}
-	return contracts, nil
+	return balances, nil
 }
 
-func (r *postgresContractRepository) GetByCompanyID(companyID int64) ([]*models.Contract, error) {
-	query := "SELECT id, company_id, contract_type, total_hours, start_date, end_date, is_active FROM contracts WHERE company_id = $1"
-	rows, err := r.db.QueryContext(context.Background(), query, companyID)
+// balances percorre os períodos de cada contrato em ordem, levando a sobra de um período do
+// banco de horas para o seguinte (até o teto do contrato). Fica o saldo do último período.
+func (r *postgresContractRepository) balances(ctx context.Context, query string, args ...any) (map[int64]*models.ContractBalance, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var contracts []*models.Contract
+	balances := make(map[int64]*models.ContractBalance)
 	for rows.Next() {
-		var contract models.Contract
-		if err := rows.Scan(&contract.ID, &contract.CompanyId, &contract.ContractType, &contract.TotalHours, &contract.StartDate, &contract.EndDate, &contract.IsActive); err != nil {
+		var (
+			id                      int64
+			contractType            string
+			totalHours, rolloverCap int
+			periodStart, periodEnd  time.Time
+			consumed, recent        float64
+		)
+		if err := rows.Scan(&id, &contractType, &totalHours, &rolloverCap, &periodStart, &periodEnd, &consumed, &recent); err != nil {
 			return nil, err
 		}
-		contracts = append(contracts, &contract)
-	}
-	return contracts, rows.Err()
-}
 
-// Delete customizado para Contrato: desativa em vez de deletar.
-func (r *postgresContractRepository) Delete(id int64) (int64, error) {
-	query := `UPDATE contracts SET is_active = false WHERE id = $1`
-	res, err := r.db.ExecContext(context.Background(), query, id)
-	if err != nil {
-		return 0, err
+		b, ok := balances[id]
+		if !ok {
+			b = &models.ContractBalance{ContractID: id, ContractType: contractType}
+			balances[id] = b
+		} else {
+			// Novo período: a sobra do anterior passa adiante, limitada ao teto (estouro não é descontado)
+			b.RolloverHours = min(max(b.ContractedHours-b.ConsumedHours, 0), float64(rolloverCap))
+		}
+		b.ContractedHours = float64(totalHours) + b.RolloverHours
+		b.ConsumedHours = consumed
+		b.RecentHours += recent
+		if contractType == models.ContractHourBank {
+			lastDay := periodEnd.AddDate(0, 0, -1)
+			b.PeriodStart, b.PeriodEnd = &periodStart, &lastDay
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	rowsAffected, err := res.RowsAffected()
-	if err != nil {
-		return 0, err
+	now := time.Now()
+	for _, b := range balances {
+		b.Calculate(now)
 	}
-	return rowsAffected, nil
+	return balances, nil
 }
@@ -0,0 +1,438 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"nexus/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrInvoiceNotDraft indica uma alteração de itens em fatura que já não é rascunho.
+var ErrInvoiceNotDraft = errors.New("a fatura não é mais rascunho")
+
+// InvoiceRepository define as operações com faturas.
+type InvoiceRepository interface {
+	Repository[*models.Invoice]
+	GetAllWithDetails(ctx context.Context, q ListQuery) (*Page[*models.Invoice], error)
+	GetWithDetails(ctx context.Context, id int64) (*models.Invoice, error)
+	CreateDraft(ctx context.Context, invoice *models.Invoice) (*models.Invoice, error)
+	AddLine(ctx context.Context, invoiceID int64, line *models.InvoiceLine) (*models.InvoiceLine, error)
+	DeleteLine(ctx context.Context, invoiceID, lineID int64) (int64, error)
+	DeleteDraft(ctx context.Context, id int64) (int64, error)
+	Transition(ctx context.Context, id int64, status string, at time.Time) (*models.Invoice, error)
+}
+
+type postgresInvoiceRepository struct {
+	Repository[*models.Invoice]
+	db          *sql.DB
+	detailsView *listView[*models.Invoice]
+	linesView   *listView[*models.InvoiceLine]
+}
+
+// NewInvoiceRepository cria uma nova instância do repositório de faturas.
+func NewInvoiceRepository(db *sql.DB) InvoiceRepository {
+	detailsView := newListView("i", `SELECT i.id, i.company_id, i.status, i.year, i.number, i.period_start, i.period_end,
+	                 i.notes, i.total, i.created_at, i.issued_at, i.paid_at, i.voided_at, co.name`,
+		`FROM invoices i
+	     JOIN companies co ON i.company_id = co.id`,
+		scanInvoice,
+	).
+		withColumn("companyName", "co.name").
+		withPeriod("periodStart").
+		withDefaultSort("-createdAt")
+
+	linesView := newListView("l", `SELECT l.id, l.invoice_id, l.kind, l.description, l.contract_id, l.user_id,
+	                 l.quantity, l.unit_price, l.amount`,
+		`FROM invoice_lines l`,
+		scanInvoiceLine,
+	)
+
+	return &postgresInvoiceRepository{
+		Repository:  NewPostgresRepository[*models.Invoice](db, "invoices"),
+		db:          db,
+		detailsView: detailsView,
+		linesView:   linesView,
+	}
+}
+
+func scanInvoice(rows *sql.Rows) (*models.Invoice, error) {
+	var i models.Invoice
+	err := rows.Scan(
+		&i.ID, &i.CompanyID, &i.Status, &i.Year, &i.Number, &i.PeriodStart, &i.PeriodEnd,
+		&i.Notes, &i.Total, &i.CreatedAt, &i.IssuedAt, &i.PaidAt, &i.VoidedAt, &i.CompanyName,
+	)
+	i.FillCode()
+	return &i, err
+}
+
+func scanInvoiceLine(rows *sql.Rows) (*models.InvoiceLine, error) {
+	var l models.InvoiceLine
+	err := rows.Scan(&l.ID, &l.InvoiceID, &l.Kind, &l.Description, &l.ContractID, &l.UserID,
+		&l.Quantity, &l.UnitPrice, &l.Amount)
+	return &l, err
+}
+
+// GetAllWithDetails lista faturas com o nome da empresa (sem os itens).
+// Filtros comuns: companyId, status, year e o período (from/to) sobre periodStart.
+func (r *postgresInvoiceRepository) GetAllWithDetails(ctx context.Context, q ListQuery) (*Page[*models.Invoice], error) {
+	return r.detailsView.list(ctx, r.db, q)
+}
+
+// GetWithDetails busca a fatura com a empresa e os itens. Devolve nil, nil se não existir.
+func (r *postgresInvoiceRepository) GetWithDetails(ctx context.Context, id int64) (*models.Invoice, error) {
+	filter := map[string]string{"id": strconv.FormatInt(id, 10)}
+	page, err := r.detailsView.list(ctx, r.db, ListQuery{PageSize: 1, Filters: filter})
+	if err != nil {
+		return nil, err
+	}
+	if len(page.Items) == 0 {
+		return nil, nil
+	}
+	invoice := page.Items[0]
+
+	invoice.Lines = []*models.InvoiceLine{}
+	err = r.linesView.each(ctx, r.db, ListQuery{
+		Filters: map[string]string{"invoiceId": strconv.FormatInt(id, 10)},
+	}, func(l *models.InvoiceLine) error {
+		invoice.Lines = append(invoice.Lines, l)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+// billableGroup são as horas de um consultor em um contrato a um mesmo valor-hora.
+type billableGroup struct {
+	contractID     int64
+	userID         int64
+	description    string
+	rate           decimal.Decimal
+	seconds        int64
+	appointmentIDs []int64
+}
+
+// CreateDraft grava a fatura como rascunho e já inclui, em uma transação:
+//   - um item de horas por contrato × consultor × valor-hora, com os apontamentos finalizados,
+//     de semanas aprovadas e ainda não faturados da empresa no período;
+//   - os itens manuais que vierem em invoice.Lines.
+//
+// Os apontamentos cobrados ficam ligados ao item (invoice_line_id) e não entram em outra fatura.
+// Eles são travados na leitura, então duas faturas montadas ao mesmo tempo não cobram a mesma hora.
+// Horas sem valor-hora vigente ficam de fora e viram aviso em Warnings.
+func (r *postgresInvoiceRepository) CreateDraft(ctx context.Context, invoice *models.Invoice) (*models.Invoice, error) {
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO invoices (company_id, status, period_start, period_end, notes, total, created_at)
+		VALUES ($1, 'draft', $2, $3, $4, 0, $5)
+		RETURNING id`,
+		invoice.CompanyID, invoice.PeriodStart, invoice.PeriodEnd, invoice.Notes, invoice.CreatedAt,
+	).Scan(&invoice.ID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar fatura: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT a.id, a.contract_id, a.user_id, c.title, u.name, rate.hourly_rate,
+		       EXTRACT(EPOCH FROM (a.end_time - a.start_time))::bigint
+		FROM appointments a
+		JOIN contracts c ON a.contract_id = c.id
+		JOIN users u ON a.user_id = u.id
+		JOIN timesheets ts ON ts.user_id = a.user_id AND ts.week_start = date_trunc('week', a.start_time)::date
+		LEFT JOIN LATERAL (
+		    SELECT br.hourly_rate
+		    FROM billing_rates br
+		    WHERE br.contract_id = a.contract_id
+		      AND br.deleted_at IS NULL
+		      AND (br.user_id = a.user_id OR br.user_id IS NULL)
+		      AND br.valid_from <= a.start_time::date
+		      AND (br.valid_to IS NULL OR br.valid_to >= a.start_time::date)
+		    ORDER BY br.user_id NULLS LAST
+		    LIMIT 1
+		) rate ON true
+		WHERE c.company_id = $1
+		  AND ts.status = 'approved'
+		  AND a.end_time IS NOT NULL
+		  AND a.invoice_line_id IS NULL
+		  AND a.deleted_at IS NULL
+		  AND a.start_time >= $2 AND a.start_time < $3
+		ORDER BY c.title, u.name, a.start_time
+		FOR UPDATE OF a`,
+		invoice.CompanyID, invoice.PeriodStart, invoice.PeriodEnd.AddDate(0, 0, 1),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar horas faturáveis: %w", err)
+	}
+	var groups []*billableGroup
+	index := make(map[string]*billableGroup)
+	var unratedSeconds int64
+	for rows.Next() {
+		var apptID, contractID, userID, seconds int64
+		var contractTitle, userName string
+		var rate decimal.NullDecimal
+		if err := rows.Scan(&apptID, &contractID, &userID, &contractTitle, &userName, &rate, &seconds); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if !rate.Valid {
+			unratedSeconds += seconds
+			continue
+		}
+		key := fmt.Sprintf("%d/%d/%s", contractID, userID, rate.Decimal.String())
+		group, ok := index[key]
+		if !ok {
+			group = &billableGroup{
+				contractID:  contractID,
+				userID:      userID,
+				description: "Horas: " + contractTitle + " - " + userName,
+				rate:        rate.Decimal,
+			}
+			index[key] = group
+			groups = append(groups, group)
+		}
+		group.seconds += seconds
+		group.appointmentIDs = append(group.appointmentIDs, apptID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	secondsPerHour := decimal.NewFromInt(3600)
+	for _, g := range groups {
+		line := &models.InvoiceLine{
+			Kind:        models.InvoiceLineHours,
+			Description: g.description,
+			ContractID:  &g.contractID,
+			UserID:      &g.userID,
+			Quantity:    decimal.NewFromInt(g.seconds).DivRound(secondsPerHour, 2),
+			UnitPrice:   g.rate,
+		}
+		if err := insertLine(ctx, tx, invoice.ID, line); err != nil {
+			return nil, err
+		}
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE appointments SET invoice_line_id = $1 WHERE id = ANY($2)`, line.ID, g.appointmentIDs,
+		); err != nil {
+			return nil, fmt.Errorf("erro ao marcar apontamentos como faturados: %w", err)
+		}
+	}
+	for _, line := range invoice.Lines {
+		line.Kind = models.InvoiceLineManual
+		if err := insertLine(ctx, tx, invoice.ID, line); err != nil {
+			return nil, err
+		}
+	}
+	if err := updateTotal(ctx, tx, invoice.ID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	saved, err := r.GetWithDetails(ctx, invoice.ID)
+	if err != nil || saved == nil {
+		return saved, err
+	}
+	if unratedSeconds > 0 {
+		hours := decimal.NewFromInt(unratedSeconds).DivRound(secondsPerHour, 2)
+		saved.Warnings = append(saved.Warnings, hours.String()+" h aprovadas sem valor-hora vigente ficaram fora da fatura")
+	}
+	return saved, nil
+}
+
+// AddLine inclui um item manual em uma fatura rascunho e recalcula o total.
+// Devolve ErrInvoiceNotDraft se a fatura já foi emitida/cancelada e nil, nil se ela não existir.
+func (r *postgresInvoiceRepository) AddLine(ctx context.Context, invoiceID int64, line *models.InvoiceLine) (*models.InvoiceLine, error) {
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if found, err := lockDraft(ctx, tx, invoiceID); err != nil || !found {
+		return nil, err
+	}
+	line.Kind = models.InvoiceLineManual
+	if err := insertLine(ctx, tx, invoiceID, line); err != nil {
+		return nil, err
+	}
+	if err := updateTotal(ctx, tx, invoiceID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return line, nil
+}
+
+// DeleteLine remove um item de uma fatura rascunho e recalcula o total.
+// Os apontamentos de um item de horas voltam a ficar disponíveis para faturar (ON DELETE SET NULL).
+// Devolve ErrInvoiceNotDraft se a fatura não for rascunho e 0 se a fatura ou o item não existirem.
+func (r *postgresInvoiceRepository) DeleteLine(ctx context.Context, invoiceID, lineID int64) (int64, error) {
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if found, err := lockDraft(ctx, tx, invoiceID); err != nil || !found {
+		return 0, err
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM invoice_lines WHERE id = $1 AND invoice_id = $2`, lineID, invoiceID)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao remover item da fatura: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if err := updateTotal(ctx, tx, invoiceID); err != nil {
+		return 0, err
+	}
+	return affected, tx.Commit()
+}
+
+// DeleteDraft apaga uma fatura rascunho (itens em cascata, apontamentos liberados).
+// Devolve ErrInvoiceNotDraft se ela já foi emitida e 0 se não existir.
+func (r *postgresInvoiceRepository) DeleteDraft(ctx context.Context, id int64) (int64, error) {
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if found, err := lockDraft(ctx, tx, id); err != nil || !found {
+		return 0, err
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM invoices WHERE id = $1`, id)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao apagar fatura: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return affected, tx.Commit()
+}
+
+// Transition muda o status da fatura, só se o status atual permitir (ver models.InvoiceTransitionsTo):
+//   - issued: atribui o próximo número do ano da emissão;
+//   - paid: carimba paid_at;
+//   - void: carimba voided_at e libera os apontamentos para outra fatura (os itens ficam como histórico).
+//
+// Devolve ErrInvalidTransition se o status atual não permitir e nil, nil se a fatura não existir.
+func (r *postgresInvoiceRepository) Transition(ctx context.Context, id int64, status string, at time.Time) (*models.Invoice, error) {
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var current string
+	err = tx.QueryRowContext(ctx, `SELECT status FROM invoices WHERE id = $1 FOR UPDATE`, id).Scan(&current)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar fatura: %w", err)
+	}
+	if !slices.Contains(models.InvoiceTransitionsTo(status), current) {
+		invoice, err := r.GetWithDetails(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return invoice, ErrInvalidTransition
+	}
+
+	switch status {
+	case models.InvoiceIssued:
+		// A linha do ano fica travada até o commit: números em sequência, sem repetição
+		var number int
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO invoice_sequences (year, last_number) VALUES ($1, 1)
+			ON CONFLICT (year) DO UPDATE SET last_number = invoice_sequences.last_number + 1
+			RETURNING last_number`,
+			at.Year(),
+		).Scan(&number)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao numerar fatura: %w", err)
+		}
+		_, err = tx.ExecContext(ctx,
+			`UPDATE invoices SET status = $1, year = $2, number = $3, issued_at = $4 WHERE id = $5`,
+			status, at.Year(), number, at, id)
+	case models.InvoicePaid:
+		_, err = tx.ExecContext(ctx, `UPDATE invoices SET status = $1, paid_at = $2 WHERE id = $3`, status, at, id)
+	case models.InvoiceVoid:
+		_, err = tx.ExecContext(ctx, `UPDATE invoices SET status = $1, voided_at = $2 WHERE id = $3`, status, at, id)
+		if err == nil {
+			_, err = tx.ExecContext(ctx, `
+				UPDATE appointments SET invoice_line_id = NULL
+				WHERE invoice_line_id IN (SELECT id FROM invoice_lines WHERE invoice_id = $1)`, id)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao alterar status da fatura: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetWithDetails(ctx, id)
+}
+
+// lockDraft trava a fatura até o fim da transação e confere se ela ainda é rascunho.
+// Devolve false (sem erro) se a fatura não existir.
+func lockDraft(ctx context.Context, tx executor, id int64) (bool, error) {
+	var status string
+	err := tx.QueryRowContext(ctx, `SELECT status FROM invoices WHERE id = $1 FOR UPDATE`, id).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("erro ao buscar fatura: %w", err)
+	}
+	if status != models.InvoiceDraft {
+		return false, ErrInvoiceNotDraft
+	}
+	return true, nil
+}
+
+// insertLine grava o item calculando o valor (quantidade × preço, em centavos) e preenche ID e Amount.
+func insertLine(ctx context.Context, tx executor, invoiceID int64, line *models.InvoiceLine) error {
+	line.InvoiceID = invoiceID
+	line.Amount = line.Quantity.Mul(line.UnitPrice).Round(2)
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO invoice_lines (invoice_id, kind, description, contract_id, user_id, quantity, unit_price, amount)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id`,
+		invoiceID, line.Kind, line.Description, line.ContractID, line.UserID, line.Quantity, line.UnitPrice, line.Amount,
+	).Scan(&line.ID)
+	if err != nil {
+		return fmt.Errorf("erro ao gravar item da fatura: %w", err)
+	}
+	return nil
+}
+
+// updateTotal recalcula o total da fatura pela soma dos itens.
+func updateTotal(ctx context.Context, tx executor, invoiceID int64) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE invoices SET total = (SELECT COALESCE(SUM(amount), 0) FROM invoice_lines WHERE invoice_id = $1)
+		WHERE id = $1`, invoiceID)
+	if err != nil {
+		return fmt.Errorf("erro ao recalcular total da fatura: %w", err)
+	}
+	return nil
+}
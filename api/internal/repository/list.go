@@ -0,0 +1,457 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"nexus/internal/models"
+)
+
+// ErrInvalidListQuery indica filtro, ordenação ou paginação inválidos (vira 400 no handler).
+var ErrInvalidListQuery = errors.New("parâmetros de listagem inválidos")
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+// ListQuery descreve uma listagem: paginação por página ou por cursor, ordenação e filtros.
+// Os campos usados em Sort e Filters são os nomes JSON dos modelos (ex.: "startTime", "userId").
+type ListQuery struct {
+	Page     int               // Começa em 1 (ignorado quando After é informado)
+	PageSize int               // Padrão DefaultPageSize, máximo MaxPageSize
+	After    string            // Cursor opaco devolvido em Page.NextCursor
+	Sort     []string          // Ex.: ["-startTime", "id"]; "-" = decrescente
+	Filters  map[string]string // Igualdade por campo: {"userId": "5"}
+	From     string            // Início do período (data ou RFC3339), inclusivo
+	To       string            // Fim do período; data pura inclui o dia inteiro
+	// Inclui os registros removidos logicamente (modelos com deleted_at)
+	IncludeDeleted bool
+}
+
+// Page é o resultado de uma listagem.
+type Page[T any] struct {
+	Items      []T
+	Total      int64 // Total de registros que atendem aos filtros (sem paginação)
+	Page       int   // Página atual (0 no modo cursor)
+	PageSize   int
+	NextCursor string // Vazio quando não há próxima página
+}
+
+// HasNext indica se existe uma próxima página.
+func (p *Page[T]) HasNext() bool {
+	return p.NextCursor != ""
+}
+
+// listColumn é um campo do modelo que pode ser filtrado e ordenado.
+type listColumn struct {
+	expr  string       // Expressão SQL (ex.: "a.start_time")
+	typ   reflect.Type // Tipo Go do campo, usado para converter filtros e cursores
+	index []int        // Posição do campo na struct, usado para montar o cursor
+}
+
+// listView diz à listagem genérica de onde ler os dados e como escanear cada linha.
+// A view padrão lê a própria tabela do modelo; repositórios especializados montam views com JOIN.
+type listView[T models.Model] struct {
+	selectSQL   string // "SELECT ..." sem FROM
+	fromSQL     string // "FROM ... JOIN ..."
+	columns     map[string]listColumn
+	periodExpr  string   // Coluna usada por From/To ("" = sem filtro de período)
+	deletedExpr string   // Coluna deleted_at da remoção lógica ("" = modelo sem remoção lógica)
+	defaultSort []string // Ordenação quando a requisição não informa ?sort=
+	scan        func(rows *sql.Rows) (T, error)
+}
+
+// newListView cria uma view com as colunas (tag db) do modelo, prefixadas por alias.
+func newListView[T models.Model](alias, selectSQL, fromSQL string, scan func(rows *sql.Rows) (T, error)) *listView[T] {
+	v := &listView[T]{
+		selectSQL: selectSQL,
+		fromSQL:   fromSQL,
+		columns:   make(map[string]listColumn),
+		scan:      scan,
+	}
+	typ := modelType[T]()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		dbTag := strings.Split(field.Tag.Get("db"), ",")[0]
+		if dbTag == "" || jsonName(field) == "-" {
+			continue
+		}
+		expr := dbTag
+		if alias != "" {
+			expr = alias + "." + dbTag
+		}
+		v.columns[jsonName(field)] = listColumn{expr: expr, typ: field.Type, index: field.Index}
+		if dbTag == deletedAtColumn {
+			v.deletedExpr = expr
+		}
+	}
+	if _, ok := v.columns["id"]; ok {
+		v.defaultSort = []string{"id"}
+	}
+	return v
+}
+
+// withColumn libera filtro/ordenação por um campo calculado (ex.: vindo de um JOIN).
+func (v *listView[T]) withColumn(jsonField, expr string) *listView[T] {
+	typ := modelType[T]()
+	for i := 0; i < typ.NumField(); i++ {
+		if field := typ.Field(i); jsonName(field) == jsonField {
+			v.columns[jsonField] = listColumn{expr: expr, typ: field.Type, index: field.Index}
+			return v
+		}
+	}
+	panic(fmt.Sprintf("campo %q não existe em %s", jsonField, typ.Name()))
+}
+
+// withPeriod define a coluna usada pelos filtros ?from= e ?to=.
+func (v *listView[T]) withPeriod(jsonField string) *listView[T] {
+	v.periodExpr = v.columns[jsonField].expr
+	return v
+}
+
+// withDefaultSort define a ordenação usada quando a requisição não informa ?sort=.
+func (v *listView[T]) withDefaultSort(sort ...string) *listView[T] {
+	v.defaultSort = sort
+	return v
+}
+
+// sortKey é um campo de ordenação já validado.
+type sortKey struct {
+	field string
+	col   listColumn
+	desc  bool
+}
+
+// list executa a listagem: aplica filtros, período, ordenação e paginação (página ou cursor).
+func (v *listView[T]) list(ctx context.Context, db *sql.DB, q ListQuery) (*Page[T], error) {
+	where, args, err := v.filters(q)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := v.sortKeys(q.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page[T]{Items: []T{}, PageSize: q.PageSize, Page: q.Page}
+	if page.PageSize <= 0 {
+		page.PageSize = DefaultPageSize
+	}
+	if page.PageSize > MaxPageSize {
+		return nil, fmt.Errorf("%w: pageSize máximo é %d", ErrInvalidListQuery, MaxPageSize)
+	}
+
+	countSQL := "SELECT COUNT(*) " + v.fromSQL + whereClause(where)
+	if err := conn(ctx, db).QueryRowContext(ctx, countSQL, args...).Scan(&page.Total); err != nil {
+		return nil, fmt.Errorf("erro ao contar registros: %w", err)
+	}
+
+	offset := 0
+	if q.After != "" {
+		page.Page = 0
+		cond, cursorArgs, err := v.cursorCondition(keys, q.After, len(args))
+		if err != nil {
+			return nil, err
+		}
+		where = append(where, cond)
+		args = append(args, cursorArgs...)
+	} else {
+		if page.Page <= 0 {
+			page.Page = 1
+		}
+		offset = (page.Page - 1) * page.PageSize
+	}
+
+	// Busca um registro a mais para saber se existe próxima página
+	dataSQL := fmt.Sprintf("%s %s%s ORDER BY %s LIMIT %d OFFSET %d",
+		v.selectSQL, v.fromSQL, whereClause(where), orderByClause(keys), page.PageSize+1, offset)
+	rows, err := conn(ctx, db).QueryContext(ctx, dataSQL, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar o banco de dados: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := v.scan(rows)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao escanear linha: %w", err)
+		}
+		page.Items = append(page.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro nas linhas: %w", err)
+	}
+
+	if len(page.Items) > page.PageSize {
+		page.Items = page.Items[:page.PageSize]
+		page.NextCursor = encodeCursor(keys, page.Items[len(page.Items)-1])
+	}
+	return page, nil
+}
+
+// each percorre todos os registros que atendem aos filtros, na ordem pedida, sem paginação.
+// As linhas são entregues uma a uma (streaming), sem carregar tudo em memória.
+func (v *listView[T]) each(ctx context.Context, db *sql.DB, q ListQuery, fn func(T) error) error {
+	where, args, err := v.filters(q)
+	if err != nil {
+		return err
+	}
+	keys, err := v.sortKeys(q.Sort)
+	if err != nil {
+		return err
+	}
+
+	dataSQL := fmt.Sprintf("%s %s%s ORDER BY %s", v.selectSQL, v.fromSQL, whereClause(where), orderByClause(keys))
+	rows, err := conn(ctx, db).QueryContext(ctx, dataSQL, args...)
+	if err != nil {
+		return fmt.Errorf("erro ao consultar o banco de dados: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := v.scan(rows)
+		if err != nil {
+			return fmt.Errorf("erro ao escanear linha: %w", err)
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// filters monta as condições de igualdade por campo e de período, escondendo os removidos.
+func (v *listView[T]) filters(q ListQuery) ([]string, []any, error) {
+	var where []string
+	var args []any
+
+	if v.deletedExpr != "" && !q.IncludeDeleted {
+		where = append(where, v.deletedExpr+" IS NULL")
+	}
+
+	fields := make([]string, 0, len(q.Filters))
+	for field := range q.Filters {
+		fields = append(fields, field)
+	}
+	slices.Sort(fields) // Ordem estável dos placeholders
+
+	for _, field := range fields {
+		col, ok := v.columns[field]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: filtro desconhecido %q", ErrInvalidListQuery, field)
+		}
+		value, err := convertValue(col.typ, q.Filters[field], false)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: valor inválido para %q", ErrInvalidListQuery, field)
+		}
+		args = append(args, value)
+		where = append(where, fmt.Sprintf("%s = $%d", col.expr, len(args)))
+	}
+
+	if q.From != "" || q.To != "" {
+		if v.periodExpr == "" {
+			return nil, nil, fmt.Errorf("%w: esta listagem não aceita from/to", ErrInvalidListQuery)
+		}
+		if q.From != "" {
+			from, err := parseTime(q.From)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%w: from inválido", ErrInvalidListQuery)
+			}
+			args = append(args, from)
+			where = append(where, fmt.Sprintf("%s >= $%d", v.periodExpr, len(args)))
+		}
+		if q.To != "" {
+			to, err := parseTime(q.To)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%w: to inválido", ErrInvalidListQuery)
+			}
+			op := "<="
+			if isDateOnly(q.To) {
+				// "to=2026-03-31" inclui o dia 31 inteiro
+				to, op = to.AddDate(0, 0, 1), "<"
+			}
+			args = append(args, to)
+			where = append(where, fmt.Sprintf("%s %s $%d", v.periodExpr, op, len(args)))
+		}
+	}
+	return where, args, nil
+}
+
+// sortKeys valida a ordenação pedida e sempre termina pelo id, para a ordem ser estável.
+func (v *listView[T]) sortKeys(sort []string) ([]sortKey, error) {
+	if len(sort) == 0 {
+		sort = v.defaultSort
+	}
+	var keys []sortKey
+	hasID := false
+	for _, s := range sort {
+		desc := strings.HasPrefix(s, "-")
+		field := strings.TrimPrefix(s, "-")
+		col, ok := v.columns[field]
+		if !ok {
+			return nil, fmt.Errorf("%w: ordenação desconhecida %q", ErrInvalidListQuery, field)
+		}
+		keys = append(keys, sortKey{field: field, col: col, desc: desc})
+		hasID = hasID || field == "id"
+	}
+	if !hasID {
+		keys = append(keys, sortKey{field: "id", col: v.columns["id"]})
+	}
+	return keys, nil
+}
+
+// cursorCondition traduz o cursor em uma condição "depois do último registro" (keyset).
+// Para ordenação (a DESC, id ASC): a < $1 OR (a = $1 AND id > $2).
+func (v *listView[T]) cursorCondition(keys []sortKey, cursor string, argOffset int) (string, []any, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: cursor inválido", ErrInvalidListQuery)
+	}
+	var values []string
+	if err := json.Unmarshal(raw, &values); err != nil || len(values) != len(keys)+1 {
+		return "", nil, fmt.Errorf("%w: cursor inválido", ErrInvalidListQuery)
+	}
+	// O primeiro valor guarda a ordenação usada para gerar o cursor
+	if values[0] != sortSignature(keys) {
+		return "", nil, fmt.Errorf("%w: cursor gerado com outra ordenação", ErrInvalidListQuery)
+	}
+
+	args := make([]any, len(keys))
+	for i, k := range keys {
+		if k.col.typ.Kind() == reflect.Pointer {
+			return "", nil, fmt.Errorf("%w: ordenação por %q (campo opcional) não suporta cursor; use ?page=", ErrInvalidListQuery, k.field)
+		}
+		value, err := convertValue(k.col.typ, values[i+1], true)
+		if err != nil {
+			return "", nil, fmt.Errorf("%w: cursor inválido", ErrInvalidListQuery)
+		}
+		args[i] = value
+	}
+
+	var ors []string
+	for i, k := range keys {
+		var ands []string
+		for j := 0; j < i; j++ {
+			ands = append(ands, fmt.Sprintf("%s = $%d", keys[j].col.expr, argOffset+j+1))
+		}
+		op := ">"
+		if k.desc {
+			op = "<"
+		}
+		ands = append(ands, fmt.Sprintf("%s %s $%d", k.col.expr, op, argOffset+i+1))
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+	return "(" + strings.Join(ors, " OR ") + ")", args, nil
+}
+
+// encodeCursor guarda a ordenação e os valores de ordenação do último item da página.
+func encodeCursor[T models.Model](keys []sortKey, last T) string {
+	val := reflect.ValueOf(last).Elem()
+	values := []string{sortSignature(keys)}
+	for _, k := range keys {
+		field := val.FieldByIndex(k.col.index)
+		if field.Kind() == reflect.Pointer {
+			if field.IsNil() {
+				return "" // Sem cursor para campo nulo; o cliente pode seguir por ?page=
+			}
+			field = field.Elem()
+		}
+		switch f := field.Interface().(type) {
+		case time.Time:
+			values = append(values, f.Format(time.RFC3339Nano))
+		default:
+			values = append(values, fmt.Sprint(f))
+		}
+	}
+	raw, _ := json.Marshal(values)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func sortSignature(keys []sortKey) string {
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k.field
+		if k.desc {
+			parts[i] = "-" + k.field
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// convertValue converte o texto da query string para o tipo Go do campo.
+func convertValue(typ reflect.Type, raw string, exactTime bool) (any, error) {
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ == reflect.TypeOf(time.Time{}) {
+		if exactTime {
+			return time.Parse(time.RFC3339Nano, raw)
+		}
+		return parseTime(raw)
+	}
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.ParseInt(raw, 10, 64)
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(raw, 64)
+	case reflect.Bool:
+		return strconv.ParseBool(raw)
+	case reflect.String:
+		return raw, nil
+	}
+	return nil, fmt.Errorf("tipo %s não suportado em filtros", typ)
+}
+
+// parseTime aceita data pura (2026-01-31) ou RFC3339.
+func parseTime(raw string) (time.Time, error) {
+	if isDateOnly(raw) {
+		return time.Parse(time.DateOnly, raw)
+	}
+	return time.Parse(time.RFC3339, raw)
+}
+
+func isDateOnly(raw string) bool {
+	return len(raw) == len(time.DateOnly)
+}
+
+func orderByClause(keys []sortKey) string {
+	orderBy := make([]string, len(keys))
+	for i, k := range keys {
+		orderBy[i] = k.col.expr
+		if k.desc {
+			orderBy[i] += " DESC"
+		}
+	}
+	return strings.Join(orderBy, ", ")
+}
+
+func whereClause(where []string) string {
+	if len(where) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(where, " AND ")
+}
+
+func modelType[T models.Model]() reflect.Type {
+	var t T
+	return reflect.TypeOf(t).Elem()
+}
+
+func jsonName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"nexus/internal/models"
+)
+
+// RefreshTokenRepository guarda os refresh tokens emitidos para permitir rotação e revogação.
+type RefreshTokenRepository interface {
+	Save(ctx context.Context, token *models.RefreshToken) error
+	GetByID(ctx context.Context, id string) (*models.RefreshToken, error)
+	Revoke(ctx context.Context, id string) (int64, error)
+	RevokeAllForUser(ctx context.Context, userID int64) (int64, error)
+}
+
+// postgresRefreshTokenRepository é a implementação da interface para o PostgreSQL.
+type postgresRefreshTokenRepository struct {
+	db *sql.DB
+}
+
+// NewRefreshTokenRepository cria uma nova instância do repositório de refresh tokens.
+func NewRefreshTokenRepository(db *sql.DB) RefreshTokenRepository {
+	return &postgresRefreshTokenRepository{db: db}
+}
+
+// Save registra um refresh token recém-emitido.
+func (r *postgresRefreshTokenRepository) Save(ctx context.Context, token *models.RefreshToken) error {
+	query := `INSERT INTO refresh_tokens (id, user_id, expires_at) VALUES ($1, $2, $3)`
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, token.ID, token.UserID, token.ExpiresAt); err != nil {
+		return fmt.Errorf("erro ao salvar refresh token: %w", err)
+	}
+	return nil
+}
+
+// GetByID busca um refresh token pelo jti. Retorna nil, nil se não existir.
+func (r *postgresRefreshTokenRepository) GetByID(ctx context.Context, id string) (*models.RefreshToken, error) {
+	query := `SELECT id, user_id, expires_at, revoked_at, created_at FROM refresh_tokens WHERE id = $1`
+
+	var t models.RefreshToken
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
+		&t.ID, &t.UserID, &t.ExpiresAt, &t.RevokedAt, &t.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar refresh token: %w", err)
+	}
+	return &t, nil
+}
+
+// Revoke invalida um refresh token. Tokens já revogados não são afetados.
+func (r *postgresRefreshTokenRepository) Revoke(ctx context.Context, id string) (int64, error) {
+	query := `UPDATE refresh_tokens SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL`
+	res, err := conn(ctx, r.db).ExecContext(ctx, query, time.Now(), id)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao revogar refresh token: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// RevokeAllForUser invalida todos os refresh tokens ativos de um usuário.
+func (r *postgresRefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID int64) (int64, error) {
+	query := `UPDATE refresh_tokens SET revoked_at = $1 WHERE user_id = $2 AND revoked_at IS NULL`
+	res, err := conn(ctx, r.db).ExecContext(ctx, query, time.Now(), userID)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao revogar refresh tokens: %w", err)
+	}
+	return res.RowsAffected()
+}
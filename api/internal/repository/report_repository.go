@@ -0,0 +1,272 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"nexus/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// Dimensões e períodos aceitos pelo relatório de horas
+var (
+	ReportDimensions = []string{"company", "contract", "user", "ticket"}
+	ReportPeriods    = []string{"day", "week", "month", "quarter", "year"}
+)
+
+// HoursReportQuery são os parâmetros do relatório de horas.
+type HoursReportQuery struct {
+	GroupBy    []string   // company, contract, user, ticket
+	Period     string     // day, week, month, quarter, year ("" = sem quebra por período)
+	From       *time.Time // Inclusivo, sobre start_time
+	To         *time.Time // Exclusivo, sobre start_time
+	CompanyID  int64
+	ContractID int64
+	UserID     int64
+	TicketID   int64
+	// Status da semana na folha de horas (draft, submitted, approved, rejected; "" = todos)
+	ApprovalStatus string
+}
+
+// ReportRepository agrega horas direto no banco.
+type ReportRepository interface {
+	HoursReport(ctx context.Context, q HoursReportQuery) ([]*models.HoursReportRow, error)
+	RevenueReport(ctx context.Context, q HoursReportQuery) ([]*models.RevenueReportRow, error)
+	SLAReport(ctx context.Context, contractID int64, from, to *time.Time, now time.Time) ([]*models.SLAReportRow, error)
+}
+
+type postgresReportRepository struct {
+	db *sql.DB
+}
+
+// NewReportRepository cria uma nova instância do repositório de relatórios.
+func NewReportRepository(db *sql.DB) ReportRepository {
+	return &postgresReportRepository{db: db}
+}
+
+// HoursReport soma a duração dos apontamentos por grupo e por período (date_trunc sobre start_time).
+// Apontamentos em andamento contam até agora. GroupBy e Period devem vir validados.
+func (r *postgresReportRepository) HoursReport(ctx context.Context, q HoursReportQuery) ([]*models.HoursReportRow, error) {
+	query, args, err := groupedAppointmentsSQL(q, []string{
+		"COUNT(*)",
+		"COALESCE(SUM(EXTRACT(EPOCH FROM (COALESCE(a.end_time, CURRENT_TIMESTAMP) - a.start_time))), 0)::bigint",
+	}, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao gerar relatório de horas: %w", err)
+	}
+	defer rows.Close()
+
+	var report []*models.HoursReportRow
+	for rows.Next() {
+		var row models.HoursReportRow
+		dest := append(dimensionDest(q, &row), &row.AppointmentCount, &row.DurationSeconds)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		row.TotalHours = float64(row.DurationSeconds) / 3600
+		report = append(report, &row)
+	}
+	return report, rows.Err()
+}
+
+// RevenueReport soma, por grupo e período, as horas dos apontamentos finalizados e o valor delas.
+// Cada apontamento usa o valor-hora vigente no dia do início: o do consultor no contrato, se houver,
+// senão o padrão do contrato. A conta é feita em NUMERIC e só arredonda em centavos no fim de cada linha.
+func (r *postgresReportRepository) RevenueReport(ctx context.Context, q HoursReportQuery) ([]*models.RevenueReportRow, error) {
+	const seconds = "EXTRACT(EPOCH FROM (a.end_time - a.start_time))"
+	query, args, err := groupedAppointmentsSQL(q, []string{
+		"COUNT(*)",
+		"COALESCE(SUM(" + seconds + "), 0)::bigint",
+		"COALESCE(SUM(" + seconds + ") FILTER (WHERE rate.hourly_rate IS NULL), 0)::bigint",
+		"COALESCE(SUM(rate.hourly_rate * " + seconds + "), 0)",
+	}, `
+		LEFT JOIN LATERAL (
+		    SELECT br.hourly_rate
+		    FROM billing_rates br
+		    WHERE br.contract_id = a.contract_id
+		      AND br.deleted_at IS NULL
+		      AND (br.user_id = a.user_id OR br.user_id IS NULL)
+		      AND br.valid_from <= a.start_time::date
+		      AND (br.valid_to IS NULL OR br.valid_to >= a.start_time::date)
+		    ORDER BY br.user_id NULLS LAST
+		    LIMIT 1
+		) rate ON true`, []string{"a.end_time IS NOT NULL"})
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao gerar relatório de faturamento: %w", err)
+	}
+	defer rows.Close()
+
+	secondsPerHour := decimal.NewFromInt(3600)
+	var report []*models.RevenueReportRow
+	for rows.Next() {
+		var row models.RevenueReportRow
+		var rateSeconds decimal.Decimal // Soma de valor-hora × segundos
+		dest := append(dimensionDest(q, &row.HoursReportRow),
+			&row.AppointmentCount, &row.DurationSeconds, &row.UnratedSeconds, &rateSeconds)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		row.TotalHours = float64(row.DurationSeconds) / 3600
+		row.UnratedHours = float64(row.UnratedSeconds) / 3600
+		row.Amount = rateSeconds.DivRound(secondsPerHour, 2)
+		report = append(report, &row)
+	}
+	return report, rows.Err()
+}
+
+// groupedAppointmentsSQL monta a consulta agregada dos relatórios de apontamentos: as colunas de
+// período e dimensões pedidas, as medidas (agregações) e os filtros de q. join e where são extras
+// de cada relatório. A ordem das colunas é a mesma de dimensionDest.
+func groupedAppointmentsSQL(q HoursReportQuery, measures []string, join string, where []string) (string, []any, error) {
+	var selects, groups, orders []string
+	where = append([]string{"a.deleted_at IS NULL"}, where...)
+	if q.Period != "" {
+		// Period vem da lista ReportPeriods, então pode ir direto no SQL
+		selects = append(selects, fmt.Sprintf("date_trunc('%s', a.start_time) AS period", q.Period))
+		groups = append(groups, "period")
+		orders = append(orders, "period")
+	}
+	for _, dim := range q.GroupBy {
+		switch dim {
+		case "company":
+			selects = append(selects, "co.id", "co.name")
+			groups = append(groups, "co.id", "co.name")
+			orders = append(orders, "co.name")
+		case "contract":
+			selects = append(selects, "c.id", "c.title")
+			groups = append(groups, "c.id", "c.title")
+			orders = append(orders, "c.title")
+		case "user":
+			selects = append(selects, "u.id", "u.name")
+			groups = append(groups, "u.id", "u.name")
+			orders = append(orders, "u.name")
+		case "ticket":
+			// Horas sem chamado saem num grupo próprio (ticket nulo)
+			selects = append(selects, "t.id", "t.title")
+			groups = append(groups, "t.id", "t.title")
+			orders = append(orders, "t.id NULLS LAST")
+		default:
+			return "", nil, fmt.Errorf("dimensão de agrupamento inválida: %s", dim)
+		}
+	}
+	selects = append(selects, measures...)
+
+	var args []any
+	addFilter := func(cond string, value any) {
+		args = append(args, value)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+	if q.From != nil {
+		addFilter("a.start_time >= $%d", *q.From)
+	}
+	if q.To != nil {
+		addFilter("a.start_time < $%d", *q.To)
+	}
+	if q.CompanyID != 0 {
+		addFilter("c.company_id = $%d", q.CompanyID)
+	}
+	if q.ContractID != 0 {
+		addFilter("a.contract_id = $%d", q.ContractID)
+	}
+	if q.UserID != 0 {
+		addFilter("a.user_id = $%d", q.UserID)
+	}
+	if q.TicketID != 0 {
+		addFilter("a.ticket_id = $%d", q.TicketID)
+	}
+	if q.ApprovalStatus != "" {
+		addFilter("COALESCE(ts.status, 'draft') = $%d", q.ApprovalStatus)
+	}
+
+	query := "SELECT " + strings.Join(selects, ", ") + `
+		FROM appointments a
+		JOIN contracts c ON a.contract_id = c.id
+		JOIN companies co ON c.company_id = co.id
+		JOIN users u ON a.user_id = u.id
+		LEFT JOIN tickets t ON a.ticket_id = t.id
+		LEFT JOIN timesheets ts ON ts.user_id = a.user_id AND ts.week_start = date_trunc('week', a.start_time)::date` +
+		join + whereClause(where)
+	if len(groups) > 0 {
+		query += " GROUP BY " + strings.Join(groups, ", ") + " ORDER BY " + strings.Join(orders, ", ")
+	}
+	return query, args, nil
+}
+
+// dimensionDest devolve os destinos do Scan para o período e as dimensões pedidas em q.
+func dimensionDest(q HoursReportQuery, row *models.HoursReportRow) []any {
+	var dest []any
+	if q.Period != "" {
+		dest = append(dest, &row.Period)
+	}
+	for _, dim := range q.GroupBy {
+		switch dim {
+		case "company":
+			dest = append(dest, &row.CompanyID, &row.CompanyName)
+		case "contract":
+			dest = append(dest, &row.ContractID, &row.ContractTitle)
+		case "user":
+			dest = append(dest, &row.UserID, &row.UserName)
+		case "ticket":
+			dest = append(dest, &row.TicketID, &row.TicketTitle)
+		}
+	}
+	return dest
+}
+
+// SLAReport conta, por mês de abertura, os chamados do contrato medidos e cumpridos em cada meta.
+// Um chamado é medido quando já foi atendido/resolvido ou quando o prazo venceu (em relação a now).
+// to é exclusivo.
+func (r *postgresReportRepository) SLAReport(ctx context.Context, contractID int64, from, to *time.Time, now time.Time) ([]*models.SLAReportRow, error) {
+	where := []string{"contract_id = $1", "deleted_at IS NULL"}
+	args := []any{contractID, now}
+	if from != nil {
+		args = append(args, *from)
+		where = append(where, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+	if to != nil {
+		args = append(args, *to)
+		where = append(where, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+
+	query := `
+		SELECT date_trunc('month', created_at) AS month,
+		       COUNT(*),
+		       COUNT(*) FILTER (WHERE response_due_at IS NOT NULL AND (first_response_at IS NOT NULL OR response_due_at <= $2)),
+		       COUNT(*) FILTER (WHERE first_response_at <= response_due_at),
+		       COUNT(*) FILTER (WHERE resolution_due_at IS NOT NULL AND (resolved_at IS NOT NULL OR resolution_due_at <= $2)),
+		       COUNT(*) FILTER (WHERE resolved_at <= resolution_due_at)
+		FROM tickets` + whereClause(where) + `
+		GROUP BY month
+		ORDER BY month`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao gerar relatório de SLA: %w", err)
+	}
+	defer rows.Close()
+
+	var report []*models.SLAReportRow
+	for rows.Next() {
+		var row models.SLAReportRow
+		if err := rows.Scan(&row.Month, &row.Tickets, &row.ResponseMeasured, &row.ResponseMet,
+			&row.ResolutionMeasured, &row.ResolutionMet); err != nil {
+			return nil, err
+		}
+		report = append(report, &row)
+	}
+	return report, rows.Err()
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"nexus/internal/models"
+)
+
+// ErrInvalidTransition indica que o chamado não pode ir do status atual para o pedido.
+var ErrInvalidTransition = errors.New("transição de status inválida")
+
+// TicketRepository define as operações com chamados.
+type TicketRepository interface {
+	Repository[*models.Ticket]
+	GetAllWithDetails(ctx context.Context, q ListQuery) (*Page[*models.Ticket], error)
+	GetWithDetails(ctx context.Context, id int64) (*models.Ticket, error)
+	Transition(ctx context.Context, id int64, status string, assigneeID *int64, at time.Time) (*models.Ticket, error)
+	RefreshSLAStatus(ctx context.Context, now time.Time) ([]*models.Ticket, error)
+}
+
+type postgresTicketRepository struct {
+	Repository[*models.Ticket]
+	db          *sql.DB
+	detailsView *listView[*models.Ticket]
+}
+
+// NewTicketRepository cria uma nova instância do repositório de chamados.
+func NewTicketRepository(db *sql.DB) TicketRepository {
+	detailsView := newListView("t", `SELECT t.id, t.company_id, t.contract_id, t.requester_name, t.requester_email,
+	                 t.assignee_id, t.title, t.description, t.status, t.priority,
+	                 t.created_at, t.updated_at, t.resolved_at, t.closed_at,
+	                 t.first_response_at, t.response_due_at, t.resolution_due_at,
+	                 t.response_risk_at, t.resolution_risk_at, t.sla_status, t.deleted_at,
+	                 co.name, COALESCE(c.title, ''), COALESCE(u.name, ''),
+	                 COALESCE((SELECT SUM(EXTRACT(EPOCH FROM (COALESCE(a.end_time, CURRENT_TIMESTAMP) - a.start_time)))
+	                           FROM appointments a WHERE a.ticket_id = t.id AND a.deleted_at IS NULL), 0) / 3600`,
+		`FROM tickets t
+	     JOIN companies co ON t.company_id = co.id
+	     LEFT JOIN contracts c ON t.contract_id = c.id
+	     LEFT JOIN users u ON t.assignee_id = u.id`,
+		scanTicket,
+	).
+		withColumn("companyName", "co.name").
+		withColumn("assigneeName", "u.name").
+		withPeriod("createdAt").
+		withDefaultSort("-createdAt")
+
+	return &postgresTicketRepository{
+		Repository:  NewPostgresRepository[*models.Ticket](db, "tickets"),
+		db:          db,
+		detailsView: detailsView,
+	}
+}
+
+func scanTicket(rows *sql.Rows) (*models.Ticket, error) {
+	var t models.Ticket
+	err := rows.Scan(
+		&t.ID, &t.CompanyID, &t.ContractID, &t.RequesterName, &t.RequesterEmail,
+		&t.AssigneeID, &t.Title, &t.Description, &t.Status, &t.Priority,
+		&t.CreatedAt, &t.UpdatedAt, &t.ResolvedAt, &t.ClosedAt,
+		&t.FirstResponseAt, &t.ResponseDueAt, &t.ResolutionDueAt,
+		&t.ResponseRiskAt, &t.ResolutionRiskAt, &t.SLAStatus, &t.DeletedAt,
+		&t.CompanyName, &t.ContractTitle, &t.AssigneeName, &t.TotalHours,
+	)
+	return &t, err
+}
+
+// GetAllWithDetails lista chamados com empresa, contrato, responsável e horas gastas.
+// Filtros comuns: companyId, contractId, assigneeId, status, priority e o período sobre createdAt.
+func (r *postgresTicketRepository) GetAllWithDetails(ctx context.Context, q ListQuery) (*Page[*models.Ticket], error) {
+	return r.detailsView.list(ctx, r.db, q)
+}
+
+// GetWithDetails busca um chamado com os campos calculados. Devolve nil, nil se não existir.
+func (r *postgresTicketRepository) GetWithDetails(ctx context.Context, id int64) (*models.Ticket, error) {
+	page, err := r.detailsView.list(ctx, r.db, ListQuery{
+		PageSize: 1,
+		Filters:  map[string]string{"id": strconv.FormatInt(id, 10)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(page.Items) == 0 {
+		return nil, nil
+	}
+	return page.Items[0], nil
+}
+
+// Transition muda o status do chamado numa única instrução, só se o status atual permitir
+// (ver models.TicketTransitionsTo). Também carimba resolved_at/closed_at e a primeira resposta
+// (a primeira saída de "open"), marca o SLA como violado se a resposta ou a resolução passou
+// do prazo e, se assigneeID vier preenchido, atribui o responsável.
+// Devolve ErrInvalidTransition se o status atual não permitir e nil, nil se o chamado não existir.
+func (r *postgresTicketRepository) Transition(ctx context.Context, id int64, status string, assigneeID *int64, at time.Time) (*models.Ticket, error) {
+	from := models.TicketTransitionsTo(status)
+	res, err := conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE tickets SET
+		    status = $1,
+		    updated_at = $2,
+		    assignee_id = COALESCE($3, assignee_id),
+		    resolved_at = CASE WHEN $1 IN ('resolved', 'closed') THEN COALESCE(resolved_at, $2) ELSE NULL END,
+		    closed_at = CASE WHEN $1 = 'closed' THEN $2 ELSE NULL END,
+		    first_response_at = CASE WHEN $1 <> 'open' THEN COALESCE(first_response_at, $2) ELSE first_response_at END,
+		    sla_status = CASE
+		        WHEN sla_status = '' THEN ''
+		        WHEN first_response_at IS NULL AND $1 <> 'open' AND $2 > response_due_at THEN 'breached'
+		        WHEN $1 IN ('resolved', 'closed') AND COALESCE(resolved_at, $2) > resolution_due_at THEN 'breached'
+		        ELSE sla_status END
+		WHERE id = $4 AND status = ANY($5) AND deleted_at IS NULL`,
+		status, at, assigneeID, id, from,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao alterar status do chamado: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	ticket, err := r.GetWithDetails(ctx, id)
+	if err != nil || ticket == nil {
+		return ticket, err
+	}
+	if affected == 0 {
+		return ticket, ErrInvalidTransition
+	}
+	return ticket, nil
+}
+
+// RefreshSLAStatus recalcula a situação de SLA dos chamados em aberto em relação a now:
+// prazo vencido sem resposta/resolução vira "breached"; passado o ponto de risco, "at_risk".
+// Violado não volta atrás. Devolve só os chamados que mudaram (id, título e nova situação).
+func (r *postgresTicketRepository) RefreshSLAStatus(ctx context.Context, now time.Time) ([]*models.Ticket, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		WITH computed AS (
+		    SELECT id, CASE
+		        WHEN (first_response_at IS NULL AND response_due_at <= $1)
+		          OR (resolved_at IS NULL AND resolution_due_at <= $1) THEN 'breached'
+		        WHEN (first_response_at IS NULL AND response_risk_at <= $1)
+		          OR (resolved_at IS NULL AND resolution_risk_at <= $1) THEN 'at_risk'
+		        ELSE 'on_track' END AS sla_status
+		    FROM tickets
+		    WHERE sla_status IN ('on_track', 'at_risk') AND status NOT IN ('resolved', 'closed') AND deleted_at IS NULL
+		)
+		UPDATE tickets t SET sla_status = c.sla_status
+		FROM computed c
+		WHERE t.id = c.id AND t.sla_status <> c.sla_status
+		RETURNING t.id, t.title, t.sla_status`,
+		now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao atualizar SLA dos chamados: %w", err)
+	}
+	defer rows.Close()
+
+	var changed []*models.Ticket
+	for rows.Next() {
+		var t models.Ticket
+		if err := rows.Scan(&t.ID, &t.Title, &t.SLAStatus); err != nil {
+			return nil, err
+		}
+		changed = append(changed, &t)
+	}
+	return changed, rows.Err()
+}
@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"nexus/internal/models"
+)
+
+var (
+	// ErrTimesheetLocked indica que a semana já foi enviada ou aprovada (só recusada pode ser reenviada).
+	ErrTimesheetLocked = errors.New("semana já enviada ou aprovada")
+	// ErrTimesheetRunning indica um apontamento em andamento na semana que se quer enviar.
+	ErrTimesheetRunning = errors.New("há apontamento em andamento na semana")
+)
+
+// TimesheetRepository define as operações com folhas de horas semanais.
+type TimesheetRepository interface {
+	Repository[*models.Timesheet]
+	GetAllWithDetails(ctx context.Context, q ListQuery) (*Page[*models.Timesheet], error)
+	GetWithDetails(ctx context.Context, id int64) (*models.Timesheet, error)
+	WeekStatus(ctx context.Context, userID int64, weekStart time.Time) (string, error)
+	Submit(ctx context.Context, userID int64, weekStart time.Time, at time.Time) (*models.Timesheet, error)
+	Review(ctx context.Context, id int64, status string, reviewerID int64, comment string, at time.Time) (*models.Timesheet, error)
+}
+
+type postgresTimesheetRepository struct {
+	Repository[*models.Timesheet]
+	db          *sql.DB
+	detailsView *listView[*models.Timesheet]
+}
+
+// NewTimesheetRepository cria uma nova instância do repositório de folhas de horas.
+func NewTimesheetRepository(db *sql.DB) TimesheetRepository {
+	detailsView := newListView("ts", `SELECT ts.id, ts.user_id, ts.week_start, ts.status, ts.comment,
+	                 ts.submitted_at, ts.reviewed_at, ts.reviewed_by,
+	                 u.name, COALESCE(rv.name, ''), wk.appointments, wk.hours`,
+		`FROM timesheets ts
+	     JOIN users u ON ts.user_id = u.id
+	     LEFT JOIN users rv ON ts.reviewed_by = rv.id
+	     CROSS JOIN LATERAL (
+	         SELECT COUNT(*) AS appointments,
+	                COALESCE(SUM(EXTRACT(EPOCH FROM (COALESCE(a.end_time, CURRENT_TIMESTAMP) - a.start_time))), 0) / 3600 AS hours
+	         FROM appointments a
+	         WHERE a.user_id = ts.user_id AND a.start_time >= ts.week_start AND a.start_time < ts.week_start + 7
+	           AND a.deleted_at IS NULL
+	     ) wk`,
+		scanTimesheet,
+	).
+		withColumn("userName", "u.name").
+		withPeriod("weekStart").
+		withDefaultSort("-weekStart", "userName")
+
+	return &postgresTimesheetRepository{
+		Repository:  NewPostgresRepository[*models.Timesheet](db, "timesheets"),
+		db:          db,
+		detailsView: detailsView,
+	}
+}
+
+func scanTimesheet(rows *sql.Rows) (*models.Timesheet, error) {
+	var t models.Timesheet
+	err := rows.Scan(
+		&t.ID, &t.UserID, &t.WeekStart, &t.Status, &t.Comment,
+		&t.SubmittedAt, &t.ReviewedAt, &t.ReviewedBy,
+		&t.UserName, &t.ReviewerName, &t.AppointmentCount, &t.TotalHours,
+	)
+	return &t, err
+}
+
+// GetAllWithDetails lista as semanas com consultor, revisor e horas lançadas.
+// Filtros comuns: userId, status e o período (from/to) sobre weekStart.
+func (r *postgresTimesheetRepository) GetAllWithDetails(ctx context.Context, q ListQuery) (*Page[*models.Timesheet], error) {
+	return r.detailsView.list(ctx, r.db, q)
+}
+
+// GetWithDetails busca uma semana com os campos calculados. Devolve nil, nil se não existir.
+func (r *postgresTimesheetRepository) GetWithDetails(ctx context.Context, id int64) (*models.Timesheet, error) {
+	page, err := r.detailsView.list(ctx, r.db, ListQuery{
+		PageSize: 1,
+		Filters:  map[string]string{"id": strconv.FormatInt(id, 10)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(page.Items) == 0 {
+		return nil, nil
+	}
+	return page.Items[0], nil
+}
+
+// WeekStatus devolve o status da semana do usuário (models.TimesheetDraft se ainda não foi enviada).
+func (r *postgresTimesheetRepository) WeekStatus(ctx context.Context, userID int64, weekStart time.Time) (string, error) {
+	var status string
+	err := conn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT status FROM timesheets WHERE user_id = $1 AND week_start = $2`,
+		userID, weekStart.Format(time.DateOnly),
+	).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.TimesheetDraft, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("erro ao buscar folha de horas: %w", err)
+	}
+	return status, nil
+}
+
+// Submit envia a semana para aprovação, criando a folha ou reenviando uma recusada.
+// Trava o usuário durante a operação, como o cronômetro, para não correr com um "start".
+// Devolve ErrTimesheetRunning se houver apontamento em andamento na semana,
+// ErrTimesheetLocked se ela já estiver enviada/aprovada e nil, nil se o usuário não existir.
+func (r *postgresTimesheetRepository) Submit(ctx context.Context, userID int64, weekStart time.Time, at time.Time) (*models.Timesheet, error) {
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var lockedID int64
+	err = tx.QueryRowContext(ctx, `SELECT id FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&lockedID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao travar usuário: %w", err)
+	}
+
+	week := weekStart.Format(time.DateOnly)
+	var running bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM appointments
+		               WHERE user_id = $1 AND end_time IS NULL AND deleted_at IS NULL
+		                 AND start_time >= $2::date AND start_time < $2::date + 7)`,
+		userID, week,
+	).Scan(&running)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao verificar apontamentos da semana: %w", err)
+	}
+	if running {
+		return nil, ErrTimesheetRunning
+	}
+
+	var id int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO timesheets (user_id, week_start, status, submitted_at)
+		VALUES ($1, $2, 'submitted', $3)
+		ON CONFLICT (user_id, week_start) DO UPDATE
+		SET status = 'submitted', submitted_at = EXCLUDED.submitted_at, comment = '', reviewed_at = NULL, reviewed_by = NULL
+		WHERE timesheets.status = 'rejected'
+		RETURNING id`,
+		userID, week, at,
+	).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTimesheetLocked
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao enviar folha de horas: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetWithDetails(ctx, id)
+}
+
+// Review aprova ou recusa uma semana enviada, numa única instrução.
+// Devolve ErrInvalidTransition se a semana não estiver "submitted" e nil, nil se não existir.
+func (r *postgresTimesheetRepository) Review(ctx context.Context, id int64, status string, reviewerID int64, comment string, at time.Time) (*models.Timesheet, error) {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE timesheets SET status = $1, reviewed_by = $2, comment = $3, reviewed_at = $4
+		WHERE id = $5 AND status = 'submitted'`,
+		status, reviewerID, comment, at, id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao revisar folha de horas: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	timesheet, err := r.GetWithDetails(ctx, id)
+	if err != nil || timesheet == nil {
+		return timesheet, err
+	}
+	if affected == 0 {
+		return timesheet, ErrInvalidTransition
+	}
+	return timesheet, nil
+}
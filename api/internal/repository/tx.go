@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+)
+
+// TxManager roda uma função dentro de uma transação (unit of work). A transação vai no
+// contexto: todo repositório chamado com esse ctx participa dela sem saber, inclusive os
+// que abrem transação própria (StartTimer, SaveBatch, faturas...), que viram savepoints.
+type TxManager interface {
+	// WithinTx faz commit se fn devolver nil e rollback se devolver erro (ou entrar em pânico).
+	// Chamado com um ctx que já tem transação, participa dela.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type txKey struct{}
+
+// executor é o que as consultas precisam; *sql.DB e *sql.Tx implementam.
+type executor interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// conn devolve a transação do contexto, se houver, ou o pool.
+func conn(ctx context.Context, db *sql.DB) executor {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+type sqlTxManager struct {
+	db *sql.DB
+}
+
+// NewTxManager cria o gerenciador de transações sobre o pool.
+func NewTxManager(db *sql.DB) TxManager {
+	return &sqlTxManager{db: db}
+}
+
+func (m *sqlTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	tx, err := begin(ctx, m.db)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = fn(context.WithValue(ctx, txKey{}, tx.Tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// savepointSeq dá nomes únicos aos savepoints das transações aninhadas.
+var savepointSeq atomic.Int64
+
+// scopedTx é uma transação própria ou, dentro de outra, um savepoint dela:
+// Commit/Rollback do savepoint só confirmam ou desfazem a parte aninhada.
+type scopedTx struct {
+	*sql.Tx
+	ctx       context.Context
+	savepoint string // "" = transação própria
+	done      bool
+}
+
+// begin abre uma transação ou, se o contexto já tiver uma, um savepoint nela.
+// O padrão de uso é o mesmo de *sql.Tx: defer tx.Rollback() e tx.Commit() no fim.
+func begin(ctx context.Context, db *sql.DB) (*scopedTx, error) {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		name := fmt.Sprintf("sp_%d", savepointSeq.Add(1))
+		if _, err := tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+			return nil, err
+		}
+		return &scopedTx{Tx: tx, ctx: ctx, savepoint: name}, nil
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &scopedTx{Tx: tx, ctx: ctx}, nil
+}
+
+func (t *scopedTx) Commit() error {
+	if t.savepoint == "" {
+		return t.Tx.Commit()
+	}
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+	_, err := t.Tx.ExecContext(t.ctx, "RELEASE SAVEPOINT "+t.savepoint)
+	return err
+}
+
+func (t *scopedTx) Rollback() error {
+	if t.savepoint == "" {
+		return t.Tx.Rollback()
+	}
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+	_, err := t.Tx.ExecContext(t.ctx, "ROLLBACK TO SAVEPOINT "+t.savepoint)
+	return err
+}
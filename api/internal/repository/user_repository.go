@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"nexus/internal/models"
 )
@@ -10,7 +11,8 @@ import (
 // UserRepository define a interface para as operações com usuários.
 type UserRepository interface {
 	Repository[*models.User]
-	EmailExists(email string) (bool, error)
+	EmailExists(ctx context.Context, email string) (bool, error)
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
 }
 
 // postgresUserRepository é a implementação da interface para o PostgreSQL.
@@ -27,13 +29,31 @@ func NewUserRepository(db *sql.DB) UserRepository {
 	}
 }
 
-// EmailExists verifica se um e-mail já está cadastrado.
-func (r *postgresUserRepository) EmailExists(email string) (bool, error) {
+// EmailExists verifica se um e-mail já está em uso por um usuário ativo (removidos liberam o e-mail).
+func (r *postgresUserRepository) EmailExists(ctx context.Context, email string) (bool, error) {
 	var exists bool
-	query := fmt.Sprintf("SELECT EXISTS(SELECT 1 FROM %s WHERE email = $1)", r.GetTableName())
-	err := r.db.QueryRowContext(context.Background(), query, email).Scan(&exists)
+	query := fmt.Sprintf("SELECT EXISTS(SELECT 1 FROM %s WHERE email = $1 AND deleted_at IS NULL)", r.GetTableName())
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, email).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("erro ao checar e-mail: %w", err)
 	}
 	return exists, nil
 }
+
+// GetByEmail busca um usuário pelo e-mail (incluindo o hash da senha, usado no login).
+// Retorna nil, nil se o e-mail não estiver cadastrado.
+func (r *postgresUserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	query := `SELECT id, name, email, role, password_hash FROM users WHERE email = $1 AND deleted_at IS NULL`
+
+	var u models.User
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, email).Scan(
+		&u.ID, &u.Name, &u.Email, &u.Role, &u.PasswordHash,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar usuário por e-mail: %w", err)
+	}
+	return &u, nil
+}
@@ -0,0 +1,119 @@
+// Package sla calcula prazos de atendimento em horário comercial e acompanha os chamados.
+package sla
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"nexus/internal/models"
+)
+
+// maxSearchDays limita a busca por expediente (calendário sem nenhuma janela útil).
+const maxSearchDays = 3660
+
+// window é um expediente já convertido para um dia específico.
+type window struct {
+	start, end time.Time
+}
+
+// ValidateCalendar confere fuso, grade semanal e feriados. O erro já é a mensagem para o cliente.
+// Nome e tamanhos ficam nas tags `validate` do modelo.
+func ValidateCalendar(cal *models.BusinessCalendar) error {
+	if cal.Timezone == "" {
+		cal.Timezone = "America/Sao_Paulo"
+	}
+	if _, err := time.LoadLocation(cal.Timezone); err != nil {
+		return fmt.Errorf("Fuso horário inválido: %s", cal.Timezone)
+	}
+	if len(cal.Hours) == 0 {
+		return errors.New("Informe ao menos um expediente em hours")
+	}
+	for _, w := range cal.Hours {
+		if w.Weekday < 0 || w.Weekday > 6 {
+			return errors.New("weekday deve ir de 0 (domingo) a 6 (sábado)")
+		}
+		start, err1 := time.Parse("15:04", w.Start)
+		end, err2 := time.Parse("15:04", w.End)
+		if err1 != nil || err2 != nil {
+			return errors.New("Horários do expediente devem estar no formato HH:MM")
+		}
+		if !start.Before(end) {
+			return errors.New("O início do expediente deve ser anterior ao fim")
+		}
+	}
+	if cal.Holidays == nil {
+		cal.Holidays = models.Holidays{}
+	}
+	for _, h := range cal.Holidays {
+		if _, err := time.Parse(time.DateOnly, h); err != nil {
+			return fmt.Errorf("Feriado inválido (use AAAA-MM-DD): %s", h)
+		}
+	}
+	return nil
+}
+
+// AddBusinessMinutes soma minutos de horário comercial a start, pulando fora do expediente,
+// fins de semana sem janela e feriados. Sem calendário, soma minutos corridos (24x7).
+// O resultado volta no mesmo fuso de start.
+func AddBusinessMinutes(cal *models.BusinessCalendar, start time.Time, minutes int) time.Time {
+	remaining := time.Duration(minutes) * time.Minute
+	if cal == nil || len(cal.Hours) == 0 {
+		return start.Add(remaining)
+	}
+	loc, err := time.LoadLocation(cal.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	holidays := make(map[string]bool, len(cal.Holidays))
+	for _, h := range cal.Holidays {
+		holidays[h] = true
+	}
+
+	t := start.In(loc)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	for i := 0; i < maxSearchDays; i, day = i+1, day.AddDate(0, 0, 1) {
+		if holidays[day.Format(time.DateOnly)] {
+			continue
+		}
+		for _, w := range windowsOn(cal, day) {
+			if !t.Before(w.end) {
+				continue
+			}
+			begin := w.start
+			if t.After(begin) {
+				begin = t
+			}
+			available := w.end.Sub(begin)
+			if remaining <= available {
+				return begin.Add(remaining).In(start.Location())
+			}
+			remaining -= available
+			t = w.end
+		}
+	}
+	// Calendário sem expediente alcançável: cai para tempo corrido
+	return start.Add(time.Duration(minutes) * time.Minute)
+}
+
+// windowsOn devolve os expedientes do dia, em ordem.
+func windowsOn(cal *models.BusinessCalendar, day time.Time) []window {
+	var windows []window
+	for _, w := range cal.Hours {
+		if time.Weekday(w.Weekday) != day.Weekday() {
+			continue
+		}
+		start, err1 := time.Parse("15:04", w.Start)
+		end, err2 := time.Parse("15:04", w.End)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		windows = append(windows, window{
+			start: time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), 0, 0, day.Location()),
+			end:   time.Date(day.Year(), day.Month(), day.Day(), end.Hour(), end.Minute(), 0, 0, day.Location()),
+		})
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i].start.Before(windows[j].start) })
+	return windows
+}
@@ -0,0 +1,125 @@
+package sla
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata" // Fuso do calendário sem depender do zoneinfo da máquina
+
+	"nexus/internal/models"
+)
+
+// saoPaulo é UTC-3 o ano todo (sem horário de verão desde 2019).
+var saoPaulo = mustLoadLocation("America/Sao_Paulo")
+
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
+
+// at monta um horário em São Paulo: at("2026-03-02 10:00").
+func at(value string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04", value, saoPaulo)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// weekdays monta a grade de segunda a sexta com as janelas informadas ("09:00", "18:00", ...).
+func weekdays(bounds ...string) models.BusinessHours {
+	var hours models.BusinessHours
+	for day := 1; day <= 5; day++ {
+		for i := 0; i+1 < len(bounds); i += 2 {
+			hours = append(hours, models.BusinessWindow{Weekday: day, Start: bounds[i], End: bounds[i+1]})
+		}
+	}
+	return hours
+}
+
+func TestAddBusinessMinutes(t *testing.T) {
+	commercial := &models.BusinessCalendar{
+		Timezone: "America/Sao_Paulo",
+		Hours:    weekdays("09:00", "18:00"),
+		Holidays: models.Holidays{"2026-12-25"},
+	}
+	lunchBreak := &models.BusinessCalendar{
+		Timezone: "America/Sao_Paulo",
+		Hours:    weekdays("13:00", "18:00", "09:00", "12:00"), // fora de ordem de propósito
+	}
+
+	// 2026-03-02 é segunda; 2026-03-06, sexta; 2026-12-25 (feriado) cai numa sexta
+	tests := []struct {
+		name    string
+		cal     *models.BusinessCalendar
+		start   time.Time
+		minutes int
+		want    time.Time
+	}{
+		{"sem calendário é 24x7", nil, at("2026-03-07 23:00"), 120, at("2026-03-08 01:00")},
+		{"calendário sem grade é 24x7", &models.BusinessCalendar{Timezone: "America/Sao_Paulo"}, at("2026-03-07 23:00"), 120, at("2026-03-08 01:00")},
+		{"dentro do expediente", commercial, at("2026-03-02 10:00"), 60, at("2026-03-02 11:00")},
+		{"antes do expediente começa às 9h", commercial, at("2026-03-02 07:00"), 30, at("2026-03-02 09:30")},
+		{"termina no fim exato do expediente", commercial, at("2026-03-02 17:00"), 60, at("2026-03-02 18:00")},
+		{"passa para o dia seguinte", commercial, at("2026-03-02 17:00"), 120, at("2026-03-03 10:00")},
+		{"depois do expediente conta do dia seguinte", commercial, at("2026-03-02 20:00"), 30, at("2026-03-03 09:30")},
+		{"pula o fim de semana", commercial, at("2026-03-06 17:30"), 60, at("2026-03-09 09:30")},
+		{"aberto no sábado conta da segunda", commercial, at("2026-03-07 10:00"), 60, at("2026-03-09 10:00")},
+		{"vários dias úteis", commercial, at("2026-03-02 09:00"), 3 * 9 * 60, at("2026-03-04 18:00")},
+		{"pula o feriado", commercial, at("2026-12-24 17:00"), 120, at("2026-12-28 10:00")},
+		{"janelas ordenadas e almoço pulado", lunchBreak, at("2026-03-02 11:30"), 60, at("2026-03-02 13:30")},
+		{"aberto no almoço conta da volta", lunchBreak, at("2026-03-02 12:30"), 30, at("2026-03-02 13:30")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AddBusinessMinutes(tt.cal, tt.start, tt.minutes)
+			if !got.Equal(tt.want) {
+				t.Errorf("AddBusinessMinutes(%s, %d) = %s, esperado %s", tt.start, tt.minutes, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddBusinessMinutesTimezone(t *testing.T) {
+	cal := &models.BusinessCalendar{Timezone: "America/Sao_Paulo", Hours: weekdays("09:00", "18:00")}
+
+	// 11:00 UTC é 08:00 em São Paulo: o prazo começa a correr às 09:00 locais (12:00 UTC)
+	start := time.Date(2026, 3, 2, 11, 0, 0, 0, time.UTC)
+	got := AddBusinessMinutes(cal, start, 60)
+	want := time.Date(2026, 3, 2, 13, 0, 0, 0, time.UTC)
+	if !got.Equal(want) {
+		t.Errorf("AddBusinessMinutes = %s, esperado %s", got, want)
+	}
+	if got.Location() != time.UTC {
+		t.Errorf("o resultado deve voltar no fuso de start (UTC), veio em %s", got.Location())
+	}
+
+	// 20:30 UTC de sexta é 17:30 em São Paulo: sobra meia hora na sexta e meia na segunda
+	start = time.Date(2026, 3, 6, 20, 30, 0, 0, time.UTC)
+	got = AddBusinessMinutes(cal, start, 60)
+	want = time.Date(2026, 3, 9, 12, 30, 0, 0, time.UTC)
+	if !got.Equal(want) {
+		t.Errorf("AddBusinessMinutes = %s, esperado %s", got, want)
+	}
+}
+
+func TestAddBusinessMinutesFallback(t *testing.T) {
+	start := at("2026-03-02 10:00")
+
+	// Grade sem nenhuma janela válida: a busca desiste em maxSearchDays e soma tempo corrido
+	unreachable := &models.BusinessCalendar{
+		Timezone: "America/Sao_Paulo",
+		Hours:    models.BusinessHours{{Weekday: 1, Start: "9h", End: "18h"}},
+	}
+	if got, want := AddBusinessMinutes(unreachable, start, 90), start.Add(90*time.Minute); !got.Equal(want) {
+		t.Errorf("sem expediente alcançável = %s, esperado %s", got, want)
+	}
+
+	// Fuso inválido cai para UTC: 09:00-18:00 UTC é 06:00-15:00 em São Paulo
+	badZone := &models.BusinessCalendar{Timezone: "Nowhere/Invalid", Hours: weekdays("09:00", "18:00")}
+	if got, want := AddBusinessMinutes(badZone, at("2026-03-02 14:30"), 60), at("2026-03-03 06:30"); !got.Equal(want) {
+		t.Errorf("fuso inválido = %s, esperado %s", got, want)
+	}
+}
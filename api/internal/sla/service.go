@@ -0,0 +1,124 @@
+package sla
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"nexus/internal/models"
+	"nexus/internal/repository"
+)
+
+// Service calcula os prazos de SLA dos chamados a partir da política do contrato.
+type Service struct {
+	contracts repository.ContractRepository
+	policies  repository.Repository[*models.SLAPolicy]
+	calendars repository.Repository[*models.BusinessCalendar]
+}
+
+// NewService cria o serviço de SLA.
+func NewService(
+	contracts repository.ContractRepository,
+	policies repository.Repository[*models.SLAPolicy],
+	calendars repository.Repository[*models.BusinessCalendar],
+) *Service {
+	return &Service{contracts: contracts, policies: policies, calendars: calendars}
+}
+
+// ValidatePolicy confere prioridades e prazos e preenche o percentual de risco padrão.
+// O erro já é a mensagem para o cliente; nome e faixa do percentual ficam nas tags `validate`.
+func ValidatePolicy(policy *models.SLAPolicy) error {
+	if len(policy.Targets) == 0 {
+		return errors.New("Informe os prazos por prioridade em targets")
+	}
+	for priority, target := range policy.Targets {
+		if !slices.Contains(models.TicketPriorities, priority) {
+			return errors.New("Prioridade inválida em targets: use " + strings.Join(models.TicketPriorities, ", "))
+		}
+		if target.ResponseMinutes < 0 || target.ResolutionMinutes < 0 {
+			return errors.New("Os prazos não podem ser negativos")
+		}
+		if target.ResolutionMinutes > 0 && target.ResponseMinutes > target.ResolutionMinutes {
+			return fmt.Errorf("Prioridade %s: o prazo de resposta não pode passar o de resolução", priority)
+		}
+	}
+	if policy.AtRiskPercent == 0 {
+		policy.AtRiskPercent = models.DefaultAtRiskPercent
+	}
+	return nil
+}
+
+// Schedule preenche os prazos do chamado (contados de CreatedAt) conforme a política
+// do contrato e a prioridade. Chamado sem contrato ou contrato sem política fica sem SLA.
+func (s *Service) Schedule(ctx context.Context, ticket *models.Ticket) error {
+	noSLA := func() {
+		ticket.ResponseDueAt, ticket.ResponseRiskAt = nil, nil
+		ticket.ResolutionDueAt, ticket.ResolutionRiskAt = nil, nil
+		ticket.SLAStatus = ""
+	}
+	if ticket.ContractID == nil {
+		noSLA()
+		return nil
+	}
+
+	contracts, err := s.contracts.Get(ctx, ticket.ContractID)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar contrato: %w", err)
+	}
+	if len(contracts) == 0 || contracts[0].SLAPolicyID == nil {
+		noSLA()
+		return nil
+	}
+	policies, err := s.policies.Get(ctx, contracts[0].SLAPolicyID)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar política de SLA: %w", err)
+	}
+	if len(policies) == 0 {
+		noSLA()
+		return nil
+	}
+	policy := policies[0]
+
+	var calendar *models.BusinessCalendar
+	if policy.CalendarID != nil {
+		calendars, err := s.calendars.Get(ctx, policy.CalendarID)
+		if err != nil {
+			return fmt.Errorf("erro ao buscar calendário: %w", err)
+		}
+		if len(calendars) > 0 {
+			calendar = calendars[0]
+		}
+	}
+
+	ApplyPolicy(ticket, policy, calendar)
+	return nil
+}
+
+// ApplyPolicy calcula prazos e pontos de risco do chamado. Prazo zerado (ou prioridade
+// sem alvo na política) deixa aquela meta sem prazo.
+func ApplyPolicy(ticket *models.Ticket, policy *models.SLAPolicy, calendar *models.BusinessCalendar) {
+	target := policy.Targets[ticket.Priority]
+	riskPercent := policy.AtRiskPercent
+	if riskPercent == 0 {
+		riskPercent = models.DefaultAtRiskPercent
+	}
+
+	deadline := func(minutes int) (due, risk *time.Time) {
+		if minutes <= 0 {
+			return nil, nil
+		}
+		d := AddBusinessMinutes(calendar, ticket.CreatedAt, minutes)
+		r := AddBusinessMinutes(calendar, ticket.CreatedAt, minutes*riskPercent/100)
+		return &d, &r
+	}
+	ticket.ResponseDueAt, ticket.ResponseRiskAt = deadline(target.ResponseMinutes)
+	ticket.ResolutionDueAt, ticket.ResolutionRiskAt = deadline(target.ResolutionMinutes)
+
+	ticket.SLAStatus = ""
+	if ticket.ResponseDueAt != nil || ticket.ResolutionDueAt != nil {
+		ticket.SLAStatus = models.SLAOnTrack
+	}
+}
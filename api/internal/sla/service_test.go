@@ -0,0 +1,103 @@
+package sla
+
+import (
+	"testing"
+	"time"
+
+	"nexus/internal/models"
+)
+
+func TestApplyPolicy(t *testing.T) {
+	created := at("2026-03-02 10:00")
+	ptr := func(t time.Time) *time.Time { return &t }
+
+	tests := []struct {
+		name           string
+		priority       string
+		policy         *models.SLAPolicy
+		calendar       *models.BusinessCalendar
+		responseDue    *time.Time
+		responseRisk   *time.Time
+		resolutionDue  *time.Time
+		resolutionRisk *time.Time
+		status         string
+	}{
+		{
+			name:     "percentual de risco da política",
+			priority: "high",
+			policy: &models.SLAPolicy{AtRiskPercent: 50, Targets: models.SLATargets{
+				"high": {ResponseMinutes: 60, ResolutionMinutes: 480},
+			}},
+			responseDue:    ptr(at("2026-03-02 11:00")),
+			responseRisk:   ptr(at("2026-03-02 10:30")),
+			resolutionDue:  ptr(at("2026-03-02 18:00")),
+			resolutionRisk: ptr(at("2026-03-02 14:00")),
+			status:         models.SLAOnTrack,
+		},
+		{
+			name:     "percentual zerado usa o padrão (80%)",
+			priority: "low",
+			policy: &models.SLAPolicy{Targets: models.SLATargets{
+				"low": {ResponseMinutes: 100},
+			}},
+			responseDue:  ptr(at("2026-03-02 11:40")),
+			responseRisk: ptr(at("2026-03-02 11:20")),
+			status:       models.SLAOnTrack,
+		},
+		{
+			name:     "prazos em horário comercial",
+			priority: "high",
+			policy: &models.SLAPolicy{AtRiskPercent: 50, Targets: models.SLATargets{
+				"high": {ResponseMinutes: 60, ResolutionMinutes: 960}, // 16h úteis
+			}},
+			calendar:       &models.BusinessCalendar{Timezone: "America/Sao_Paulo", Hours: weekdays("09:00", "18:00")},
+			responseDue:    ptr(at("2026-03-02 11:00")),
+			responseRisk:   ptr(at("2026-03-02 10:30")),
+			resolutionDue:  ptr(at("2026-03-03 17:00")),
+			resolutionRisk: ptr(at("2026-03-02 18:00")),
+			status:         models.SLAOnTrack,
+		},
+		{
+			name:     "prioridade sem alvo fica sem SLA",
+			priority: "urgent",
+			policy: &models.SLAPolicy{AtRiskPercent: 80, Targets: models.SLATargets{
+				"high": {ResponseMinutes: 60},
+			}},
+		},
+		{
+			name:     "prazo zerado deixa a meta sem prazo",
+			priority: "medium",
+			policy: &models.SLAPolicy{AtRiskPercent: 50, Targets: models.SLATargets{
+				"medium": {ResolutionMinutes: 120},
+			}},
+			resolutionDue:  ptr(at("2026-03-02 12:00")),
+			resolutionRisk: ptr(at("2026-03-02 11:00")),
+			status:         models.SLAOnTrack,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ticket := &models.Ticket{Priority: tt.priority, CreatedAt: created, SLAStatus: "breached"}
+			ApplyPolicy(ticket, tt.policy, tt.calendar)
+
+			checkTime(t, "responseDueAt", ticket.ResponseDueAt, tt.responseDue)
+			checkTime(t, "responseRiskAt", ticket.ResponseRiskAt, tt.responseRisk)
+			checkTime(t, "resolutionDueAt", ticket.ResolutionDueAt, tt.resolutionDue)
+			checkTime(t, "resolutionRiskAt", ticket.ResolutionRiskAt, tt.resolutionRisk)
+			if ticket.SLAStatus != tt.status {
+				t.Errorf("slaStatus = %q, esperado %q", ticket.SLAStatus, tt.status)
+			}
+		})
+	}
+}
+
+func checkTime(t *testing.T, field string, got, want *time.Time) {
+	t.Helper()
+	switch {
+	case got == nil && want == nil:
+	case got == nil || want == nil:
+		t.Errorf("%s = %v, esperado %v", field, got, want)
+	case !got.Equal(*want):
+		t.Errorf("%s = %s, esperado %s", field, *got, *want)
+	}
+}
@@ -0,0 +1,45 @@
+package sla
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"nexus/internal/repository"
+)
+
+// Worker reavalia periodicamente o SLA dos chamados em aberto (em risco / violado).
+type Worker struct {
+	tickets  repository.TicketRepository
+	interval time.Duration
+}
+
+// NewWorker cria o worker de SLA; interval é o tempo entre as verificações.
+func NewWorker(tickets repository.TicketRepository, interval time.Duration) *Worker {
+	return &Worker{tickets: tickets, interval: interval}
+}
+
+// Run verifica na hora e depois a cada intervalo, até o contexto ser cancelado.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		w.check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Worker) check(ctx context.Context) {
+	changed, err := w.tickets.RefreshSLAStatus(ctx, time.Now())
+	if err != nil {
+		log.Printf("Erro ao verificar SLA dos chamados: %v", err)
+		return
+	}
+	for _, t := range changed {
+		log.Printf("⏱️ SLA do chamado #%d (%s): %s", t.ID, t.Title, t.SLAStatus)
+	}
+}
@@ -0,0 +1,408 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"nexus/internal/audit"
+	"nexus/internal/auth"
+	"nexus/internal/domain"
+	"nexus/internal/models"
+	"nexus/internal/repository"
+	"nexus/internal/validation"
+)
+
+// AppointmentUsecase aplica as regras dos apontamentos: dono (o usuário logado, ou qualquer
+// um para o admin), semana aberta na folha de horas, contrato ativo e na vigência, chamado
+// do mesmo contrato e saldo de horas conforme a OverrunPolicy.
+type AppointmentUsecase interface {
+	// Create grava o apontamento; sem EndTime ele fica em andamento. allowOverlap (só admin)
+	// libera a sobreposição com outro apontamento do mesmo usuário.
+	Create(ctx context.Context, appt *models.Appointment, allowOverlap bool) (*models.Appointment, error)
+	Update(ctx context.Context, appt *models.Appointment, allowOverlap bool) error
+	Delete(ctx context.Context, id int64) error
+	Restore(ctx context.Context, id int64) (*models.Appointment, error)
+	// StartTimer para o apontamento em andamento do usuário (se houver) e inicia outro no mesmo instante.
+	StartTimer(ctx context.Context, req models.StartTimerRequest) (*models.StartTimerResponse, error)
+	StopTimer(ctx context.Context, id int64) (*models.Appointment, error)
+}
+
+type appointmentUsecase struct {
+	tx              repository.TxManager
+	appointmentRepo repository.AppointmentRepository
+	contractRepo    repository.ContractRepository
+	ticketRepo      repository.TicketRepository
+	timesheetRepo   repository.TimesheetRepository
+}
+
+// NewAppointmentUsecase cria o caso de uso de apontamentos.
+func NewAppointmentUsecase(tx repository.TxManager, appointmentRepo repository.AppointmentRepository, contractRepo repository.ContractRepository, ticketRepo repository.TicketRepository, timesheetRepo repository.TimesheetRepository) AppointmentUsecase {
+	return &appointmentUsecase{
+		tx:              tx,
+		appointmentRepo: appointmentRepo,
+		contractRepo:    contractRepo,
+		ticketRepo:      ticketRepo,
+		timesheetRepo:   timesheetRepo,
+	}
+}
+
+func (u *appointmentUsecase) Create(ctx context.Context, appt *models.Appointment, allowOverlap bool) (*models.Appointment, error) {
+	// O dono do apontamento é o usuário logado; só admin pode lançar em nome de outro
+	currentUser := auth.UserFromContext(ctx)
+	if appt.UserID == 0 {
+		appt.UserID = currentUser.ID
+	} else if !OwnAppointment(currentUser, appt) {
+		return nil, domain.Forbidden("foreign_user", "Você não pode lançar horas em nome de outro usuário").On("userId")
+	}
+
+	// Campos controlados pelo servidor não vêm do corpo
+	appt.CreatedAt = time.Now()
+	appt.AllowOverlap = false
+	appt.InvoiceLineID = nil
+	if allowOverlap {
+		if !auth.IsAdmin(currentUser) {
+			return nil, domain.Forbidden("overlap_admin_only", "Somente admin pode liberar apontamentos sobrepostos")
+		}
+		appt.AllowOverlap = true
+	}
+	if err := validateWindow(appt); err != nil {
+		return nil, err
+	}
+
+	var saved *models.Appointment
+	err := u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.checkWeekOpen(ctx, appt); err != nil {
+			return err
+		}
+		if err := u.checkContract(ctx, appt, nil); err != nil {
+			return err
+		}
+		var err error
+		if saved, err = save(ctx, u.appointmentRepo, appt); err != nil {
+			return domain.FromPostgres(err)
+		}
+		return nil
+	})
+	return saved, err
+}
+
+func (u *appointmentUsecase) Update(ctx context.Context, appt *models.Appointment, allowOverlap bool) error {
+	currentUser := auth.UserFromContext(ctx)
+	return u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		previous, err := u.owned(ctx, appt.ID)
+		if err != nil {
+			return err
+		}
+
+		// Campos que o cliente não controla no PUT
+		if appt.UserID == 0 {
+			appt.UserID = previous.UserID
+		} else if !OwnAppointment(currentUser, appt) {
+			return domain.Forbidden("foreign_user", "Você não pode mover o apontamento para outro usuário").On("userId")
+		}
+		appt.CreatedAt = previous.CreatedAt
+		appt.InvoiceLineID = previous.InvoiceLineID
+		appt.AllowOverlap = previous.AllowOverlap
+		if allowOverlap {
+			if !auth.IsAdmin(currentUser) {
+				return domain.Forbidden("overlap_admin_only", "Somente admin pode liberar apontamentos sobrepostos")
+			}
+			appt.AllowOverlap = true
+		}
+		if err := validateWindow(appt); err != nil {
+			return err
+		}
+
+		// Nem sair de uma semana travada, nem entrar em uma
+		if err := u.checkWeekOpen(ctx, previous); err != nil {
+			return err
+		}
+		if err := u.checkWeekOpen(ctx, appt); err != nil {
+			return err
+		}
+		// A auditoria guarda a versão gravada, sem as horas recalculadas pelo checkContract
+		before := *previous
+		if err := u.checkContract(ctx, appt, previous); err != nil {
+			return err
+		}
+
+		updated, err := update(ctx, u.appointmentRepo, appt, &before)
+		if err != nil {
+			return domain.FromPostgres(err)
+		}
+		if !updated {
+			return domain.NotFound("appointment_not_found", "Apontamento não encontrado")
+		}
+		return nil
+	})
+}
+
+// Delete remove o apontamento se a semana dele ainda aceitar alterações.
+func (u *appointmentUsecase) Delete(ctx context.Context, id int64) error {
+	return u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		existing, err := u.owned(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := u.checkWeekOpen(ctx, existing); err != nil {
+			return err
+		}
+		removed, err := remove(ctx, u.appointmentRepo, existing)
+		if err != nil {
+			return err
+		}
+		if !removed {
+			return domain.NotFound("appointment_not_found", "Apontamento não encontrado")
+		}
+		return nil
+	})
+}
+
+// Restore desfaz a remoção se a semana do apontamento ainda aceitar alterações e ele não
+// bater com outro apontamento (cronômetro ou sobreposição).
+func (u *appointmentUsecase) Restore(ctx context.Context, id int64) (*models.Appointment, error) {
+	var restored *models.Appointment
+	err := u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		existing, err := getIncludingDeleted(ctx, u.appointmentRepo, id)
+		if err != nil {
+			return fmt.Errorf("erro ao buscar apontamento: %w", err)
+		}
+		if existing == nil || !OwnAppointment(auth.UserFromContext(ctx), existing) {
+			return domain.NotFound("appointment_not_found", "Apontamento não encontrado")
+		}
+		if existing.DeletedAt == nil {
+			return domain.NotFound("appointment_not_found", "Apontamento removido não encontrado")
+		}
+		if err := u.checkWeekOpen(ctx, existing); err != nil {
+			return err
+		}
+		if restored, err = restore(ctx, u.appointmentRepo, existing); err != nil {
+			return domain.FromPostgres(err)
+		}
+		if restored == nil {
+			return domain.NotFound("appointment_not_found", "Apontamento removido não encontrado")
+		}
+		return nil
+	})
+	return restored, err
+}
+
+func (u *appointmentUsecase) StartTimer(ctx context.Context, req models.StartTimerRequest) (*models.StartTimerResponse, error) {
+	if req.ContractID == 0 {
+		return nil, domain.Invalid(domain.Field("contractId", domain.CodeRequired, "O contrato é obrigatório"))
+	}
+
+	appt := &models.Appointment{
+		ContractID:  req.ContractID,
+		UserID:      req.UserID,
+		TicketID:    req.TicketID,
+		Description: req.Description,
+		StartTime:   time.Now(),
+	}
+	currentUser := auth.UserFromContext(ctx)
+	if appt.UserID == 0 {
+		appt.UserID = currentUser.ID
+	} else if !OwnAppointment(currentUser, appt) {
+		return nil, domain.Forbidden("foreign_user", "Você não pode iniciar cronômetro em nome de outro usuário").On("userId")
+	}
+
+	var stopped *models.Appointment
+	err := u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.checkWeekOpen(ctx, appt); err != nil {
+			return err
+		}
+		if err := u.checkContract(ctx, appt, nil); err != nil {
+			return err
+		}
+		var err error
+		if stopped, err = u.appointmentRepo.StartTimer(ctx, appt); err != nil {
+			return domain.FromPostgres(err)
+		}
+		audit.Record(ctx, u.appointmentRepo.GetTableName(), models.AuditCreate, appt.ID, nil, appt)
+		if stopped != nil {
+			u.auditStopped(ctx, stopped)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	appt.FillDuration(appt.StartTime)
+	if stopped != nil {
+		stopped.FillDuration(appt.StartTime)
+	}
+	return &models.StartTimerResponse{Started: appt, Stopped: stopped}, nil
+}
+
+func (u *appointmentUsecase) StopTimer(ctx context.Context, id int64) (*models.Appointment, error) {
+	var stopped *models.Appointment
+	err := u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := u.owned(ctx, id); err != nil {
+			return err
+		}
+		var err error
+		if stopped, err = u.appointmentRepo.StopTimer(ctx, id, time.Now()); err != nil {
+			return err
+		}
+		if stopped == nil {
+			return domain.Conflict("timer_stopped", "Este apontamento já foi finalizado")
+		}
+		u.auditStopped(ctx, stopped)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	stopped.FillDuration(*stopped.EndTime)
+	return stopped, nil
+}
+
+// auditStopped registra na auditoria o fim gravado ao parar um cronômetro.
+func (u *appointmentUsecase) auditStopped(ctx context.Context, stopped *models.Appointment) {
+	running := *stopped
+	running.EndTime = nil
+	audit.Record(ctx, u.appointmentRepo.GetTableName(), models.AuditUpdate, stopped.ID, &running, stopped)
+}
+
+// owned busca o apontamento ativo do usuário logado (qualquer um, para o admin).
+// Apontamento de outro consultor é tratado como inexistente.
+func (u *appointmentUsecase) owned(ctx context.Context, id int64) (*models.Appointment, error) {
+	appt, err := get(ctx, u.appointmentRepo, id)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar apontamento: %w", err)
+	}
+	if appt == nil || !OwnAppointment(auth.UserFromContext(ctx), appt) {
+		return nil, domain.NotFound("appointment_not_found", "Apontamento não encontrado")
+	}
+	return appt, nil
+}
+
+// validateWindow confere as tags do apontamento e a janela: sem EndTime ele está em andamento.
+func validateWindow(appt *models.Appointment) error {
+	fields := validation.Struct(appt)
+	if appt.EndTime != nil && appt.EndTime.Before(appt.StartTime) {
+		fields = append(fields, domain.Field("endTime", "end_before_start", "A data de fim não pode ser anterior ao início"))
+	}
+	if len(fields) > 0 {
+		return domain.Invalid(fields...)
+	}
+	return nil
+}
+
+// checkWeekOpen confere se a semana do apontamento (pelo início) ainda aceita alterações na folha de horas:
+// aprovada trava para todos; enviada trava para o consultor até o admin recusar.
+// A semana é a do início em UTC, a mesma que o banco usa (date_trunc na sessão em UTC).
+func (u *appointmentUsecase) checkWeekOpen(ctx context.Context, appt *models.Appointment) error {
+	week := models.WeekStart(appt.StartTime.UTC())
+	status, err := u.timesheetRepo.WeekStatus(ctx, appt.UserID, week)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar folha de horas: %w", err)
+	}
+	if !models.TimesheetLocked(status, auth.IsAdmin(auth.UserFromContext(ctx))) {
+		return nil
+	}
+	if status == models.TimesheetApproved {
+		return domain.Conflict("week_approved", "A semana de "+week.Format("02/01/2006")+" já foi aprovada e não pode mais ser alterada")
+	}
+	return domain.Conflict("week_submitted", "A semana de "+week.Format("02/01/2006")+" está em aprovação; ela só pode ser alterada se for recusada")
+}
+
+// checkContract valida o apontamento contra o contrato: ativo, dentro da vigência e com saldo,
+// e contra o chamado (se houver), que precisa ser da mesma empresa e estar aberto.
+// Com saldo esgotado aplica a OverrunPolicy do contrato (recusa, avisa ou marca IsOverrun).
+// previous é a versão gravada (no update), cujas horas já estão no consumo do contrato.
+func (u *appointmentUsecase) checkContract(ctx context.Context, appt, previous *models.Appointment) error {
+	contract, err := get(ctx, u.contractRepo, appt.ContractID)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar contrato: %w", err)
+	}
+	if contract == nil {
+		return domain.Invalid(domain.Field("contractId", "contract_not_found", "Contrato não encontrado"))
+	}
+
+	if !contract.IsActive {
+		return domain.Invalid(domain.Field("contractId", "contract_inactive", "Não é possível lançar horas em um contrato inativo"))
+	}
+	end := appt.StartTime
+	if appt.EndTime != nil {
+		end = *appt.EndTime
+	}
+	if !contract.CoversDay(appt.StartTime) || !contract.CoversDay(end) {
+		return domain.Invalid(domain.Field("startTime", "outside_contract_term", "O apontamento está fora da vigência do contrato ("+
+			contract.StartDate.Format("02/01/2006")+" a "+contract.EndDate.Format("02/01/2006")+")"))
+	}
+	if appt.TicketID != nil {
+		if err := u.checkTicket(ctx, appt, previous, contract); err != nil {
+			return err
+		}
+	}
+
+	appt.IsOverrun = false
+	if !contract.HasHourLimit() {
+		return nil
+	}
+
+	// No banco de horas o saldo é o do período em que o apontamento começa
+	balance, err := u.contractRepo.GetBalance(ctx, contract.ID, appt.StartTime)
+	if err != nil {
+		return fmt.Errorf("erro ao calcular saldo do contrato: %w", err)
+	}
+	consumed := balance.ConsumedHours
+	if previous != nil && previous.ContractID == contract.ID && balance.CoversPeriod(previous.StartTime) {
+		previous.FillDuration(time.Now())
+		consumed -= previous.TotalHours
+	}
+	appt.FillDuration(end)
+	projected := consumed + appt.TotalHours
+	// Em andamento (sem horas ainda) só passa se sobrar saldo
+	exhausted := projected > balance.ContractedHours || (appt.EndTime == nil && projected >= balance.ContractedHours)
+	if !exhausted {
+		return nil
+	}
+
+	switch contract.OverrunPolicy {
+	case models.OverrunReject:
+		return domain.Conflict("hours_exhausted", overrunMessage(balance))
+	case models.OverrunFlag:
+		appt.IsOverrun = true
+	}
+	appt.Warnings = append(appt.Warnings, overrunMessage(balance))
+	return nil
+}
+
+// overrunMessage explica o estouro; no banco de horas diz de qual período.
+func overrunMessage(balance *models.ContractBalance) string {
+	if balance.PeriodStart == nil {
+		return "O saldo de horas do contrato está esgotado"
+	}
+	return "O saldo de horas do contrato está esgotado no período de " +
+		balance.PeriodStart.Format("02/01/2006") + " a " + balance.PeriodEnd.Format("02/01/2006")
+}
+
+// checkTicket confere se o chamado do apontamento existe, é do mesmo contrato (ou da empresa
+// do contrato, se o chamado não tiver contrato) e não está fechado. Um apontamento que já era
+// do chamado continua podendo ser editado depois do fechamento.
+func (u *appointmentUsecase) checkTicket(ctx context.Context, appt, previous *models.Appointment, contract *models.Contract) error {
+	ticket, err := get(ctx, u.ticketRepo, *appt.TicketID)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar chamado: %w", err)
+	}
+	if ticket == nil {
+		return domain.Invalid(domain.Field("ticketId", "ticket_not_found", "Chamado não encontrado"))
+	}
+	if ticket.CompanyID != contract.CompanyId || (ticket.ContractID != nil && *ticket.ContractID != contract.ID) {
+		return domain.Invalid(domain.Field("ticketId", "ticket_other_contract", "O chamado não pertence ao contrato do apontamento"))
+	}
+	alreadyLinked := previous != nil && previous.TicketID != nil && *previous.TicketID == ticket.ID
+	if ticket.Status == models.TicketClosed && !alreadyLinked {
+		return domain.Invalid(domain.Field("ticketId", "ticket_closed", "Não é possível lançar horas em um chamado fechado"))
+	}
+	return nil
+}
+
+// OwnAppointment é a política de acesso dos apontamentos: admin ou o próprio consultor.
+func OwnAppointment(user *models.User, appt *models.Appointment) bool {
+	return auth.IsAdmin(user) || (user != nil && appt.UserID == user.ID)
+}
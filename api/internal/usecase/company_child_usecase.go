@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"nexus/internal/domain"
+	"nexus/internal/models"
+	"nexus/internal/repository"
+	"nexus/internal/validation"
+)
+
+// CompanyChildUsecase cuida dos cadastros que pertencem a uma empresa (contatos e endereços):
+// a empresa precisa existir, o registro não muda de empresa e só um por empresa fica como
+// principal (marcar um novo desmarca o anterior, na mesma transação).
+type CompanyChildUsecase[T models.CompanyChild] interface {
+	Create(ctx context.Context, child T) (T, error)
+	// Update grava child, que já vem com o ID e a empresa da rota.
+	Update(ctx context.Context, child T) error
+}
+
+type companyChildUsecase[T models.CompanyChild] struct {
+	tx          repository.TxManager
+	repo        repository.Repository[T]
+	companyRepo repository.CompanyRepository
+	notFound    *domain.Error
+}
+
+// NewCompanyContactUsecase cria o caso de uso dos contatos da empresa.
+func NewCompanyContactUsecase(tx repository.TxManager, contactRepo repository.Repository[*models.CompanyContact], companyRepo repository.CompanyRepository) CompanyChildUsecase[*models.CompanyContact] {
+	return &companyChildUsecase[*models.CompanyContact]{
+		tx: tx, repo: contactRepo, companyRepo: companyRepo,
+		notFound: domain.NotFound("contact_not_found", "Contato não encontrado"),
+	}
+}
+
+// NewCompanyAddressUsecase cria o caso de uso dos endereços de cobrança da empresa.
+func NewCompanyAddressUsecase(tx repository.TxManager, addressRepo repository.Repository[*models.CompanyAddress], companyRepo repository.CompanyRepository) CompanyChildUsecase[*models.CompanyAddress] {
+	return &companyChildUsecase[*models.CompanyAddress]{
+		tx: tx, repo: addressRepo, companyRepo: companyRepo,
+		notFound: domain.NotFound("address_not_found", "Endereço não encontrado"),
+	}
+}
+
+func (u *companyChildUsecase[T]) Create(ctx context.Context, child T) (T, error) {
+	var saved T
+	if fields := validation.Struct(child); len(fields) > 0 {
+		return saved, domain.Invalid(fields...)
+	}
+
+	err := u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.checkCompany(ctx, child.GetCompanyID()); err != nil {
+			return err
+		}
+		if err := u.demotePrimary(ctx, child); err != nil {
+			return err
+		}
+		var err error
+		saved, err = save(ctx, u.repo, child)
+		return domain.FromPostgres(err)
+	})
+	return saved, err
+}
+
+func (u *companyChildUsecase[T]) Update(ctx context.Context, child T) error {
+	if fields := validation.Struct(child); len(fields) > 0 {
+		return domain.Invalid(fields...)
+	}
+
+	return u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		id := child.GetID()
+		found, err := u.repo.Get(ctx, &id)
+		if err != nil {
+			return fmt.Errorf("erro ao buscar cadastro da empresa: %w", err)
+		}
+		if len(found) == 0 || found[0].GetCompanyID() != child.GetCompanyID() {
+			return u.notFound
+		}
+		before := found[0]
+		if err := u.demotePrimary(ctx, child); err != nil {
+			return err
+		}
+		_, err = update(ctx, u.repo, child, before)
+		return domain.FromPostgres(err)
+	})
+}
+
+// checkCompany confere se a empresa da rota existe (e não foi removida).
+func (u *companyChildUsecase[T]) checkCompany(ctx context.Context, companyID int64) error {
+	company, err := get(ctx, u.companyRepo, companyID)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar empresa: %w", err)
+	}
+	if company == nil {
+		return domain.NotFound("company_not_found", "Empresa não encontrada")
+	}
+	return nil
+}
+
+// demotePrimary desmarca o principal atual da empresa quando child passa a ser o principal.
+// Cada troca fica na auditoria como uma alteração do registro desmarcado.
+func (u *companyChildUsecase[T]) demotePrimary(ctx context.Context, child T) error {
+	if !child.IsPrimary() {
+		return nil
+	}
+	page, err := u.repo.List(ctx, repository.ListQuery{
+		PageSize: repository.MaxPageSize,
+		Filters: map[string]string{
+			"companyId": strconv.FormatInt(child.GetCompanyID(), 10),
+			"primary":   "true",
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("erro ao buscar o principal atual: %w", err)
+	}
+	for _, current := range page.Items {
+		if current.GetID() == child.GetID() {
+			continue
+		}
+		before, err := get(ctx, u.repo, current.GetID()) // cópia para a auditoria
+		if err != nil {
+			return fmt.Errorf("erro ao buscar o principal atual: %w", err)
+		}
+		current.SetPrimary(false)
+		if _, err := update(ctx, u.repo, current, before); err != nil {
+			return domain.FromPostgres(err)
+		}
+	}
+	return nil
+}
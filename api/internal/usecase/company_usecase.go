@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"nexus/internal/audit"
+	"nexus/internal/document"
+	"nexus/internal/domain"
+	"nexus/internal/lookup"
+	"nexus/internal/models"
+	"nexus/internal/repository"
+	"nexus/internal/validation"
+)
+
+type CompanyUsecase interface {
+	// Create grava uma ou mais empresas; em lote, ou entram todas ou nenhuma.
+	Create(ctx context.Context, companies []*models.Company) ([]*models.Company, error)
+	Update(ctx context.Context, company *models.Company) error
+	// Lookup consulta razão social e endereço do CNPJ no provedor configurado.
+	Lookup(ctx context.Context, cnpj string) (*models.CompanyInfo, error)
+}
+
+type companyUsecase struct {
+	tx          repository.TxManager
+	companyRepo repository.CompanyRepository
+	addressRepo repository.Repository[*models.CompanyAddress]
+	lookup      lookup.Provider
+}
+
+// NewCompanyUsecase cria o caso de uso de empresas. provider é opcional (nil desliga a consulta de CNPJ);
+// com ele, o endereço da consulta vira o endereço principal da empresa cadastrada.
+func NewCompanyUsecase(tx repository.TxManager, companyRepo repository.CompanyRepository, addressRepo repository.Repository[*models.CompanyAddress], provider lookup.Provider) CompanyUsecase {
+	return &companyUsecase{tx: tx, companyRepo: companyRepo, addressRepo: addressRepo, lookup: provider}
+}
+
+func (u *companyUsecase) Create(ctx context.Context, companies []*models.Company) ([]*models.Company, error) {
+	if len(companies) == 0 {
+		return nil, domain.Validation("empty_batch", "Nenhuma empresa para cadastrar")
+	}
+	// Em lote os campos vêm com o índice da empresa: [2].cnpj
+	var fields []domain.FieldError
+	infos := make([]*models.CompanyInfo, len(companies))
+	for i, company := range companies {
+		prefix := ""
+		if len(companies) > 1 {
+			prefix = fmt.Sprintf("[%d].", i)
+		}
+		infos[i] = u.enrich(ctx, company)
+		fields = append(fields, validateCompany(company, prefix)...)
+	}
+	if len(fields) > 0 {
+		return nil, domain.Invalid(fields...)
+	}
+
+	var saved []*models.Company
+	err := u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if len(companies) == 1 {
+			company, err := save(ctx, u.companyRepo, companies[0])
+			if err != nil {
+				return domain.FromPostgres(err)
+			}
+			saved = []*models.Company{company}
+		} else {
+			var err error
+			if saved, err = u.companyRepo.SaveBatch(ctx, companies); err != nil {
+				return domain.FromPostgres(err)
+			}
+			for _, company := range saved {
+				audit.Record(ctx, u.companyRepo.GetTableName(), models.AuditCreate, company.ID, nil, company)
+			}
+		}
+		for i, company := range saved {
+			if err := u.saveLookupAddress(ctx, company, infos[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return saved, err
+}
+
+func (u *companyUsecase) Update(ctx context.Context, company *models.Company) error {
+	if fields := validateCompany(company, ""); len(fields) > 0 {
+		return domain.Invalid(fields...)
+	}
+	return u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := get(ctx, u.companyRepo, company.ID)
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			return domain.NotFound("company_not_found", "Empresa não encontrada")
+		}
+		_, err = update(ctx, u.companyRepo, company, before)
+		return domain.FromPostgres(err)
+	})
+}
+
+func (u *companyUsecase) Lookup(ctx context.Context, cnpj string) (*models.CompanyInfo, error) {
+	if u.lookup == nil {
+		return nil, domain.Unavailable("lookup_disabled", "A consulta de CNPJ não está configurada", nil)
+	}
+	if !document.ValidCNPJ(cnpj) {
+		return nil, domain.Invalid(domain.Field("cnpj", "invalid_cnpj", "cnpj não é um CNPJ válido"))
+	}
+	info, err := u.lookup.LookupCNPJ(ctx, document.NormalizeCNPJ(cnpj))
+	if errors.Is(err, lookup.ErrNotFound) {
+		return nil, domain.NotFound("cnpj_not_found", "CNPJ não encontrado na consulta")
+	}
+	if err != nil {
+		return nil, domain.Unavailable("lookup_failed", "Não foi possível consultar o CNPJ agora", err)
+	}
+	info.CNPJ = document.FormatCNPJ(info.CNPJ)
+	return info, nil
+}
+
+// enrich consulta o CNPJ, quando há provedor, e preenche o nome em branco com a razão social.
+// Devolve a consulta (nil sem provedor ou sem resultado) para o endereço ser gravado depois.
+// Falha na consulta não barra o cadastro aqui: o nome continua obrigatório e a validação acusa.
+func (u *companyUsecase) enrich(ctx context.Context, company *models.Company) *models.CompanyInfo {
+	if u.lookup == nil || company == nil || !document.ValidCNPJ(company.CNPJ) {
+		return nil
+	}
+	info, err := u.lookup.LookupCNPJ(ctx, document.NormalizeCNPJ(company.CNPJ))
+	if err != nil {
+		if !errors.Is(err, lookup.ErrNotFound) {
+			log.Printf("Erro ao consultar CNPJ %s: %v", company.CNPJ, err)
+		}
+		return nil
+	}
+	if strings.TrimSpace(company.Name) == "" {
+		company.Name = info.Name
+	}
+	return info
+}
+
+// saveLookupAddress grava o endereço da consulta como endereço principal da empresa recém-criada.
+// Endereço incompleto (ex.: sem CEP) fica de fora, com registro no log: o cadastro segue manual.
+func (u *companyUsecase) saveLookupAddress(ctx context.Context, company *models.Company, info *models.CompanyInfo) error {
+	if info == nil {
+		return nil
+	}
+	address := &models.CompanyAddress{
+		CompanyID:  company.ID,
+		Street:     info.Address.Street,
+		Number:     info.Address.Number,
+		Complement: info.Address.Complement,
+		District:   info.Address.District,
+		City:       info.Address.City,
+		State:      info.Address.State,
+		ZipCode:    info.Address.ZipCode,
+		Primary:    true,
+	}
+	if fields := validation.Struct(address); len(fields) > 0 {
+		log.Printf("Endereço da consulta do CNPJ %s não gravado: %s", company.CNPJ, domain.Invalid(fields...).Message)
+		return nil
+	}
+	if _, err := save(ctx, u.addressRepo, address); err != nil {
+		return domain.FromPostgres(err)
+	}
+	return nil
+}
+
+// validateCompany devolve os erros de campo da empresa e deixa o CNPJ na forma canônica
+// (sem máscara), a que vai para o banco; prefix identifica a empresa no lote.
+func validateCompany(company *models.Company, prefix string) []domain.FieldError {
+	if company == nil {
+		return []domain.FieldError{domain.Field(strings.TrimSuffix(prefix, "."), domain.CodeRequired, "Empresa vazia")}
+	}
+	fields := validation.Struct(company)
+	for i := range fields {
+		fields[i].Field = prefix + fields[i].Field
+	}
+	company.CNPJ = document.NormalizeCNPJ(company.CNPJ)
+	return fields
+}
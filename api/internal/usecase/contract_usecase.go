@@ -2,15 +2,113 @@ package usecase
 
 import (
 	"context"
+	"fmt"
+	"strings"
+
+	"nexus/internal/domain"
 	"nexus/internal/models"
 	"nexus/internal/repository"
+	"nexus/internal/validation"
 )
 
 type ContractUsecase interface {
-	CreateContract(ctx context.Context, contract models.Contract) (models.Contract, error)
+	Create(ctx context.Context, contract *models.Contract) (*models.Contract, error)
+	Update(ctx context.Context, contract *models.Contract) error
 }
 
 type contractUsecase struct {
+	tx           repository.TxManager
 	contractRepo repository.ContractRepository
 	companyRepo  repository.CompanyRepository
 }
+
+// NewContractUsecase cria o caso de uso de contratos; as operações rodam numa transação de tx.
+func NewContractUsecase(tx repository.TxManager, contractRepo repository.ContractRepository, companyRepo repository.CompanyRepository) ContractUsecase {
+	return &contractUsecase{tx: tx, contractRepo: contractRepo, companyRepo: companyRepo}
+}
+
+// Create confere o contrato e a empresa e grava na mesma transação.
+func (u *contractUsecase) Create(ctx context.Context, contract *models.Contract) (*models.Contract, error) {
+	if fields := validateContract(contract); len(fields) > 0 {
+		return nil, domain.Invalid(fields...)
+	}
+	var saved *models.Contract
+	err := u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.checkCompany(ctx, contract); err != nil {
+			return err
+		}
+		var err error
+		saved, err = save(ctx, u.contractRepo, contract)
+		return domain.FromPostgres(err)
+	})
+	return saved, err
+}
+
+func (u *contractUsecase) Update(ctx context.Context, contract *models.Contract) error {
+	if fields := validateContract(contract); len(fields) > 0 {
+		return domain.Invalid(fields...)
+	}
+	return u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := get(ctx, u.contractRepo, contract.ID)
+		if err != nil {
+			return fmt.Errorf("erro ao buscar contrato: %w", err)
+		}
+		if before == nil {
+			return domain.NotFound("contract_not_found", "Contrato não encontrado")
+		}
+		if err := u.checkCompany(ctx, contract); err != nil {
+			return err
+		}
+		_, err = update(ctx, u.contractRepo, contract, before)
+		return domain.FromPostgres(err)
+	})
+}
+
+// checkCompany exige que a empresa do contrato exista e não esteja removida.
+func (u *contractUsecase) checkCompany(ctx context.Context, contract *models.Contract) error {
+	company, err := get(ctx, u.companyRepo, contract.CompanyId)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar empresa: %w", err)
+	}
+	if company == nil {
+		return domain.Invalid(domain.Field("companyId", "company_not_found", "Empresa não encontrada"))
+	}
+	return nil
+}
+
+// validateContract confere as tags do modelo e depois datas, tipo, horas e política de estouro,
+// preenchendo os padrões. Devolve todos os erros de campo de uma vez.
+func validateContract(contract *models.Contract) []domain.FieldError {
+	fields := validation.Struct(contract)
+	if !contract.EndDate.IsZero() && contract.EndDate.Before(contract.StartDate) {
+		fields = append(fields, domain.Field("endDate", "end_before_start", "Data de fim não pode ser anterior à data de início"))
+	}
+
+	switch contract.ContractType {
+	case models.ContractHourBank, models.ContractFixedPrice:
+		if contract.TotalHours <= 0 {
+			fields = append(fields, domain.Field("totalHours", domain.CodeInvalidValue, "totalHours deve ser maior que zero (horas por período no banco de horas, orçamento no projeto fechado)"))
+		}
+	case models.ContractOnDemand:
+		if contract.TotalHours != 0 {
+			fields = append(fields, domain.Field("totalHours", domain.CodeInvalidValue, "Contrato sob demanda não tem limite de horas: totalHours deve ser 0"))
+		}
+	default:
+		fields = append(fields, domain.Field("contractType", "invalid_contract_type", "Tipo de contrato inválido (use "+strings.Join(models.ContractTypes, ", ")+")"))
+	}
+	if contract.RolloverCapHours < 0 {
+		fields = append(fields, domain.Field("rolloverCapHours", domain.CodeInvalidValue, "O teto de acúmulo (rolloverCapHours) não pode ser negativo"))
+	}
+	if contract.RolloverCapHours > 0 && contract.ContractType != models.ContractHourBank {
+		fields = append(fields, domain.Field("rolloverCapHours", domain.CodeInvalidValue, "Só o banco de horas acumula sobra (rolloverCapHours)"))
+	}
+
+	switch contract.OverrunPolicy {
+	case "":
+		contract.OverrunPolicy = models.OverrunWarn
+	case models.OverrunReject, models.OverrunWarn, models.OverrunFlag:
+	default:
+		fields = append(fields, domain.Field("overrunPolicy", "invalid_overrun_policy", "Política de estouro inválida (use reject, warn ou flag)"))
+	}
+	return fields
+}
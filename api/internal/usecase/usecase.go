@@ -0,0 +1,79 @@
+// Package usecase concentra as regras de negócio: os handlers decodificam a requisição,
+// chamam o caso de uso e traduzem o *domain.Error devolvido em status HTTP. As operações
+// que leem e gravam rodam numa transação (repository.TxManager) e registram a auditoria.
+package usecase
+
+import (
+	"context"
+	"strconv"
+
+	"nexus/internal/audit"
+	"nexus/internal/models"
+	"nexus/internal/repository"
+)
+
+// save, update e remove gravam pelo repositório e registram a operação na auditoria.
+func save[T models.Model](ctx context.Context, repo repository.Repository[T], model T) (T, error) {
+	saved, err := repo.Save(ctx, model)
+	if err == nil {
+		audit.Record(ctx, repo.GetTableName(), models.AuditCreate, saved.GetID(), nil, saved)
+	}
+	return saved, err
+}
+
+// update devolve false se o registro não existir (ou estiver removido). before é a versão gravada.
+func update[T models.Model](ctx context.Context, repo repository.Repository[T], model T, before T) (bool, error) {
+	rowsAffected, err := repo.Update(ctx, model)
+	if err != nil || rowsAffected == 0 {
+		return false, err
+	}
+	audit.Record(ctx, repo.GetTableName(), models.AuditUpdate, model.GetID(), before, model)
+	return true, nil
+}
+
+func remove[T models.Model](ctx context.Context, repo repository.Repository[T], before T) (bool, error) {
+	rowsAffected, err := repo.Delete(ctx, before.GetID())
+	if err != nil || rowsAffected == 0 {
+		return false, err
+	}
+	audit.Record(ctx, repo.GetTableName(), models.AuditDelete, before.GetID(), before, nil)
+	return true, nil
+}
+
+// restore desfaz a remoção lógica e devolve o registro restaurado (nil se não estava removido).
+func restore[T models.Model](ctx context.Context, repo repository.Repository[T], before T) (T, error) {
+	var zero T
+	rowsAffected, err := repo.Restore(ctx, before.GetID())
+	if err != nil || rowsAffected == 0 {
+		return zero, err
+	}
+	restored, err := get(ctx, repo, before.GetID())
+	if err != nil {
+		return zero, err
+	}
+	audit.Record(ctx, repo.GetTableName(), models.AuditRestore, before.GetID(), before, restored)
+	return restored, nil
+}
+
+// get busca um registro ativo pelo ID (nil se não existir ou estiver removido).
+func get[T models.Model](ctx context.Context, repo repository.Repository[T], id int64) (T, error) {
+	var zero T
+	found, err := repo.Get(ctx, &id)
+	if err != nil || len(found) == 0 {
+		return zero, err
+	}
+	return found[0], nil
+}
+
+// getIncludingDeleted busca o registro pelo ID mesmo se ele tiver sido removido logicamente.
+func getIncludingDeleted[T models.Model](ctx context.Context, repo repository.Repository[T], id int64) (T, error) {
+	var zero T
+	page, err := repo.List(ctx, repository.ListQuery{
+		Filters:        map[string]string{"id": strconv.FormatInt(id, 10)},
+		IncludeDeleted: true,
+	})
+	if err != nil || len(page.Items) == 0 {
+		return zero, err
+	}
+	return page.Items[0], nil
+}
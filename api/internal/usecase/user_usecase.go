@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"nexus/internal/auth"
+	"nexus/internal/domain"
+	"nexus/internal/models"
+	"nexus/internal/repository"
+	"nexus/internal/validation"
+)
+
+type UserUsecase interface {
+	Create(ctx context.Context, user *models.User) (*models.User, error)
+	// Update mantém a senha atual se Password vier vazio.
+	Update(ctx context.Context, user *models.User) error
+}
+
+type userUsecase struct {
+	tx       repository.TxManager
+	userRepo repository.UserRepository
+}
+
+// NewUserUsecase cria o caso de uso de usuários.
+func NewUserUsecase(tx repository.TxManager, userRepo repository.UserRepository) UserUsecase {
+	return &userUsecase{tx: tx, userRepo: userRepo}
+}
+
+func (u *userUsecase) Create(ctx context.Context, user *models.User) (*models.User, error) {
+	fields := validateUser(user)
+	if user.Password == "" {
+		fields = append(fields, domain.Field("password", domain.CodeRequired, "A senha do usuário não pode ser vazia"))
+	}
+	if len(fields) > 0 {
+		return nil, domain.Invalid(fields...)
+	}
+
+	var saved *models.User
+	err := u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		exists, err := u.userRepo.EmailExists(ctx, user.Email)
+		if err != nil {
+			return fmt.Errorf("erro ao verificar e-mail: %w", err)
+		}
+		if exists {
+			return domain.Conflict("email_taken", "E-mail já cadastrado").On("email")
+		}
+		if err := hashPassword(user); err != nil {
+			return err
+		}
+		saved, err = save(ctx, u.userRepo, user)
+		return domain.FromPostgres(err)
+	})
+	return saved, err
+}
+
+func (u *userUsecase) Update(ctx context.Context, user *models.User) error {
+	if fields := validateUser(user); len(fields) > 0 {
+		return domain.Invalid(fields...)
+	}
+
+	return u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := get(ctx, u.userRepo, user.ID)
+		if err != nil {
+			return fmt.Errorf("erro ao buscar usuário: %w", err)
+		}
+		if before == nil {
+			return domain.NotFound("user_not_found", "Usuário não encontrado")
+		}
+		if user.Password == "" {
+			user.PasswordHash = before.PasswordHash
+		} else if err := hashPassword(user); err != nil {
+			return err
+		}
+		_, err = update(ctx, u.userRepo, user, before)
+		return domain.FromPostgres(err)
+	})
+}
+
+// validateUser confere o usuário pelas tags (nome, e-mail, papel e tamanho da senha),
+// antes das constraints do banco, que dariam um erro menos claro.
+func validateUser(user *models.User) []domain.FieldError {
+	return validation.Struct(user)
+}
+
+// hashPassword troca a senha em texto puro pelo hash que vai para o banco.
+func hashPassword(user *models.User) error {
+	hash, err := auth.HashPassword(user.Password)
+	if err != nil {
+		return fmt.Errorf("erro ao processar senha: %w", err)
+	}
+	user.PasswordHash = hash
+	user.Password = ""
+	return nil
+}
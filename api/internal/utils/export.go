@@ -0,0 +1,397 @@
+package utils
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Formatos de exportação aceitos em ?format= ou no header Accept
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+
+	mimeCSV  = "text/csv"
+	mimeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// CellKind diz como um valor da coluna deve ser formatado.
+type CellKind int
+
+const (
+	CellText     CellKind = iota
+	CellNumber            // float64 ou decimal.Decimal com 2 casas (ex.: horas, valores)
+	CellInteger           // int64
+	CellDate              // time.Time só com a data
+	CellDateTime          // time.Time com data e hora
+)
+
+// ExportColumn é uma coluna da planilha, com o cabeçalho em cada idioma.
+type ExportColumn struct {
+	Header   string // Cabeçalho em inglês (padrão)
+	HeaderPT string // Cabeçalho em pt-BR
+	Kind     CellKind
+}
+
+// ExportLocale define cabeçalhos e formatos de número e data da exportação.
+type ExportLocale struct {
+	Name           string
+	DecimalComma   bool
+	CSVSeparator   rune
+	DateLayout     string // Layout Go usado no CSV
+	DateTimeLayout string
+	XLSXDate       string // Formato de célula no XLSX
+	XLSXDateTime   string
+}
+
+var (
+	LocaleEN = ExportLocale{
+		Name: "en", CSVSeparator: ',',
+		DateLayout: "2006-01-02", DateTimeLayout: "2006-01-02 15:04",
+		XLSXDate: "yyyy-mm-dd", XLSXDateTime: "yyyy-mm-dd hh:mm",
+	}
+	// pt-BR usa vírgula decimal, então o CSV separa colunas com ";" (padrão do Excel brasileiro)
+	LocalePTBR = ExportLocale{
+		Name: "pt-BR", DecimalComma: true, CSVSeparator: ';',
+		DateLayout: "02/01/2006", DateTimeLayout: "02/01/2006 15:04",
+		XLSXDate: "dd/mm/yyyy", XLSXDateTime: "dd/mm/yyyy hh:mm",
+	}
+)
+
+// ExportFormatFromRequest devolve o formato pedido (?format=csv|xlsx ou Accept), ou "" para JSON.
+func ExportFormatFromRequest(r *http.Request) string {
+	switch strings.ToLower(r.URL.Query().Get("format")) {
+	case FormatCSV:
+		return FormatCSV
+	case FormatXLSX:
+		return FormatXLSX
+	}
+	accept := r.Header.Get("Accept")
+	switch {
+	case strings.Contains(accept, mimeCSV):
+		return FormatCSV
+	case strings.Contains(accept, mimeXLSX):
+		return FormatXLSX
+	}
+	return ""
+}
+
+// ExportLocaleFromRequest escolhe o idioma por ?locale=pt-BR ou pelo Accept-Language. O padrão é inglês.
+func ExportLocaleFromRequest(r *http.Request) ExportLocale {
+	lang := r.URL.Query().Get("locale")
+	if lang == "" {
+		lang = r.Header.Get("Accept-Language")
+	}
+	if strings.HasPrefix(strings.ToLower(lang), "pt") {
+		return LocalePTBR
+	}
+	return LocaleEN
+}
+
+// TableEncoder escreve uma tabela linha a linha direto na resposta HTTP (streaming).
+type TableEncoder interface {
+	// WriteRow recebe os valores na ordem das colunas: string, float64, decimal.Decimal, int64, time.Time ou *time.Time.
+	WriteRow(values ...any) error
+	// Close finaliza o arquivo; precisa ser chamado mesmo se a escrita das linhas falhar.
+	Close() error
+}
+
+// NewTableEncoder escreve os headers HTTP e o cabeçalho da tabela no formato pedido.
+// filename vai sem extensão (ex.: "apontamentos").
+func NewTableEncoder(w http.ResponseWriter, format string, locale ExportLocale, filename string, columns []ExportColumn) (TableEncoder, error) {
+	headers := make([]string, len(columns))
+	for i, col := range columns {
+		headers[i] = col.Header
+		if locale.Name == LocalePTBR.Name && col.HeaderPT != "" {
+			headers[i] = col.HeaderPT
+		}
+	}
+
+	switch format {
+	case FormatCSV:
+		w.Header().Set("Content-Type", mimeCSV+"; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, filename))
+		w.WriteHeader(http.StatusOK)
+		return newCSVEncoder(w, locale, columns, headers)
+	case FormatXLSX:
+		w.Header().Set("Content-Type", mimeXLSX)
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.xlsx"`, filename))
+		w.WriteHeader(http.StatusOK)
+		return newXLSXEncoder(w, locale, columns, headers)
+	}
+	return nil, fmt.Errorf("formato de exportação desconhecido: %s", format)
+}
+
+// --- CSV ---
+
+type csvEncoder struct {
+	w       *csv.Writer
+	locale  ExportLocale
+	columns []ExportColumn
+}
+
+func newCSVEncoder(w io.Writer, locale ExportLocale, columns []ExportColumn, headers []string) (*csvEncoder, error) {
+	// BOM para o Excel abrir o UTF-8 com acentos corretamente
+	if _, err := io.WriteString(w, "\uFEFF"); err != nil {
+		return nil, err
+	}
+	cw := csv.NewWriter(w)
+	cw.Comma = locale.CSVSeparator
+	if err := cw.Write(headers); err != nil {
+		return nil, err
+	}
+	return &csvEncoder{w: cw, locale: locale, columns: columns}, nil
+}
+
+func (e *csvEncoder) WriteRow(values ...any) error {
+	record := make([]string, len(e.columns))
+	for i, col := range e.columns {
+		if i < len(values) {
+			record[i] = formatCell(e.locale, col.Kind, values[i])
+		}
+	}
+	// O csv.Writer é bufferizado: o cliente vai recebendo em blocos
+	return e.w.Write(record)
+}
+
+func (e *csvEncoder) Close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// formatCell formata um valor para o CSV conforme o idioma.
+func formatCell(locale ExportLocale, kind CellKind, value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return formatCell(locale, kind, *v)
+	case time.Time:
+		if kind == CellDate {
+			return v.Format(locale.DateLayout)
+		}
+		return v.Format(locale.DateTimeLayout)
+	case float64:
+		s := strconv.FormatFloat(v, 'f', 2, 64)
+		if locale.DecimalComma {
+			s = strings.Replace(s, ".", ",", 1)
+		}
+		return s
+	case decimal.Decimal:
+		s := v.StringFixed(2)
+		if locale.DecimalComma {
+			s = strings.Replace(s, ".", ",", 1)
+		}
+		return s
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case int:
+		return strconv.Itoa(v)
+	case string:
+		return escapeFormula(v)
+	case *string:
+		if v == nil {
+			return ""
+		}
+		return escapeFormula(*v)
+	}
+	return escapeFormula(fmt.Sprint(value))
+}
+
+// escapeFormula evita que texto do usuário vire fórmula ao abrir o CSV no Excel.
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// --- XLSX ---
+
+// Estilos definidos em xlsxStyles (índice em cellXfs)
+const (
+	xlsxStyleDefault = iota
+	xlsxStyleNumber
+	xlsxStyleDate
+	xlsxStyleDateTime
+	xlsxStyleHeader
+)
+
+// xlsxEncoder gera um XLSX mínimo (uma planilha) escrevendo as linhas direto no zip.
+type xlsxEncoder struct {
+	zip     *zip.Writer
+	sheet   *bufio.Writer
+	columns []ExportColumn
+	row     int
+}
+
+func newXLSXEncoder(w io.Writer, locale ExportLocale, columns []ExportColumn, headers []string) (*xlsxEncoder, error) {
+	zw := zip.NewWriter(w)
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", fmt.Sprintf(xlsxStyles, locale.XLSXDate, locale.XLSXDateTime)},
+	}
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	// A planilha é a última parte: as linhas vão sendo escritas nela até o Close
+	sheet, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	e := &xlsxEncoder{zip: zw, sheet: bufio.NewWriter(sheet), columns: columns}
+	e.sheet.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	e.row++
+	e.sheet.WriteString(fmt.Sprintf(`<row r="%d">`, e.row))
+	for i, h := range headers {
+		e.writeText(i, h, xlsxStyleHeader)
+	}
+	e.sheet.WriteString(`</row>`)
+	return e, nil
+}
+
+func (e *xlsxEncoder) WriteRow(values ...any) error {
+	e.row++
+	e.sheet.WriteString(fmt.Sprintf(`<row r="%d">`, e.row))
+	for i, col := range e.columns {
+		if i >= len(values) {
+			break
+		}
+		e.writeValue(i, col.Kind, values[i])
+	}
+	_, err := e.sheet.WriteString(`</row>`)
+	return err
+}
+
+func (e *xlsxEncoder) Close() error {
+	e.sheet.WriteString(`</sheetData></worksheet>`)
+	if err := e.sheet.Flush(); err != nil {
+		return err
+	}
+	return e.zip.Close()
+}
+
+func (e *xlsxEncoder) writeValue(col int, kind CellKind, value any) {
+	switch v := value.(type) {
+	case nil:
+		return
+	case *time.Time:
+		if v != nil {
+			e.writeValue(col, kind, *v)
+		}
+	case *string:
+		if v != nil {
+			e.writeText(col, *v, xlsxStyleDefault)
+		}
+	case time.Time:
+		style := xlsxStyleDateTime
+		if kind == CellDate {
+			style = xlsxStyleDate
+		}
+		e.writeNumber(col, excelSerial(v), style)
+	case float64:
+		e.writeNumber(col, v, xlsxStyleNumber)
+	case decimal.Decimal:
+		// Escreve o decimal exato, sem passar por float64
+		e.writeNumeric(col, v.String(), xlsxStyleNumber)
+	case int64:
+		e.writeNumber(col, float64(v), xlsxStyleDefault)
+	case int:
+		e.writeNumber(col, float64(v), xlsxStyleDefault)
+	case string:
+		e.writeText(col, v, xlsxStyleDefault)
+	default:
+		e.writeText(col, fmt.Sprint(value), xlsxStyleDefault)
+	}
+}
+
+func (e *xlsxEncoder) writeNumber(col int, v float64, style int) {
+	e.writeNumeric(col, strconv.FormatFloat(v, 'f', -1, 64), style)
+}
+
+// writeNumeric escreve uma célula numérica a partir do número já formatado (ponto decimal).
+func (e *xlsxEncoder) writeNumeric(col int, v string, style int) {
+	e.sheet.WriteString(fmt.Sprintf(`<c r="%s%d" s="%d"><v>%s</v></c>`, xlsxColumn(col), e.row, style, v))
+}
+
+func (e *xlsxEncoder) writeText(col int, text string, style int) {
+	e.sheet.WriteString(fmt.Sprintf(`<c r="%s%d" t="inlineStr" s="%d"><is><t xml:space="preserve">`, xlsxColumn(col), e.row, style))
+	xml.EscapeText(e.sheet, []byte(text))
+	e.sheet.WriteString(`</t></is></c>`)
+}
+
+// xlsxColumn converte o índice (0 = A) para a letra da coluna.
+func xlsxColumn(i int) string {
+	name := ""
+	for i >= 0 {
+		name = string(rune('A'+i%26)) + name
+		i = i/26 - 1
+	}
+	return name
+}
+
+// excelSerial converte uma data para o número de dias usado pelo Excel (base 30/12/1899).
+func excelSerial(t time.Time) float64 {
+	base := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	return wall.Sub(base).Hours() / 24
+}
+
+const xlsxContentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`</Types>`
+
+const xlsxRootRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const xlsxWorkbook = xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+	`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+	`<sheets><sheet name="Nexus" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+const xlsxWorkbookRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
+
+// xlsxStyles recebe os formatos de data e de data/hora do idioma
+const xlsxStyles = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="2"><numFmt numFmtId="164" formatCode="%s"/><numFmt numFmtId="165" formatCode="%s"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="5">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="2" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`</cellXfs></styleSheet>`
@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestFormatCell(t *testing.T) {
+	day := time.Date(2026, 3, 7, 14, 5, 0, 0, time.UTC)
+	text := "=SUM(A1)"
+	var noTime *time.Time
+	var noText *string
+
+	tests := []struct {
+		name   string
+		locale ExportLocale
+		kind   CellKind
+		value  any
+		want   string
+	}{
+		{"nil", LocaleEN, CellText, nil, ""},
+		{"data en", LocaleEN, CellDate, day, "2026-03-07"},
+		{"data pt-BR", LocalePTBR, CellDate, day, "07/03/2026"},
+		{"data e hora en", LocaleEN, CellDateTime, day, "2026-03-07 14:05"},
+		{"data e hora pt-BR", LocalePTBR, CellDateTime, day, "07/03/2026 14:05"},
+		{"ponteiro de data", LocalePTBR, CellDate, &day, "07/03/2026"},
+		{"ponteiro de data nil", LocaleEN, CellDate, noTime, ""},
+		{"float en", LocaleEN, CellNumber, 1234.5, "1234.50"},
+		{"float pt-BR", LocalePTBR, CellNumber, 1234.5, "1234,50"},
+		{"float arredonda", LocaleEN, CellNumber, 2.0 / 3, "0.67"},
+		{"decimal en", LocaleEN, CellNumber, decimal.RequireFromString("1500.257"), "1500.26"},
+		{"decimal pt-BR", LocalePTBR, CellNumber, decimal.RequireFromString("-12.5"), "-12,50"},
+		{"int64", LocalePTBR, CellInteger, int64(42), "42"},
+		{"int", LocaleEN, CellInteger, 7, "7"},
+		{"texto", LocaleEN, CellText, "Reunião, cliente", "Reunião, cliente"},
+		{"texto com fórmula", LocaleEN, CellText, "=1+1", "'=1+1"},
+		{"ponteiro de texto com fórmula", LocalePTBR, CellText, &text, "'=SUM(A1)"},
+		{"ponteiro de texto nil", LocaleEN, CellText, noText, ""},
+		{"outro tipo escapa", LocaleEN, CellText, true, "true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatCell(tt.locale, tt.kind, tt.value); got != tt.want {
+				t.Errorf("formatCell(%s, %v) = %q, esperado %q", tt.locale.Name, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscapeFormula(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"", ""},
+		{"texto", "texto"},
+		{"=HYPERLINK(\"x\")", "'=HYPERLINK(\"x\")"},
+		{"+55 11 99999-0000", "'+55 11 99999-0000"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\t=1", "'\t=1"},
+		{"\r=1", "'\r=1"},
+		{"a=1", "a=1"}, // só o primeiro caractere conta
+		{" =1", " =1"},
+	}
+	for _, tt := range tests {
+		if got := escapeFormula(tt.s); got != tt.want {
+			t.Errorf("escapeFormula(%q) = %q, esperado %q", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestExcelSerial(t *testing.T) {
+	saoPaulo := time.FixedZone("BRT", -3*60*60)
+	tests := []struct {
+		name string
+		t    time.Time
+		want float64
+	}{
+		{"base do Excel", time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC), 0},
+		{"1900-01-01", time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC), 2},
+		{"2026-01-01", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), 46023},
+		{"meio-dia", time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC), 46023.5},
+		{"6 horas", time.Date(2026, 1, 1, 6, 0, 0, 0, time.UTC), 46023.25},
+		{"usa a hora local, não a UTC", time.Date(2026, 1, 1, 22, 0, 0, 0, saoPaulo), 46023 + 22.0/24},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := excelSerial(tt.t); got != tt.want {
+				t.Errorf("excelSerial(%v) = %v, esperado %v", tt.t, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestXLSXColumn(t *testing.T) {
+	tests := []struct {
+		i    int
+		want string
+	}{
+		{0, "A"},
+		{1, "B"},
+		{25, "Z"},
+		{26, "AA"},
+		{27, "AB"},
+		{51, "AZ"},
+		{52, "BA"},
+		{701, "ZZ"},
+		{702, "AAA"},
+	}
+	for _, tt := range tests {
+		if got := xlsxColumn(tt.i); got != tt.want {
+			t.Errorf("xlsxColumn(%d) = %q, esperado %q", tt.i, got, tt.want)
+		}
+	}
+}
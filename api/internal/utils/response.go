@@ -3,12 +3,41 @@ package utils
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
+
+	"nexus/internal/domain"
 )
 
+// Problem é o corpo de erro da API, no formato problem+json (RFC 7807). Code é estável
+// para o cliente comparar; Errors traz os erros por campo, para mostrar ao lado dos inputs.
+type Problem struct {
+	Type   string              `json:"type"`
+	Title  string              `json:"title"`
+	Status int                 `json:"status"`
+	Detail string              `json:"detail,omitempty"`
+	Code   string              `json:"code"`
+	Errors []domain.FieldError `json:"errors,omitempty"`
+}
+
+// RespondWithError responde problem+json com o código genérico do status (ex.: "not_found").
 func RespondWithError(w http.ResponseWriter, code int, message string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	json.NewEncoder(w).Encode(map[string]string{"error": message})
+	RespondWithProblem(w, Problem{Status: code, Detail: message})
+}
+
+// RespondWithProblem preenche o que faltar (type, title e code pelo status) e responde.
+func RespondWithProblem(w http.ResponseWriter, p Problem) {
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+	if p.Code == "" {
+		p.Code = strings.ReplaceAll(strings.ToLower(http.StatusText(p.Status)), " ", "_")
+	}
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
 }
 
 func RespondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
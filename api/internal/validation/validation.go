@@ -0,0 +1,157 @@
+// Package validation confere os modelos pelas tags `validate` dos campos, no estilo
+// `validate:"required,max=255"`, devolvendo todos os erros de campo de uma vez.
+//
+// Regras aceitas (separadas por vírgula):
+//
+//	required     não pode ser o valor zero (string só com espaços conta como vazia)
+//	max=N        strings: no máximo N caracteres; números: no máximo N
+//	min=N        strings: no mínimo N caracteres; números: no mínimo N
+//	email        e-mail no formato nome@dominio
+//	cnpj         CNPJ com dígitos verificadores válidos (numérico ou alfanumérico, com ou sem máscara)
+//	cpf          CPF com dígitos verificadores válidos (com ou sem máscara)
+//	oneof=a b c  um dos valores listados
+//
+// Só required olha campos vazios: as demais regras ignoram o valor zero, então um campo
+// opcional com formato (ex.: `validate:"email"`) pode vir em branco.
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"nexus/internal/document"
+	"nexus/internal/domain"
+)
+
+// Struct valida v (struct ou ponteiro para struct) pelas tags `validate`.
+// Os campos saem com o nome do JSON, para o cliente ligar o erro ao input.
+func Struct(v any) []domain.FieldError {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields []domain.FieldError
+	typ := value.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("validate")
+		if tag == "" || !field.IsExported() {
+			continue
+		}
+		name := jsonName(field)
+		for _, rule := range strings.Split(tag, ",") {
+			if fe, ok := check(value.Field(i), name, rule); !ok {
+				fields = append(fields, fe)
+				break // um erro por campo basta
+			}
+		}
+	}
+	return fields
+}
+
+// check aplica uma regra ao campo; devolve false e o erro quando ela não passa.
+func check(v reflect.Value, name, rule string) (domain.FieldError, bool) {
+	rule, param, _ := strings.Cut(rule, "=")
+	if rule == "required" {
+		if isEmpty(v) {
+			return domain.Field(name, domain.CodeRequired, name+" é obrigatório"), false
+		}
+		return domain.FieldError{}, true
+	}
+	if isEmpty(v) {
+		return domain.FieldError{}, true
+	}
+	for v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+
+	switch rule {
+	case "max", "min":
+		limit, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			panic(fmt.Sprintf("validation: %s=%q inválido em %s", rule, param, name))
+		}
+		size, isString := measure(v)
+		if (rule == "max" && size <= limit) || (rule == "min" && size >= limit) {
+			return domain.FieldError{}, true
+		}
+		switch {
+		case rule == "max" && isString:
+			return domain.Field(name, "too_long", fmt.Sprintf("%s deve ter no máximo %s caracteres", name, param)), false
+		case rule == "max":
+			return domain.Field(name, "too_large", fmt.Sprintf("%s deve ser no máximo %s", name, param)), false
+		case isString:
+			return domain.Field(name, "too_short", fmt.Sprintf("%s deve ter no mínimo %s caracteres", name, param)), false
+		default:
+			return domain.Field(name, "too_small", fmt.Sprintf("%s deve ser no mínimo %s", name, param)), false
+		}
+	case "email":
+		if addr, err := mail.ParseAddress(v.String()); err == nil && addr.Address == v.String() {
+			return domain.FieldError{}, true
+		}
+		return domain.Field(name, "invalid_email", name+" não é um e-mail válido"), false
+	case "cnpj":
+		if document.ValidCNPJ(v.String()) {
+			return domain.FieldError{}, true
+		}
+		return domain.Field(name, "invalid_cnpj", name+" não é um CNPJ válido"), false
+	case "cpf":
+		if document.ValidCPF(v.String()) {
+			return domain.FieldError{}, true
+		}
+		return domain.Field(name, "invalid_cpf", name+" não é um CPF válido"), false
+	case "oneof":
+		options := strings.Fields(param)
+		if slices.Contains(options, fmt.Sprint(v.Interface())) {
+			return domain.FieldError{}, true
+		}
+		return domain.Field(name, "invalid_choice", name+" deve ser um destes: "+strings.Join(options, ", ")), false
+	}
+	panic(fmt.Sprintf("validation: regra %q desconhecida em %s", rule, name))
+}
+
+// isEmpty indica o valor zero; string só com espaços conta como vazia.
+func isEmpty(v reflect.Value) bool {
+	if v.Kind() == reflect.String {
+		return strings.TrimSpace(v.String()) == ""
+	}
+	return v.IsZero()
+}
+
+// measure devolve o tamanho de uma string (em caracteres) ou o valor de um número.
+func measure(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), false
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), false
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), false
+	case reflect.Slice, reflect.Map:
+		return float64(v.Len()), false
+	}
+	panic(fmt.Sprintf("validation: min/max não se aplica a %s", v.Kind()))
+}
+
+// jsonName é o nome do campo no JSON (o da tag, ou o do Go se não houver).
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
@@ -0,0 +1,134 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	"nexus/internal/domain"
+)
+
+type sample struct {
+	Name     string   `json:"name" validate:"required,max=5"`
+	Nick     string   `json:"nick,omitempty" validate:"min=3"`
+	Email    string   `json:"email" validate:"email"`
+	CNPJ     string   `json:"cnpj" validate:"cnpj"`
+	CPF      string   `json:"cpf" validate:"cpf"`
+	Role     string   `json:"role" validate:"oneof=admin consultant"`
+	Hours    int      `json:"hours" validate:"max=40"`
+	Rate     float64  `json:"rate" validate:"min=10"`
+	Owner    *int64   `json:"ownerId" validate:"required"`
+	Tags     []string `json:"tags" validate:"max=2"`
+	NoJSON   string   `validate:"required"`
+	internal string   `validate:"required"` // não exportado: ignorado
+}
+
+// valid devolve um sample que passa em todas as regras, para cada caso mudar só um campo.
+func valid() sample {
+	owner := int64(1)
+	return sample{Name: "Ana", Owner: &owner, NoJSON: "x"}
+}
+
+func TestStruct(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(s *sample)
+		field  string // "" = sem erro
+		code   string
+	}{
+		{"válido", func(s *sample) {}, "", ""},
+		{"required vazio", func(s *sample) { s.Name = "" }, "name", domain.CodeRequired},
+		{"required só com espaços", func(s *sample) { s.Name = " \t " }, "name", domain.CodeRequired},
+		{"required de ponteiro nil", func(s *sample) { s.Owner = nil }, "ownerId", domain.CodeRequired},
+		{"sem tag json usa o nome do Go", func(s *sample) { s.NoJSON = "" }, "NoJSON", domain.CodeRequired},
+		{"max conta caracteres, não bytes", func(s *sample) { s.Name = "ÁÉÍÓÚ" }, "", ""},
+		{"max estourado", func(s *sample) { s.Name = "Ananias" }, "name", "too_long"},
+		{"min em campo vazio é ignorado", func(s *sample) { s.Nick = "" }, "", ""},
+		{"min de string", func(s *sample) { s.Nick = "Jo" }, "nick", "too_short"},
+		{"max de número", func(s *sample) { s.Hours = 41 }, "hours", "too_large"},
+		{"max de número no limite", func(s *sample) { s.Hours = 40 }, "", ""},
+		{"min de float", func(s *sample) { s.Rate = 9.99 }, "rate", "too_small"},
+		{"max de slice", func(s *sample) { s.Tags = []string{"a", "b", "c"} }, "tags", "too_large"},
+		{"e-mail válido", func(s *sample) { s.Email = "ana@exemplo.com.br" }, "", ""},
+		{"e-mail sem domínio", func(s *sample) { s.Email = "ana" }, "email", "invalid_email"},
+		{"e-mail com nome", func(s *sample) { s.Email = "Ana <ana@exemplo.com>" }, "email", "invalid_email"},
+		{"e-mail com espaço no fim", func(s *sample) { s.Email = "ana@exemplo.com " }, "email", "invalid_email"},
+		{"CNPJ com máscara", func(s *sample) { s.CNPJ = "11.222.333/0001-81" }, "", ""},
+		{"CNPJ inválido", func(s *sample) { s.CNPJ = "11.222.333/0001-82" }, "cnpj", "invalid_cnpj"},
+		{"CPF válido", func(s *sample) { s.CPF = "529.982.247-25" }, "", ""},
+		{"CPF inválido", func(s *sample) { s.CPF = "529.982.247-26" }, "cpf", "invalid_cpf"},
+		{"oneof aceito", func(s *sample) { s.Role = "consultant" }, "", ""},
+		{"oneof recusado", func(s *sample) { s.Role = "root" }, "role", "invalid_choice"},
+		{"não exportado é ignorado", func(s *sample) { s.internal = "" }, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := valid()
+			tt.change(&s)
+			fields := Struct(&s)
+			if tt.field == "" {
+				if len(fields) != 0 {
+					t.Fatalf("esperado sem erros, veio %+v", fields)
+				}
+				return
+			}
+			if len(fields) != 1 {
+				t.Fatalf("esperado 1 erro em %s, veio %+v", tt.field, fields)
+			}
+			if fields[0].Field != tt.field || fields[0].Code != tt.code {
+				t.Errorf("erro = %s/%s, esperado %s/%s", fields[0].Field, fields[0].Code, tt.field, tt.code)
+			}
+			if !strings.Contains(fields[0].Message, tt.field) {
+				t.Errorf("mensagem %q não cita o campo %s", fields[0].Message, tt.field)
+			}
+		})
+	}
+}
+
+func TestStructAllFieldsAtOnce(t *testing.T) {
+	s := sample{Name: "Ananias", Email: "x", Hours: 50}
+	fields := Struct(s) // também aceita o valor, sem ponteiro
+	var got []string
+	for _, f := range fields {
+		got = append(got, f.Field+"/"+f.Code)
+	}
+	want := []string{"name/too_long", "email/invalid_email", "hours/too_large", "ownerId/required", "NoJSON/required"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("erros = %v, esperado %v", got, want)
+	}
+}
+
+func TestStructNotStruct(t *testing.T) {
+	var nilPtr *sample
+	for _, v := range []any{nil, nilPtr, "texto", 42} {
+		if fields := Struct(v); fields != nil {
+			t.Errorf("Struct(%#v) = %+v, esperado nil", v, fields)
+		}
+	}
+}
+
+func TestStructMalformedTag(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+	}{
+		{"limite não numérico", &struct {
+			Name string `validate:"max=abc"`
+		}{Name: "x"}},
+		{"regra desconhecida", &struct {
+			Name string `validate:"phone"`
+		}{Name: "x"}},
+		{"min/max em tipo sem tamanho", &struct {
+			Active bool `validate:"max=1"`
+		}{Active: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("esperado pânico para a tag malformada")
+				}
+			}()
+			Struct(tt.value)
+		})
+	}
+}
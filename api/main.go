@@ -8,16 +8,30 @@
 
 // @host            localhost:8080
 // @BasePath        /
+
+// @securityDefinitions.apikey  BearerAuth
+// @in                          header
+// @name                        Authorization
+// @description                 "Bearer " seguido do accessToken devolvido por /api/auth/login
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
+	"os"
+	"time"
 
 	"nexus/internal/api"
+	"nexus/internal/auth"
 	"nexus/internal/database"
 	"nexus/internal/handlers"
+	"nexus/internal/lookup"
+	"nexus/internal/models"
+	"nexus/internal/notify"
 	"nexus/internal/repository"
+	"nexus/internal/sla"
+	"nexus/internal/usecase"
 )
 
 func main() {
@@ -36,15 +50,79 @@ func main() {
 	companyRepo := repository.NewCompanyRepository(db)
 	userRepo := repository.NewUserRepository(db)
 	appointmentRepo := repository.NewAppointmentRepository(db)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	reportRepo := repository.NewReportRepository(db)
+	ticketRepo := repository.NewTicketRepository(db)
+	timesheetRepo := repository.NewTimesheetRepository(db)
+	billingRateRepo := repository.NewPostgresRepository[*models.BillingRate](db, "billing_rates")
+	invoiceRepo := repository.NewInvoiceRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	slaPolicyRepo := repository.NewPostgresRepository[*models.SLAPolicy](db, "sla_policies")
+	calendarRepo := repository.NewPostgresRepository[*models.BusinessCalendar](db, "business_calendars")
+	contactRepo := repository.NewPostgresRepository[*models.CompanyContact](db, "company_contacts")
+	addressRepo := repository.NewPostgresRepository[*models.CompanyAddress](db, "company_addresses")
+
+	// 3.1 Autenticação
+	tokens := auth.NewTokenManager(jwtSecret(), 15*time.Minute, 7*24*time.Hour)
+	bootstrapAdmin(context.Background(), userRepo)
+
+	// 3.2 Worker de SLA: marca chamados em risco ou com prazo violado
+	go sla.NewWorker(ticketRepo, time.Minute).Run(context.Background())
+
+	// 3.3 Casos de uso: regras de negócio, cada operação numa transação
+	txManager := repository.NewTxManager(db)
+	companyUsecase := usecase.NewCompanyUsecase(txManager, companyRepo, addressRepo, cnpjLookup())
+	userUsecase := usecase.NewUserUsecase(txManager, userRepo)
+	contractUsecase := usecase.NewContractUsecase(txManager, contractRepo, companyRepo)
+	appointmentUsecase := usecase.NewAppointmentUsecase(txManager, appointmentRepo, contractRepo, ticketRepo, timesheetRepo)
+	contactUsecase := usecase.NewCompanyContactUsecase(txManager, contactRepo, companyRepo)
+	addressUsecase := usecase.NewCompanyAddressUsecase(txManager, addressRepo, companyRepo)
+
+	// 3.4 Envios ao cliente: cada um vai para o tipo certo de contato (por ora, só no log)
+	recipients := notify.NewRouter(contactRepo, companyRepo, nil)
 
 	// 4. Handlers
-	companyHandler := handlers.NewCompanyHandler(companyRepo)
-	userHandler := handlers.NewUserHandler(userRepo)
-	contractHandler := handlers.NewContractHandler(contractRepo)
-	appointmentHandler := handlers.NewAppointmentHandler(appointmentRepo)
+	authHandler := handlers.NewAuthHandler(userRepo, refreshTokenRepo, tokens)
+	companyHandler := handlers.NewCompanyHandler(companyRepo, companyUsecase)
+	contactHandler := handlers.NewCompanyContactHandler(contactRepo, contactUsecase)
+	addressHandler := handlers.NewCompanyAddressHandler(addressRepo, addressUsecase)
+	userHandler := handlers.NewUserHandler(userRepo, userUsecase)
+	contractHandler := handlers.NewContractHandler(contractRepo, contractUsecase)
+	appointmentHandler := handlers.NewAppointmentHandler(appointmentRepo, timesheetRepo, appointmentUsecase)
+	reportHandler := handlers.NewReportHandler(reportRepo, contractRepo)
+	statementHandler := handlers.NewStatementHandler(contractRepo, companyRepo, appointmentRepo, reportRepo, recipients)
+	slaService := sla.NewService(contractRepo, slaPolicyRepo, calendarRepo)
+	ticketHandler := handlers.NewTicketHandler(ticketRepo, contractRepo, slaService, recipients)
+	slaPolicyHandler := handlers.NewSLAPolicyHandler(slaPolicyRepo)
+	calendarHandler := handlers.NewBusinessCalendarHandler(calendarRepo)
+	timesheetHandler := handlers.NewTimesheetHandler(timesheetRepo)
+	billingRateHandler := handlers.NewBillingRateHandler(billingRateRepo)
+	invoiceHandler := handlers.NewInvoiceHandler(invoiceRepo, companyRepo, addressRepo, recipients)
+	auditHandler := handlers.NewAuditHandler(auditRepo)
 
-	// 5. Roteador
-	router := api.NewRouter(companyHandler, userHandler, contractHandler, appointmentHandler)
+	// 5. Roteador (prazo das consultas: NEXUS_QUERY_TIMEOUT e, para relatórios/exportações, NEXUS_REPORT_TIMEOUT)
+	timeouts := api.Timeouts{
+		Default: envDuration("NEXUS_QUERY_TIMEOUT", 30*time.Second),
+		Reports: envDuration("NEXUS_REPORT_TIMEOUT", 2*time.Minute),
+	}
+	router := api.NewRouter(api.Deps{Tokens: tokens, UserRepo: userRepo, Timeouts: timeouts}, api.Handlers{
+		Auth:        authHandler,
+		Company:     companyHandler,
+		Contact:     contactHandler,
+		Address:     addressHandler,
+		User:        userHandler,
+		Contract:    contractHandler,
+		Appointment: appointmentHandler,
+		Report:      reportHandler,
+		Statement:   statementHandler,
+		Ticket:      ticketHandler,
+		SLAPolicy:   slaPolicyHandler,
+		Calendar:    calendarHandler,
+		Timesheet:   timesheetHandler,
+		BillingRate: billingRateHandler,
+		Invoice:     invoiceHandler,
+		Audit:       auditHandler,
+	})
 
 	const port = ":8080"
 	log.Printf("Servidor subindo na porta %s", port)
@@ -52,3 +130,78 @@ func main() {
 		log.Fatal("Erro ao iniciar o servidor: ", err)
 	}
 }
+
+// bootstrapAdmin cria o primeiro admin a partir de NEXUS_ADMIN_EMAIL/NEXUS_ADMIN_PASSWORD,
+// já que todas as rotas (inclusive POST /api/users) exigem login.
+func bootstrapAdmin(ctx context.Context, userRepo repository.UserRepository) {
+	email := os.Getenv("NEXUS_ADMIN_EMAIL")
+	password := os.Getenv("NEXUS_ADMIN_PASSWORD")
+	if email == "" || password == "" {
+		return
+	}
+
+	exists, err := userRepo.EmailExists(ctx, email)
+	if err != nil {
+		log.Fatalf("Erro ao verificar admin inicial: %v", err)
+	}
+	if exists {
+		return
+	}
+
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		log.Fatalf("Erro ao gerar hash da senha do admin: %v", err)
+	}
+	admin := &models.User{Name: "Administrador", Email: email, Role: "admin", PasswordHash: hash}
+	if _, err := userRepo.Save(ctx, admin); err != nil {
+		log.Fatalf("Erro ao criar admin inicial: %v", err)
+	}
+	log.Printf("👤 Admin inicial %s criado", email)
+}
+
+// jwtSecret lê o segredo dos tokens. Sem NEXUS_JWT_SECRET a API não sobe: o segredo de
+// desenvolvimento está no repositório e qualquer um assinaria tokens de admin com ele.
+// Só NEXUS_DEV=true (ambiente local) libera esse segredo.
+func jwtSecret() string {
+	if secret := os.Getenv("NEXUS_JWT_SECRET"); secret != "" {
+		return secret
+	}
+	if os.Getenv("NEXUS_DEV") != "true" {
+		log.Fatal("NEXUS_JWT_SECRET não definido (em ambiente local, use NEXUS_DEV=true para o segredo de desenvolvimento)")
+	}
+	log.Println("⚠️ NEXUS_DEV=true e NEXUS_JWT_SECRET não definido: usando segredo de desenvolvimento, NUNCA em produção")
+	return "nexus-dev-secret"
+}
+
+// cnpjLookup escolhe o provedor de consulta de CNPJ por NEXUS_CNPJ_LOOKUP; sem a variável,
+// a consulta fica desligada. "fake" usa o provedor em memória, para desenvolvimento.
+func cnpjLookup() lookup.Provider {
+	switch v := os.Getenv("NEXUS_CNPJ_LOOKUP"); v {
+	case "":
+		return nil
+	case "fake":
+		log.Println("⚠️ Consulta de CNPJ usando o provedor fake (dados fictícios)")
+		return lookup.NewFake(models.CompanyInfo{
+			CNPJ:      "12.ABC.345/01DE-35",
+			Name:      "Empresa Exemplo Ltda",
+			TradeName: "Exemplo",
+			Address:   models.Address{Street: "Avenida Paulista", Number: "1000", District: "Bela Vista", City: "São Paulo", State: "SP", ZipCode: "01310-100"},
+		})
+	default:
+		log.Fatalf("NEXUS_CNPJ_LOOKUP inválido (%q): use fake ou deixe em branco", v)
+		return nil
+	}
+}
+
+// envDuration lê uma duração do ambiente (ex.: 30s, 2m); sem a variável, usa def.
+func envDuration(name string, def time.Duration) time.Duration {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		log.Fatalf("%s inválido (%q): use o formato 30s, 2m...", name, v)
+	}
+	return d
+}
@@ -4,7 +4,8 @@ echo      INICIANDO O SISTEMA NEXUS 🚀
 echo ==========================================
 
 :: 1. Abre uma nova janela, entra na pasta 'api' e roda o Go
-start "Nexus Backend (Go)" cmd /k "cd api && go run main.go"
+::    NEXUS_DEV libera o segredo de desenvolvimento dos tokens (nunca em produção)
+start "Nexus Backend (Go)" cmd /k "cd api && set "NEXUS_DEV=true" && go run main.go"
 
 :: 2. Abre uma nova janela, entra na pasta 'frontend' e roda o NPM
 start "Nexus Frontend (Next.js)" cmd /k "cd frontend && npm run dev"
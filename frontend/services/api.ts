@@ -28,7 +28,8 @@ async function fetchClient<T>(endpoint: string, options?: RequestInit): Promise<
     }
 
     if (!response.ok) {
-      const errorMsg = (data as ApiError)?.error || `Error ${response.status}: ${response.statusText}`;
+      const problem = data as ApiError | null;
+      const errorMsg = problem?.detail || problem?.title || `Error ${response.status}: ${response.statusText}`;
       throw new Error(errorMsg);
     }
 
@@ -37,6 +37,18 @@ export interface Appointment {
   durationSeconds?: number;
 }
 
+// Erro da API no formato problem+json (RFC 7807)
+export interface ApiFieldError {
+  field: string;
+  code: string;
+  message: string;
+}
+
 export interface ApiError {
-  error: string;
+  type: string;
+  title: string;
+  status: number;
+  detail?: string;
+  code: string;
+  errors?: ApiFieldError[];
 }